@@ -0,0 +1,73 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// appliedSetsFileName is the file in the directory of the context recording
+// the objects applied with each prune selector.
+const appliedSetsFileName = "applied.yaml"
+
+// appliedSets maps selectors to IDs of the objects applied with them. The
+// kinds pruned carry no labels in the control plane, so the objects pruned
+// with a selector are limited to the ones recorded here by previous applies.
+type appliedSets map[string][]string
+
+func loadAppliedSets(file string) (appliedSets, error) {
+	sets := appliedSets{}
+	buff, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return sets, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "read file %s failed", file)
+	}
+
+	err = yaml.Unmarshal(buff, &sets)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshal %s failed", file)
+	}
+
+	return sets, nil
+}
+
+func (s appliedSets) save(file string) error {
+	buff, err := yaml.Marshal(s)
+	if err != nil {
+		return errors.Wrapf(err, "marshal %+v to yaml failed", s)
+	}
+
+	err = os.MkdirAll(path.Dir(file), 0700)
+	if err != nil {
+		return errors.Wrapf(err, "create directory %s failed", path.Dir(file))
+	}
+
+	err = ioutil.WriteFile(file, buff, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "write file %s failed", file)
+	}
+
+	return nil
+}
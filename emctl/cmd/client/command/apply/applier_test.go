@@ -0,0 +1,151 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+func TestApplier(t *testing.T) {
+	tests := []struct {
+		object   resource.MeshObject
+		changed  resource.MeshObject
+		path     string
+		attached bool
+	}{
+		{resource.ToTenant(&v1alpha1.Tenant{Name: "pet", Description: "pet clinic"}),
+			resource.ToTenant(&v1alpha1.Tenant{Name: "pet", Description: "pet shop"}),
+			"/apis/v1/mesh/tenants/pet", false},
+		{resource.ToService(&v1alpha1.Service{Name: "vets", RegisterTenant: "pet"}),
+			resource.ToService(&v1alpha1.Service{Name: "vets", RegisterTenant: "shop"}),
+			"/apis/v1/mesh/services/vets", false},
+		{resource.ToIngress(&v1alpha1.Ingress{Name: "pet-ingress"}),
+			resource.ToIngress(&v1alpha1.Ingress{Name: "pet-ingress", Rules: []*v1alpha1.IngressRule{{Host: "pet.com"}}}),
+			"/apis/v1/mesh/ingresses/pet-ingress", false},
+		{resource.ToCanary("vets", &v1alpha1.Canary{}),
+			resource.ToCanary("vets", &v1alpha1.Canary{CanaryRules: []*v1alpha1.CanaryRule{{}}}),
+			"/apis/v1/mesh/services/vets/canary", true},
+		{resource.ToLoadBalance("vets", &v1alpha1.LoadBalance{Policy: "random"}),
+			resource.ToLoadBalance("vets", &v1alpha1.LoadBalance{Policy: "roundRobin"}),
+			"/apis/v1/mesh/services/vets/loadbalance", true},
+		{resource.ToResilience("vets", &v1alpha1.Resilience{}),
+			resource.ToResilience("vets", &v1alpha1.Resilience{RateLimiter: &v1alpha1.RateLimiter{}}),
+			"/apis/v1/mesh/services/vets/resilience", true},
+		{resource.ToObservabilityTracings("vets", &v1alpha1.ObservabilityTracings{Enabled: true}),
+			resource.ToObservabilityTracings("vets", &v1alpha1.ObservabilityTracings{Enabled: false}),
+			"/apis/v1/mesh/services/vets/tracings", true},
+		{resource.ToObservabilityMetrics("vets", &v1alpha1.ObservabilityMetrics{Enabled: true}),
+			resource.ToObservabilityMetrics("vets", &v1alpha1.ObservabilityMetrics{Enabled: false}),
+			"/apis/v1/mesh/services/vets/metrics", true},
+		{resource.ToObservabilityOutputServer("vets", &v1alpha1.ObservabilityOutputServer{Enabled: true}),
+			resource.ToObservabilityOutputServer("vets", &v1alpha1.ObservabilityOutputServer{Enabled: false}),
+			"/apis/v1/mesh/services/vets/outputserver", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.object.Kind(), func(t *testing.T) {
+			server := fake.NewServer()
+			defer server.Close()
+			client := meshclient.New(server.URL())
+			applier := WrapApplierByMeshObject(tt.object, client, time.Second)
+
+			if tt.attached {
+				_, err := applier.Apply()
+				if !meshclient.IsNotFoundError(err) {
+					t.Fatalf("expect NotFoundError without the service but got: %v", err)
+				}
+				server.AddService(&v1alpha1.Service{Name: "vets"})
+				server.ResetRequests()
+			}
+
+			if result, err := applier.Apply(); err != nil || result != ResultCreated {
+				t.Fatalf("create %s failed: %v, %v", tt.object.Kind(), result, err)
+			}
+			expectRequests(t, server, []string{http.MethodGet + " " + tt.path, http.MethodPost + " " + tt.path})
+
+			server.ResetRequests()
+			if result, err := applier.Apply(); err != nil || result != ResultUnchanged {
+				t.Fatalf("apply unchanged %s failed: %v, %v", tt.object.Kind(), result, err)
+			}
+			expectRequests(t, server, []string{http.MethodGet + " " + tt.path})
+
+			server.ResetRequests()
+			changed := WrapApplierByMeshObject(tt.changed, client, time.Second)
+			if result, err := changed.Apply(); err != nil || result != ResultConfigured {
+				t.Fatalf("update %s failed: %v, %v", tt.object.Kind(), result, err)
+			}
+			expectRequests(t, server, []string{http.MethodGet + " " + tt.path, http.MethodPut + " " + tt.path})
+
+			_, err := client.V1Alpha1().Resource(tt.object.Kind()).Get(context.Background(), tt.object.Name())
+			if err != nil {
+				t.Errorf("get applied %s failed: %v", tt.object.Kind(), err)
+			}
+
+			server.SetStatus(http.MethodGet, tt.path, http.StatusInternalServerError)
+			if _, err := applier.Apply(); err == nil {
+				t.Errorf("expect error when the control plane fails")
+			}
+		})
+	}
+}
+
+func TestApplyServiceReplacesAttachedKinds(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.AddService(&v1alpha1.Service{Name: "vets", RegisterTenant: "pet", Canary: &v1alpha1.Canary{}})
+	client := meshclient.New(server.URL())
+
+	// The service is replaced as a whole, the kinds attached to it which
+	// aren't specified are removed.
+	service := resource.ToService(&v1alpha1.Service{Name: "vets", RegisterTenant: "pet"})
+	if result, err := WrapApplierByMeshObject(service, client, time.Second).Apply(); err != nil || result != ResultConfigured {
+		t.Fatalf("expect service configured but got %v, %v", result, err)
+	}
+
+	applied, err := client.V1Alpha1().Service().Get(context.Background(), "vets")
+	if err != nil {
+		t.Fatalf("get service failed: %v", err)
+	}
+	if applied.Spec.Canary != nil {
+		t.Errorf("expect canary removed but got %+v", applied.Spec.Canary)
+	}
+
+	if result, err := WrapApplierByMeshObject(service, client, time.Second).Apply(); err != nil || result != ResultUnchanged {
+		t.Errorf("expect service unchanged but got %v, %v", result, err)
+	}
+}
+
+func expectRequests(t *testing.T, server *fake.Server, expected []string) {
+	requests := server.Requests()
+	if len(requests) != len(expected) {
+		t.Fatalf("expect requests %v but got %v", expected, requests)
+	}
+	for i, r := range requests {
+		if r.Method+" "+r.Path != expected[i] {
+			t.Errorf("expect request %s but got %s %s", expected[i], r.Method, r.Path)
+		}
+	}
+}
@@ -18,7 +18,8 @@
 package apply
 
 import (
-	"fmt"
+	"os"
+	"path"
 
 	"github.com/megaease/easemeshctl/cmd/client/command/flags"
 	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
@@ -36,7 +37,30 @@ func Run(cmd *cobra.Command, flags *flags.Apply) {
 		common.ExitWithErrorf("no resource specified")
 	}
 
+	if err := flags.AdminDryRun.Validate(); err != nil {
+		common.ExitWithErrorf("%v", err)
+	}
+
+	if flags.Concurrency < 1 {
+		common.ExitWithErrorf("concurrency must be at least 1")
+	}
+
+	if flags.Selector != "" && !flags.Prune {
+		common.ExitWithErrorf("selector is only supported with --prune")
+	}
+
+	if flags.All && !flags.Prune {
+		common.ExitWithErrorf("--all is only supported with --prune")
+	}
+
+	if flags.Prune {
+		if err := validatePrune(flags); err != nil {
+			common.ExitWithErrorf("%v", err)
+		}
+	}
+
 	vss, err := util.NewVisitorBuilder().
+		DefaultTenant(flags.Tenant).
 		FilenameParam(&util.FilenameOptions{
 			Recursive: flags.Recursive,
 			Filenames: []string{flags.YamlFile},
@@ -47,28 +71,57 @@ func Run(cmd *cobra.Command, flags *flags.Apply) {
 		common.ExitWithErrorf("build visitor failed: %v", err)
 	}
 
-	var errs []error
+	var objects []resource.MeshObject
+	invalid := 0
 	for _, vs := range vss {
-		err := vs.Visit(func(mo resource.MeshObject, e error) error {
+		// The visitor has reported errors of every document already.
+		vs.Visit(func(mo resource.MeshObject, e error) error {
 			if e != nil {
+				invalid++
 				return errors.Wrap(e, "visit failed")
 			}
-
-			err := WrapApplierByMeshObject(mo, meshclient.New(flags.Server), flags.Timeout).Apply()
-			if err != nil {
-				return fmt.Errorf("%s/%s applied failed: %s", mo.Kind(), mo.Name(), err)
-			}
-
-			fmt.Printf("%s/%s applied successfully\n", mo.Kind(), mo.Name())
+			objects = append(objects, mo)
 			return nil
 		})
+	}
+
+	if invalid > 0 {
+		common.ExitWithErrorf("%d resources are invalid, nothing is applied", invalid)
+	}
 
+	if flags.Prune {
+		// The selector scopes both the applied and the pruned objects.
+		sel, err := util.ParseSelector(flags.Selector)
 		if err != nil {
-			errs = append(errs, err)
+			common.ExitWithErrorf("%v", err)
 		}
+		objects = util.FilterObjects(objects, sel)
+	}
+
+	client := meshclient.New(flags.Server, flags.ClientOptions()...)
+	objects, err = plan(objects, newExistsFunc(client, flags.Timeout, flags.DryRun))
+	if err != nil {
+		common.ExitWithErrorf("planning resources failed, nothing is applied:\n%v", err)
 	}
 
-	if len(errs) > 0 {
-		common.ExitWithErrorf("applying resources has errors occurred")
+	planned := newPlannedSet()
+	r := newRunner(flags.Concurrency, flags.DryRun, func(object resource.MeshObject) Applier {
+		if flags.IsDryRun() {
+			return newDryRunApplier(object, client, flags.Timeout, flags.DryRun, planned)
+		}
+		return WrapApplierByMeshObject(object, client, flags.Timeout)
+	})
+
+	failed := r.run(objects)
+	r.printSummary(os.Stdout)
+	if failed > 0 {
+		common.ExitWithErrorf("applying resources has errors occurred, %d resources failed", failed)
+	}
+
+	if flags.Prune {
+		err := prune(objects, flags, path.Join(flags.ContextDir(), appliedSetsFileName))
+		if err != nil {
+			common.ExitWithErrorf("pruning resources has errors occurred: %v", err)
+		}
 	}
 }
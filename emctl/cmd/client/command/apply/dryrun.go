@@ -0,0 +1,168 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/get"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+
+	"github.com/pkg/errors"
+)
+
+var _ Applier = &dryRunApplier{}
+
+// dryRunApplier reports what applying the object would do without issuing
+// any write request to the control plane. In the server mode it resolves
+// the object and the objects it refers to against the control plane, the
+// objects planned by the previous dry run in the same session are treated
+// as existed, and the result is whether the object would be created or
+// updated. In the client mode the result is empty.
+type dryRunApplier struct {
+	baseApplier
+	object  resource.MeshObject
+	mode    string
+	planned *plannedSet
+}
+
+// plannedSet records the objects planned by the dry run, it's shared by the
+// dry run appliers running concurrently.
+type plannedSet struct {
+	mutex sync.Mutex
+	ids   map[string]bool
+}
+
+func newPlannedSet() *plannedSet {
+	return &plannedSet{ids: map[string]bool{}}
+}
+
+func (p *plannedSet) add(object resource.MeshObject) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.ids[objectID(object)] = true
+}
+
+func (p *plannedSet) has(object resource.MeshObject) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.ids[objectID(object)]
+}
+
+func newDryRunApplier(object resource.MeshObject, client meshclient.MeshClient,
+	timeout time.Duration, mode string, planned *plannedSet) Applier {
+	return &dryRunApplier{
+		baseApplier: baseApplier{client: client, timeout: timeout},
+		object:      object,
+		mode:        mode,
+		planned:     planned,
+	}
+}
+
+func (d *dryRunApplier) Apply() (Result, error) {
+	if d.object.Name() == "" {
+		return "", errors.Errorf("name of %s is required", d.object.Kind())
+	}
+
+	var result Result
+	action := "would be applied"
+	if d.mode == flags.DryRunServer {
+		existed, err := d.exists(d.object)
+		if err != nil {
+			return "", errors.Wrapf(err, "resolve %s %s", d.object.Kind(), d.object.Name())
+		}
+		if existed {
+			result, action = ResultConfigured, "would be patched"
+		} else {
+			result, action = ResultCreated, "would be created"
+		}
+
+		for _, ref := range references(d.object) {
+			existed, err := d.exists(ref)
+			if err != nil {
+				return "", errors.Wrapf(err, "resolve %s %s referred by %s %s",
+					ref.Kind(), ref.Name(), d.object.Kind(), d.object.Name())
+			}
+			if !existed {
+				return "", errors.Errorf("%s %s referred by %s %s not found",
+					ref.Kind(), ref.Name(), d.object.Kind(), d.object.Name())
+			}
+		}
+	}
+
+	d.planned.add(d.object)
+	fmt.Printf("%s/%s %s (dry run: %s)\n", d.object.Kind(), d.object.Name(), action, d.mode)
+	return result, nil
+}
+
+func (d *dryRunApplier) exists(object resource.MeshObject) (bool, error) {
+	if d.planned.has(object) {
+		return true, nil
+	}
+	return get.Exists(object, d.client, d.timeout)
+}
+
+// references returns objects which must exist in the control plane before
+// the object is applied.
+func references(object resource.MeshObject) []resource.MeshObject {
+	var refs []resource.MeshObject
+	newRef := func(kind, name string) {
+		ref, err := resource.NewObjectCreator().NewFromResource(
+			resource.NewMeshResource(resource.DefaultAPIVersion, kind, name))
+		if err == nil {
+			refs = append(refs, ref)
+		}
+	}
+
+	switch o := object.(type) {
+	case *resource.Service:
+		if o.Spec != nil && o.Spec.RegisterTenant != "" {
+			newRef(resource.KindTenant, o.Spec.RegisterTenant)
+		}
+	case *resource.Canary, *resource.LoadBalance, *resource.Resilience,
+		*resource.ObservabilityTracings, *resource.ObservabilityMetrics, *resource.ObservabilityOutputServer:
+		newRef(resource.KindService, object.Name())
+	case *resource.Ingress:
+		if o.Spec == nil {
+			break
+		}
+		backends := map[string]bool{}
+		for _, rule := range o.Spec.Rules {
+			if rule == nil {
+				continue
+			}
+			for _, path := range rule.Paths {
+				if path == nil || path.Backend == "" || backends[path.Backend] {
+					continue
+				}
+				backends[path.Backend] = true
+				newRef(resource.KindService, path.Backend)
+			}
+		}
+	}
+
+	return refs
+}
+
+func objectID(object resource.MeshObject) string {
+	return object.Kind() + "/" + object.Name()
+}
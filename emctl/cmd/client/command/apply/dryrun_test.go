@@ -0,0 +1,93 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+func TestDryRunApplier(t *testing.T) {
+	objects := []resource.MeshObject{
+		resource.ToTenant(&v1alpha1.Tenant{Name: "pet"}),
+		resource.ToService(&v1alpha1.Service{Name: "vets", RegisterTenant: "pet"}),
+		resource.ToService(&v1alpha1.Service{Name: "owners", RegisterTenant: "pet"}),
+		resource.ToCanary("vets", &v1alpha1.Canary{}),
+	}
+
+	tests := []struct {
+		mode     string
+		expected []Result
+	}{
+		{flags.DryRunClient, []Result{"", "", "", ""}},
+		// The tenant and the service vets are planned before the objects
+		// referring to them, owners exists in the control plane.
+		{flags.DryRunServer, []Result{ResultCreated, ResultCreated, ResultConfigured, ResultCreated}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			server := fake.NewServer()
+			defer server.Close()
+			server.AddService(&v1alpha1.Service{Name: "owners", RegisterTenant: "pet"})
+			client := meshclient.New(server.URL())
+			planned := newPlannedSet()
+
+			for i, object := range objects {
+				result, err := newDryRunApplier(object, client, time.Second, tt.mode, planned).Apply()
+				if err != nil {
+					t.Fatalf("dry run %s/%s failed: %v", object.Kind(), object.Name(), err)
+				}
+				if result != tt.expected[i] {
+					t.Errorf("expect %s/%s %q but got %q", object.Kind(), object.Name(), tt.expected[i], result)
+				}
+			}
+
+			requests := server.Requests()
+			if tt.mode == flags.DryRunClient && len(requests) != 0 {
+				t.Errorf("expect no requests in client mode but got %v", requests)
+			}
+			for _, r := range requests {
+				if r.Method != http.MethodGet {
+					t.Errorf("expect no mutating requests but got %s %s", r.Method, r.Path)
+				}
+			}
+		})
+	}
+}
+
+func TestDryRunApplierMissingReference(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	client := meshclient.New(server.URL())
+
+	canary := resource.ToCanary("vets", &v1alpha1.Canary{})
+	if _, err := newDryRunApplier(canary, client, time.Second, flags.DryRunServer, newPlannedSet()).Apply(); err == nil {
+		t.Errorf("expect error for the canary of the missing service")
+	}
+	if _, err := newDryRunApplier(canary, client, time.Second, flags.DryRunClient, newPlannedSet()).Apply(); err != nil {
+		t.Errorf("expect no reference checked in client mode but got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/get"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+
+	"github.com/pkg/errors"
+)
+
+// existsFunc tells whether the object exists in the control plane
+type existsFunc func(object resource.MeshObject) (bool, error)
+
+// newExistsFunc returns the existsFunc resolving objects against the control
+// plane, it returns nil in the client dry run mode, which doesn't contact the
+// control plane.
+func newExistsFunc(client meshclient.MeshClient, timeout time.Duration, dryRun string) existsFunc {
+	if dryRun == flags.DryRunClient {
+		return nil
+	}
+	return func(object resource.MeshObject) (bool, error) {
+		return get.Exists(object, client, timeout)
+	}
+}
+
+// plan orders the objects by dependency, so that every object is applied
+// after the objects it refers to, whatever the order of the files and the
+// documents is. It checks that every object referred to is either planned or
+// existing in the control plane, and reports all missing ones at once before
+// anything is applied. The control plane isn't resolved if exists is nil.
+func plan(objects []resource.MeshObject, exists existsFunc) ([]resource.MeshObject, error) {
+	planned := make([]resource.MeshObject, len(objects))
+	copy(planned, objects)
+	resource.SortByKind(planned)
+
+	ids := map[string]bool{}
+	for _, object := range planned {
+		ids[objectID(object)] = true
+	}
+
+	var errs []string
+	resolved := map[string]bool{}
+	for _, object := range planned {
+		for _, ref := range references(object) {
+			id := objectID(ref)
+			if ids[id] || exists == nil {
+				continue
+			}
+
+			existed, checked := resolved[id]
+			if !checked {
+				var err error
+				existed, err = exists(ref)
+				if err != nil {
+					return nil, errors.Wrapf(err, "resolve %s referred by %s", id, objectID(object))
+				}
+				resolved[id] = existed
+			}
+			if !existed {
+				errs = append(errs, fmt.Sprintf("%s refers to %s, which is neither declared nor in the control plane",
+					objectID(object), id))
+			}
+		}
+	}
+
+	if len(errs) != 0 {
+		return nil, errors.New(strings.Join(errs, "\n"))
+	}
+
+	return planned, nil
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+func TestPlan(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.AddTenant(&v1alpha1.Tenant{Name: "shop"})
+	server.AddService(&v1alpha1.Service{Name: "order", RegisterTenant: "shop"})
+	exists := newExistsFunc(meshclient.New(server.URL()), time.Second, flags.DryRunNone)
+
+	// The documents are in the order of files, e.g. service.yaml sorts
+	// before tenant.yaml.
+	objects := []resource.MeshObject{
+		resource.ToIngress(&v1alpha1.Ingress{Name: "pet-ingress", Rules: []*v1alpha1.IngressRule{
+			{Paths: []*v1alpha1.IngressPath{{Backend: "vets"}, {Backend: "order"}}},
+		}}),
+		resource.ToCanary("vets", &v1alpha1.Canary{}),
+		resource.ToService(&v1alpha1.Service{Name: "vets", RegisterTenant: "pet"}),
+		resource.ToService(&v1alpha1.Service{Name: "payment", RegisterTenant: "shop"}),
+		resource.ToTenant(&v1alpha1.Tenant{Name: "pet"}),
+	}
+
+	planned, err := plan(objects, exists)
+	if err != nil {
+		t.Fatalf("plan failed: %v", err)
+	}
+	ids := []string{}
+	for _, object := range planned {
+		ids = append(ids, objectID(object))
+	}
+	expected := "Tenant/pet,Service/vets,Service/payment,Canary/vets,Ingress/pet-ingress"
+	if strings.Join(ids, ",") != expected {
+		t.Errorf("expect planned order %s but got %s", expected, strings.Join(ids, ","))
+	}
+
+	objects = []resource.MeshObject{
+		resource.ToService(&v1alpha1.Service{Name: "vets", RegisterTenant: "pet"}),
+		resource.ToResilience("visits", &v1alpha1.Resilience{}),
+		resource.ToLoadBalance("order", &v1alpha1.LoadBalance{}),
+	}
+	_, err = plan(objects, exists)
+	if err == nil {
+		t.Fatalf("expect error of missing references")
+	}
+	for _, missing := range []string{"Service/vets refers to Tenant/pet", "Resilience/visits refers to Service/visits"} {
+		if !strings.Contains(err.Error(), missing) {
+			t.Errorf("expect %q in error but got: %v", missing, err)
+		}
+	}
+	if strings.Contains(err.Error(), "LoadBalance/order") {
+		t.Errorf("expect reference to existing Service/order to be resolved but got: %v", err)
+	}
+
+	if _, err := plan(objects, nil); err != nil {
+		t.Errorf("expect no error without resolving the control plane but got: %v", err)
+	}
+}
@@ -0,0 +1,241 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	deleter "github.com/megaease/easemeshctl/cmd/client/command/delete"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/get"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+
+	"github.com/pkg/errors"
+)
+
+// pruneKinds lists all kinds in the order of deletion, the objects depending
+// on others are deleted before the ones they depend on.
+var pruneKinds = []string{
+	resource.KindIngress,
+	resource.KindCanary,
+	resource.KindLoadBalance,
+	resource.KindResilience,
+	resource.KindObservabilityTracings,
+	resource.KindObservabilityMetrics,
+	resource.KindObservabilityOutputServer,
+	resource.KindService,
+	resource.KindTenant,
+}
+
+// validatePrune checks the pruning options before anything is applied. As
+// kubectl does, pruning requires either a selector or --all.
+func validatePrune(flags *flags.Apply) error {
+	sel, err := util.ParseSelector(flags.Selector)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case sel.Empty() && !flags.All:
+		return errors.New("--prune requires a selector (-l) or --all")
+	case !sel.Empty() && flags.All:
+		return errors.New("--all and --selector are both specified")
+	}
+
+	return nil
+}
+
+// prune deletes the objects in the control plane which are not declared by
+// the applied objects. With a selector, only the objects recorded in the
+// appliedSetsFile by previous applies with the same selector are pruned,
+// and the record is replaced with the applied objects afterwards.
+func prune(applied []resource.MeshObject, flags *flags.Apply, appliedSetsFile string) error {
+	if err := validatePrune(flags); err != nil {
+		return err
+	}
+
+	if flags.IsClientDryRun() {
+		fmt.Printf("Pruning is skipped (dry run: %s), use --dry-run=server to list resources would be pruned\n", flags.DryRun)
+		return nil
+	}
+
+	sel, err := util.ParseSelector(flags.Selector)
+	if err != nil {
+		return err
+	}
+
+	declared := map[string]bool{}
+	for _, object := range applied {
+		for _, id := range declaredIDs(object) {
+			declared[id] = true
+		}
+	}
+
+	var sets appliedSets
+	previous := map[string]bool{}
+	if !sel.Empty() {
+		sets, err = loadAppliedSets(appliedSetsFile)
+		if err != nil {
+			return err
+		}
+		for _, id := range sets[sel.String()] {
+			previous[id] = true
+		}
+	}
+
+	client := meshclient.New(flags.Server, flags.ClientOptions()...)
+	var pruned []resource.MeshObject
+	for _, kind := range pruneKinds {
+		object, err := resource.NewObjectCreator().NewFromKind(resource.VersionKind{
+			APIVersion: resource.DefaultAPIVersion,
+			Kind:       kind,
+		})
+		if err != nil {
+			return err
+		}
+
+		objects, err := get.WrapGetterByMeshObject(object, client, flags.Timeout).Get()
+		if meshclient.IsNotFoundError(err) {
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "list %s", kind)
+		}
+
+		for _, object := range objects {
+			id := objectID(object)
+			if !declared[id] && (sel.Empty() || previous[id]) {
+				pruned = append(pruned, object)
+			}
+		}
+	}
+
+	if flags.IsDryRun() {
+		for _, object := range pruned {
+			fmt.Printf("%s/%s would be pruned (dry run: %s)\n", object.Kind(), object.Name(), flags.DryRun)
+		}
+		if len(pruned) == 0 {
+			fmt.Println("No resource to prune")
+		}
+		return nil
+	}
+
+	// The objects not pruned stay in the record to be pruned next time.
+	remained := map[string]bool{}
+	for id := range declared {
+		remained[id] = true
+	}
+
+	var errs []string
+	switch {
+	case len(pruned) == 0:
+		fmt.Println("No resource to prune")
+	case !flags.Yes && !confirmPrune(pruned):
+		fmt.Println("Pruning cancelled")
+		for _, object := range pruned {
+			remained[objectID(object)] = true
+		}
+	default:
+		for _, object := range pruned {
+			err := deleter.WrapDeleterByMeshObject(object, client, flags.Timeout).Delete()
+			if err != nil && !meshclient.IsNotFoundError(err) {
+				errs = append(errs, fmt.Sprintf("%s/%s pruned failed: %v", object.Kind(), object.Name(), err))
+				remained[objectID(object)] = true
+				continue
+			}
+			fmt.Printf("%s/%s pruned\n", object.Kind(), object.Name())
+		}
+	}
+
+	if !sel.Empty() {
+		ids := make([]string, 0, len(remained))
+		for id := range remained {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		sets[sel.String()] = ids
+		if err := sets.save(appliedSetsFile); err != nil {
+			errs = append(errs, fmt.Sprintf("record applied resources failed: %v", err))
+		}
+	}
+
+	if len(errs) != 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
+
+// declaredIDs returns IDs of objects declared by the object, a Service
+// declares its per-service kinds carried in its spec as well.
+func declaredIDs(object resource.MeshObject) []string {
+	ids := []string{objectID(object)}
+
+	service, ok := object.(*resource.Service)
+	if !ok || service.Spec == nil {
+		return ids
+	}
+
+	declare := func(kind string) {
+		ids = append(ids, kind+"/"+service.Name())
+	}
+	if service.Spec.Canary != nil {
+		declare(resource.KindCanary)
+	}
+	if service.Spec.LoadBalance != nil {
+		declare(resource.KindLoadBalance)
+	}
+	if service.Spec.Resilience != nil {
+		declare(resource.KindResilience)
+	}
+	if observability := service.Spec.Observability; observability != nil {
+		if observability.Tracings != nil {
+			declare(resource.KindObservabilityTracings)
+		}
+		if observability.Metrics != nil {
+			declare(resource.KindObservabilityMetrics)
+		}
+		if observability.OutputServer != nil {
+			declare(resource.KindObservabilityOutputServer)
+		}
+	}
+
+	return ids
+}
+
+func confirmPrune(objects []resource.MeshObject) bool {
+	fmt.Println("The following resources will be pruned:")
+	for _, object := range objects {
+		fmt.Printf("  %s/%s\n", object.Kind(), object.Name())
+	}
+	fmt.Print("Do you want to continue? [y/N]: ")
+
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
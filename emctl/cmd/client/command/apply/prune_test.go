@@ -0,0 +1,172 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply
+
+import (
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+func newPruneFlags(server, dryRun, selector string, all bool) *flags.Apply {
+	return &flags.Apply{
+		AdminGlobal:   &flags.AdminGlobal{Server: server, Timeout: time.Second},
+		AdminDryRun:   &flags.AdminDryRun{DryRun: dryRun},
+		AdminSelector: &flags.AdminSelector{Selector: selector},
+		Prune:         true,
+		All:           all,
+		Yes:           true,
+	}
+}
+
+func TestPrune(t *testing.T) {
+	applied := []resource.MeshObject{
+		resource.ToTenant(&v1alpha1.Tenant{Name: "pet"}),
+		resource.ToService(&v1alpha1.Service{Name: "vets", RegisterTenant: "pet",
+			Canary: &v1alpha1.Canary{}}),
+	}
+
+	tests := []struct {
+		dryRun   string
+		expected []string
+	}{
+		{flags.DryRunNone, []string{
+			"DELETE /apis/v1/mesh/services/owners/canary",
+			"DELETE /apis/v1/mesh/services/owners",
+			"DELETE /apis/v1/mesh/tenants/store",
+		}},
+		{flags.DryRunServer, nil},
+		{flags.DryRunClient, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dryRun, func(t *testing.T) {
+			server := fake.NewServer()
+			defer server.Close()
+			server.AddTenant(&v1alpha1.Tenant{Name: "pet"})
+			server.AddTenant(&v1alpha1.Tenant{Name: "store"})
+			server.AddService(&v1alpha1.Service{Name: "vets", RegisterTenant: "pet",
+				Canary: &v1alpha1.Canary{}})
+			server.AddService(&v1alpha1.Service{Name: "owners", RegisterTenant: "pet",
+				Canary: &v1alpha1.Canary{}})
+
+			file := filepath.Join(t.TempDir(), appliedSetsFileName)
+			err := prune(applied, newPruneFlags(server.URL(), tt.dryRun, "", true), file)
+			if err != nil {
+				t.Fatalf("prune failed: %v", err)
+			}
+
+			requests := server.Requests()
+			if tt.dryRun == flags.DryRunClient && len(requests) != 0 {
+				t.Errorf("expect no requests in client dry run but got %v", requests)
+			}
+			var mutations []string
+			for _, r := range requests {
+				if r.Method != http.MethodGet {
+					mutations = append(mutations, r.Method+" "+r.Path)
+				}
+			}
+			if !reflect.DeepEqual(mutations, tt.expected) {
+				t.Errorf("expect requests %v but got %v", tt.expected, mutations)
+			}
+		})
+	}
+}
+
+func TestPruneSelector(t *testing.T) {
+	applied := []resource.MeshObject{
+		resource.ToService(&v1alpha1.Service{Name: "vets", RegisterTenant: "pet"}),
+	}
+
+	server := fake.NewServer()
+	defer server.Close()
+	server.AddTenant(&v1alpha1.Tenant{Name: "pet"})
+	server.AddService(&v1alpha1.Service{Name: "vets", RegisterTenant: "pet"})
+	server.AddService(&v1alpha1.Service{Name: "owners", RegisterTenant: "pet"})
+	server.AddService(&v1alpha1.Service{Name: "orders", RegisterTenant: "pet"})
+
+	// Only the objects applied with the same selector before are pruned,
+	// Service/gone is not in the control plane any more.
+	file := filepath.Join(t.TempDir(), appliedSetsFileName)
+	err := appliedSets{
+		"team=pet":   {"Service/gone", "Service/owners", "Service/vets"},
+		"team=order": {"Service/orders"},
+	}.save(file)
+	if err != nil {
+		t.Fatalf("save applied sets failed: %v", err)
+	}
+
+	err = prune(applied, newPruneFlags(server.URL(), flags.DryRunNone, "team=pet", false), file)
+	if err != nil {
+		t.Fatalf("prune failed: %v", err)
+	}
+
+	var mutations []string
+	for _, r := range server.Requests() {
+		if r.Method != http.MethodGet {
+			mutations = append(mutations, r.Method+" "+r.Path)
+		}
+	}
+	expected := []string{"DELETE /apis/v1/mesh/services/owners"}
+	if !reflect.DeepEqual(mutations, expected) {
+		t.Errorf("expect requests %v but got %v", expected, mutations)
+	}
+
+	sets, err := loadAppliedSets(file)
+	if err != nil {
+		t.Fatalf("load applied sets failed: %v", err)
+	}
+	expectedSets := appliedSets{
+		"team=pet":   {"Service/vets"},
+		"team=order": {"Service/orders"},
+	}
+	if !reflect.DeepEqual(sets, expectedSets) {
+		t.Errorf("expect applied sets %v but got %v", expectedSets, sets)
+	}
+}
+
+func TestValidatePrune(t *testing.T) {
+	tests := []struct {
+		selector string
+		all      bool
+		valid    bool
+	}{
+		{"", true, true},
+		{"", false, false},
+		{"team=order", false, true},
+		{"team=order", true, false},
+		{"team in (", false, false},
+	}
+
+	for _, tt := range tests {
+		err := validatePrune(newPruneFlags("", flags.DryRunNone, tt.selector, tt.all))
+		if tt.valid && err != nil {
+			t.Errorf("expect selector %q all %v valid but got %v", tt.selector, tt.all, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("expect selector %q all %v invalid", tt.selector, tt.all)
+		}
+	}
+}
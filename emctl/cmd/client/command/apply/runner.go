@@ -0,0 +1,170 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// runner applies the planned objects kind by kind in the order of the
+	// plan. The objects of the same kind don't refer to each other, so up
+	// to concurrency objects of them are applied in parallel. An object is
+	// skipped and counted as failed if any object it refers to failed.
+	runner struct {
+		concurrency int
+		dryRun      string
+		newApplier  func(object resource.MeshObject) Applier
+
+		mutex   sync.Mutex
+		failed  map[string]bool
+		summary map[string]*kindSummary
+	}
+
+	// kindSummary counts the objects of a kind by the result of applying
+	kindSummary struct {
+		created    int
+		configured int
+		unchanged  int
+		failed     int
+	}
+)
+
+func newRunner(concurrency int, dryRun string, newApplier func(object resource.MeshObject) Applier) *runner {
+	return &runner{
+		concurrency: concurrency,
+		dryRun:      dryRun,
+		newApplier:  newApplier,
+		failed:      map[string]bool{},
+		summary:     map[string]*kindSummary{},
+	}
+}
+
+// run applies the objects sorted by kind, and returns the number of the
+// failed ones.
+func (r *runner) run(objects []resource.MeshObject) int {
+	for start := 0; start < len(objects); {
+		end := start + 1
+		for end < len(objects) && objects[end].Kind() == objects[start].Kind() {
+			end++
+		}
+		r.runKind(objects[start:end])
+		start = end
+	}
+
+	return len(r.failed)
+}
+
+func (r *runner) runKind(objects []resource.MeshObject) {
+	tokens := make(chan struct{}, r.concurrency)
+	wg := &sync.WaitGroup{}
+	for _, object := range objects {
+		tokens <- struct{}{}
+		wg.Add(1)
+		go func(object resource.MeshObject) {
+			defer func() {
+				<-tokens
+				wg.Done()
+			}()
+			r.apply(object)
+		}(object)
+	}
+	wg.Wait()
+}
+
+func (r *runner) apply(object resource.MeshObject) {
+	var result Result
+	err := r.failedReference(object)
+	if err == nil {
+		result, err = r.newApplier(object).Apply()
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	summary := r.summary[object.Kind()]
+	if summary == nil {
+		summary = &kindSummary{}
+		r.summary[object.Kind()] = summary
+	}
+
+	if err != nil {
+		summary.failed++
+		r.failed[objectID(object)] = true
+		if r.dryRun == flags.DryRunNone {
+			err = fmt.Errorf("%s/%s applied failed: %s", object.Kind(), object.Name(), err)
+		} else {
+			err = fmt.Errorf("%s/%s dry run failed: %s", object.Kind(), object.Name(), err)
+		}
+		common.OutputError(err)
+		return
+	}
+
+	switch result {
+	case ResultCreated:
+		summary.created++
+	case ResultConfigured:
+		summary.configured++
+	case ResultUnchanged:
+		summary.unchanged++
+	}
+
+	// The dry run appliers print what they would do by themselves.
+	if r.dryRun == flags.DryRunNone {
+		fmt.Printf("%s/%s %s\n", object.Kind(), object.Name(), result)
+	}
+}
+
+// failedReference returns an error if any object the object refers to failed.
+func (r *runner) failedReference(object resource.MeshObject) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, ref := range references(object) {
+		if r.failed[objectID(ref)] {
+			return errors.Errorf("skipped because %s failed", objectID(ref))
+		}
+	}
+	return nil
+}
+
+// printSummary prints the number of objects of every kind by the result,
+// it prints nothing in the client dry run mode, which resolves nothing.
+func (r *runner) printSummary(out io.Writer) {
+	if r.dryRun == flags.DryRunClient || len(r.summary) == 0 {
+		return
+	}
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "\nKIND\tCREATED\tCONFIGURED\tUNCHANGED\tFAILED")
+	for _, kind := range resource.Kinds {
+		if s := r.summary[kind]; s != nil {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\n", kind, s.created, s.configured, s.unchanged, s.failed)
+		}
+	}
+	w.Flush()
+}
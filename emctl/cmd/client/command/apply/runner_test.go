@@ -0,0 +1,82 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package apply
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+func TestRunner(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.AddService(&v1alpha1.Service{Name: "service-0", RegisterTenant: "pet"})
+	server.SetStatus(http.MethodPost, "/apis/v1/mesh/services/service-1", http.StatusInternalServerError)
+	client := meshclient.New(server.URL())
+
+	objects := []resource.MeshObject{resource.ToTenant(&v1alpha1.Tenant{Name: "pet"})}
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("service-%d", i)
+		objects = append(objects,
+			resource.ToService(&v1alpha1.Service{Name: name, RegisterTenant: "pet"}),
+			resource.ToCanary(name, &v1alpha1.Canary{}))
+	}
+	objects, err := plan(objects, nil)
+	if err != nil {
+		t.Fatalf("plan failed: %v", err)
+	}
+
+	r := newRunner(4, flags.DryRunNone, func(object resource.MeshObject) Applier {
+		return WrapApplierByMeshObject(object, client, time.Second)
+	})
+	if failed := r.run(objects); failed != 2 {
+		t.Errorf("expect Service/service-1 and Canary/service-1 failed but got %d failed", failed)
+	}
+
+	for _, request := range server.Requests() {
+		if request.Path == "/apis/v1/mesh/services/service-1/canary" {
+			t.Errorf("expect Canary/service-1 to be skipped but got %s %s", request.Method, request.Path)
+		}
+	}
+
+	buff := &bytes.Buffer{}
+	r.printSummary(buff)
+	expected := []string{
+		"KIND CREATED CONFIGURED UNCHANGED FAILED",
+		"Tenant 1 0 0 0",
+		"Service 18 0 1 1",
+		"Canary 19 0 0 1",
+	}
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(buff.String()), "\n") {
+		got = append(got, strings.Join(strings.Fields(line), " "))
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expect summary:\n%s\nbut got:\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+}
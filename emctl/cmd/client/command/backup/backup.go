@@ -0,0 +1,174 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	yamljsontool "github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+const (
+	// manifestTitle is the first line of a backup, the manifest follows it
+	// as YAML comments, so that the backup is still a valid bundle of
+	// resources for emctl apply and emctl validate.
+	manifestTitle = "# EaseMesh backup"
+	commentPrefix = "# "
+)
+
+// manifest describes a backup
+type manifest struct {
+	Timestamp time.Time      `json:"timestamp"`
+	Server    string         `json:"server"`
+	Counts    map[string]int `json:"counts"`
+}
+
+// RunBackup is the entrypoint of the emctl backup sub command
+func RunBackup(flags *flags.Backup) {
+	client := meshclient.New(flags.Server, flags.ClientOptions()...)
+	content, m, err := backup(client, flags.Timeout, flags.Server, time.Now())
+	if err != nil {
+		common.ExitWithErrorf("backup failed: %v", err)
+	}
+
+	if flags.OutputFile == "" {
+		fmt.Print(string(content))
+		return
+	}
+
+	err = ioutil.WriteFile(flags.OutputFile, content, 0644)
+	if err != nil {
+		common.ExitWithErrorf("write backup to %s failed: %v", flags.OutputFile, err)
+	}
+
+	total := 0
+	for _, kind := range resource.Kinds {
+		if count := m.Counts[kind]; count != 0 {
+			fmt.Printf("%s: %d\n", kind, count)
+			total += count
+		}
+	}
+	fmt.Printf("%d resources backed up to %s\n", total, flags.OutputFile)
+}
+
+// backup lists all resources in the control plane and renders them as a
+// multi-document YAML bundle with the manifest as the header. The kinds
+// carried in the spec of a Service are backed up as individual resources,
+// so they're removed from the Service.
+func backup(client meshclient.MeshClient, timeout time.Duration, server string, now time.Time) ([]byte, *manifest, error) {
+	m := &manifest{
+		Timestamp: now.UTC().Truncate(time.Second),
+		Server:    server,
+		Counts:    map[string]int{},
+	}
+
+	documents := &bytes.Buffer{}
+	for _, kind := range resource.Kinds {
+		objects, err := list(client, timeout, kind)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, object := range objects {
+			if service, ok := object.(*resource.Service); ok && service.Spec != nil {
+				service.Spec.Resilience = nil
+				service.Spec.Canary = nil
+				service.Spec.LoadBalance = nil
+				service.Spec.Observability = nil
+			}
+
+			buff, err := yamljsontool.Marshal(object)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "marshal %s/%s to yaml", object.Kind(), object.Name())
+			}
+			fmt.Fprintf(documents, "---\n%s", buff)
+		}
+		m.Counts[kind] = len(objects)
+	}
+
+	header, err := m.marshal()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return append(header, documents.Bytes()...), m, nil
+}
+
+func list(client meshclient.MeshClient, timeout time.Duration, kind string) ([]resource.MeshObject, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+	defer cancelFunc()
+
+	objects, err := client.V1Alpha1().Resource(kind).List(ctx)
+	if meshclient.IsNotFoundError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "list %s", kind)
+	}
+	return objects, nil
+}
+
+// marshal renders the manifest as the YAML comments.
+func (m *manifest) marshal() ([]byte, error) {
+	buff, err := yamljsontool.Marshal(m)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal manifest to yaml")
+	}
+
+	header := &bytes.Buffer{}
+	header.WriteString(manifestTitle + "\n")
+	for _, line := range strings.Split(strings.TrimSpace(string(buff)), "\n") {
+		header.WriteString(commentPrefix + line + "\n")
+	}
+	return header.Bytes(), nil
+}
+
+// parseManifest parses the manifest in the header of the backup, it returns
+// nil if the content doesn't start with the manifest.
+func parseManifest(content []byte) (*manifest, error) {
+	lines := strings.Split(string(content), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != manifestTitle {
+		return nil, nil
+	}
+
+	buff := &bytes.Buffer{}
+	for _, line := range lines[1:] {
+		if !strings.HasPrefix(line, commentPrefix) {
+			break
+		}
+		buff.WriteString(strings.TrimPrefix(line, commentPrefix) + "\n")
+	}
+
+	m := &manifest{}
+	err := yamljsontool.Unmarshal(buff.Bytes(), m)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse manifest")
+	}
+	return m, nil
+}
@@ -0,0 +1,162 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+func newFakeServer() *fake.Server {
+	server := fake.NewServer()
+	server.AddTenant(&v1alpha1.Tenant{Name: "pet", Services: []string{"vets"}})
+	server.AddService(&v1alpha1.Service{
+		Name:           "vets",
+		RegisterTenant: "pet",
+		Sidecar:        &v1alpha1.Sidecar{DiscoveryType: "eureka", IngressPort: 13001},
+		Canary:         &v1alpha1.Canary{},
+		LoadBalance:    &v1alpha1.LoadBalance{Policy: "random"},
+		Observability: &v1alpha1.Observability{
+			Tracings: &v1alpha1.ObservabilityTracings{Enabled: true},
+		},
+	})
+	server.AddService(&v1alpha1.Service{Name: "visits", RegisterTenant: "pet", Sidecar: &v1alpha1.Sidecar{DiscoveryType: "nacos"}})
+	server.AddIngress(&v1alpha1.Ingress{Name: "pet-ingress"})
+	server.AddServiceInstance(&v1alpha1.ServiceInstance{ServiceName: "vets", InstanceID: "vets-1"})
+	return server
+}
+
+// dump lists all resources in the control plane as JSON.
+func dump(t *testing.T, client meshclient.MeshClient) string {
+	all := map[string][]resource.MeshObject{}
+	for _, kind := range resource.Kinds {
+		objects, err := client.V1Alpha1().Resource(kind).List(context.Background())
+		if err != nil && !meshclient.IsNotFoundError(err) {
+			t.Fatalf("list %s failed: %v", kind, err)
+		}
+		all[kind] = objects
+	}
+
+	buff, err := json.Marshal(all)
+	if err != nil {
+		t.Fatalf("marshal resources failed: %v", err)
+	}
+	return string(buff)
+}
+
+func writeBackup(t *testing.T, client meshclient.MeshClient) string {
+	content, m, err := backup(client, time.Second, "127.0.0.1:2381", time.Now())
+	if err != nil {
+		t.Fatalf("backup failed: %v", err)
+	}
+	if m.Counts[resource.KindService] != 2 || m.Counts[resource.KindCanary] != 1 ||
+		m.Counts[resource.KindResilience] != 0 || m.Counts[resource.KindServiceInstance] != 0 {
+		t.Errorf("unexpected counts %v", m.Counts)
+	}
+
+	path := filepath.Join(t.TempDir(), "mesh-backup.yaml")
+	if err := ioutil.WriteFile(path, content, 0644); err != nil {
+		t.Fatalf("write backup failed: %v", err)
+	}
+	return path
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	source := newFakeServer()
+	defer source.Close()
+	sourceClient := meshclient.New(source.URL())
+	path := writeBackup(t, sourceClient)
+
+	m, objects, err := loadBackup(path)
+	if err != nil {
+		t.Fatalf("load backup failed: %v", err)
+	}
+	if m == nil || m.Server != "127.0.0.1:2381" {
+		t.Fatalf("expect manifest of server 127.0.0.1:2381 but got %+v", m)
+	}
+	kinds := []string{}
+	for _, object := range objects {
+		kinds = append(kinds, object.Kind())
+	}
+	expectedKinds := "Tenant,Service,Service,LoadBalance,Canary,ObservabilityTracings,Ingress"
+	if strings.Join(kinds, ",") != expectedKinds {
+		t.Errorf("expect restoring order %s but got %s", expectedKinds, strings.Join(kinds, ","))
+	}
+
+	target := fake.NewServer()
+	defer target.Close()
+	targetClient := meshclient.New(target.URL())
+
+	result, err := restore(targetClient, time.Second, objects, flags.ConflictFail)
+	if err != nil || result.restored != len(objects) {
+		t.Fatalf("expect %d restored but got %+v, %v", len(objects), result, err)
+	}
+	if expected, got := dump(t, sourceClient), dump(t, targetClient); expected != got {
+		t.Errorf("expect restored resources:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	result, err = restore(targetClient, time.Second, objects, flags.ConflictSkip)
+	if err != nil || result.skipped != len(objects) {
+		t.Errorf("expect %d skipped but got %+v, %v", len(objects), result, err)
+	}
+
+	// Overwriting a Service clears the kinds carried in its spec, so they're
+	// restored again.
+	result, err = restore(targetClient, time.Second, objects, flags.ConflictOverwrite)
+	if err != nil || result.overwritten == 0 || result.restored+result.overwritten != len(objects) {
+		t.Errorf("expect %d restored or overwritten but got %+v, %v", len(objects), result, err)
+	}
+	if expected, got := dump(t, sourceClient), dump(t, targetClient); expected != got {
+		t.Errorf("expect overwritten resources:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	result, err = restore(targetClient, time.Second, objects, flags.ConflictFail)
+	if err == nil || result.restored+result.overwritten+result.skipped != 0 {
+		t.Errorf("expect restoring to stop at the first existing resource but got %+v, %v", result, err)
+	}
+}
+
+func TestLoadTruncatedBackup(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	path := writeBackup(t, meshclient.New(server.URL()))
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read backup failed: %v", err)
+	}
+	truncated := content[:strings.LastIndex(string(content), "---")]
+	if err := ioutil.WriteFile(path, truncated, 0644); err != nil {
+		t.Fatalf("write backup failed: %v", err)
+	}
+
+	if _, _, err := loadBackup(path); err == nil || !strings.Contains(err.Error(), "truncated") {
+		t.Errorf("expect error of truncated backup but got %v", err)
+	}
+}
@@ -0,0 +1,180 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+)
+
+// restoreResult counts the resources by the result of restoring them
+type restoreResult struct {
+	restored    int
+	overwritten int
+	skipped     int
+	failed      int
+}
+
+// RunRestore is the entrypoint of the emctl restore sub command
+func RunRestore(flags *flags.Restore) {
+	if flags.YamlFile == "" {
+		common.ExitWithErrorf("no backup file specified")
+	}
+
+	if err := flags.Validate(); err != nil {
+		common.ExitWithErrorf("%v", err)
+	}
+
+	m, objects, err := loadBackup(flags.YamlFile)
+	if err != nil {
+		common.ExitWithErrorf("load backup %s failed: %v", flags.YamlFile, err)
+	}
+
+	if m == nil {
+		common.OutputWarningf("%s has no manifest of emctl backup, restore the resources in it anyway", flags.YamlFile)
+	} else {
+		fmt.Printf("Restoring the backup of %s taken at %s\n", m.Server, m.Timestamp.Format(time.RFC3339))
+	}
+
+	client := meshclient.New(flags.Server, flags.ClientOptions()...)
+	result, err := restore(client, flags.Timeout, objects, flags.Conflict)
+	fmt.Printf("%d restored, %d overwritten, %d skipped, %d failed\n",
+		result.restored, result.overwritten, result.skipped, result.failed)
+	if err != nil {
+		common.ExitWithErrorf("restore failed: %v", err)
+	}
+}
+
+// loadBackup reads the manifest and the resources of the backup, the
+// resources are sorted in the order of restoring. It fails if the number of
+// resources doesn't match the manifest, the backup might be truncated.
+func loadBackup(path string) (*manifest, []resource.MeshObject, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m, err := parseManifest(content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vss, err := util.NewVisitorBuilder().
+		FilenameParam(&util.FilenameOptions{Filenames: []string{path}}).
+		Do()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var objects []resource.MeshObject
+	invalid := 0
+	for _, vs := range vss {
+		// The visitor has reported errors of every document already.
+		vs.Visit(func(mo resource.MeshObject, e error) error {
+			if e != nil {
+				invalid++
+				return errors.Wrap(e, "visit failed")
+			}
+			objects = append(objects, mo)
+			return nil
+		})
+	}
+	if invalid > 0 {
+		return nil, nil, errors.Errorf("%d resources are invalid", invalid)
+	}
+
+	if m != nil {
+		counts := map[string]int{}
+		for _, object := range objects {
+			counts[object.Kind()]++
+		}
+		for _, kind := range resource.Kinds {
+			if counts[kind] != m.Counts[kind] {
+				return nil, nil, errors.Errorf("the manifest records %d %s but got %d, the backup might be truncated",
+					m.Counts[kind], kind, counts[kind])
+			}
+		}
+	}
+
+	resource.SortByKind(objects)
+
+	return m, objects, nil
+}
+
+// restore creates the objects in order, the existing ones are handled by the
+// conflict strategy. It stops at the first existing object with the fail
+// strategy, and keeps restoring the remaining objects if one fails.
+func restore(client meshclient.MeshClient, timeout time.Duration,
+	objects []resource.MeshObject, conflict string) (restoreResult, error) {
+	result := restoreResult{}
+
+	for _, object := range objects {
+		err := restoreObject(client, timeout, object, conflict, &result)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if result.failed > 0 {
+		return result, errors.Errorf("%d resources restored failed", result.failed)
+	}
+	return result, nil
+}
+
+func restoreObject(client meshclient.MeshClient, timeout time.Duration,
+	object resource.MeshObject, conflict string, result *restoreResult) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+	defer cancelFunc()
+
+	rest := client.V1Alpha1().Resource(object.Kind())
+	err := rest.Create(ctx, object)
+	switch {
+	case err == nil:
+		result.restored++
+		fmt.Printf("%s/%s restored\n", object.Kind(), object.Name())
+	case !meshclient.IsConflictError(err):
+		result.failed++
+		common.OutputErrorf("%s/%s restored failed: %v", object.Kind(), object.Name(), err)
+	case conflict == flags.ConflictSkip:
+		result.skipped++
+		fmt.Printf("%s/%s skipped, it already exists\n", object.Kind(), object.Name())
+	case conflict == flags.ConflictOverwrite:
+		err = rest.Patch(ctx, object)
+		if err != nil {
+			result.failed++
+			common.OutputErrorf("%s/%s overwritten failed: %v", object.Kind(), object.Name(), err)
+			return nil
+		}
+		result.overwritten++
+		fmt.Printf("%s/%s overwritten\n", object.Kind(), object.Name())
+	default:
+		return errors.Errorf("%s/%s already exists in the control plane", object.Kind(), object.Name())
+	}
+
+	return nil
+}
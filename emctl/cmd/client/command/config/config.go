@@ -0,0 +1,188 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/rcfile"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func loadRCFile() *rcfile.RCFile {
+	rc, err := rcfile.New()
+	if err != nil {
+		common.ExitWithErrorf("new rcfile failed: %v", err)
+	}
+
+	err = rc.Unmarshal()
+	if err != nil && !os.IsNotExist(errors.Cause(err)) {
+		common.ExitWithErrorf("unmarshal rcfile failed: %v", err)
+	}
+
+	return rc
+}
+
+func saveRCFile(rc *rcfile.RCFile) {
+	err := rc.Marshal()
+	if err != nil {
+		common.ExitWithErrorf("save rcfile failed: %v", err)
+	}
+}
+
+func contextName(cmd *cobra.Command) string {
+	cmdArgs := cmd.Flags().Args()
+	if len(cmdArgs) != 1 {
+		common.ExitWithErrorf("invalid command args: support <context name>")
+	}
+	return cmdArgs[0]
+}
+
+// RunGetContexts is the entrypoint of the emctl config get-contexts sub command
+func RunGetContexts(cmd *cobra.Command) {
+	rc := loadRCFile()
+	if len(rc.Contexts) == 0 {
+		fmt.Println("No context")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Current", "Name", "Server", "Timeout", "Namespace", "Tenant"})
+
+	table.SetBorder(false)
+	table.SetRowLine(false)
+	table.SetColumnSeparator("")
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetHeaderLine(false)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+
+	for _, c := range rc.Contexts {
+		current := ""
+		if c.Name == rc.CurrentContext {
+			current = "*"
+		}
+		timeout := ""
+		if c.Timeout != 0 {
+			timeout = c.Timeout.String()
+		}
+		table.Append([]string{current, c.Name, c.Server, timeout, c.Namespace, c.Tenant})
+	}
+
+	table.Render()
+}
+
+// RunUseContext is the entrypoint of the emctl config use-context sub command
+func RunUseContext(cmd *cobra.Command) {
+	name := contextName(cmd)
+	rc := loadRCFile()
+
+	err := rc.UseContext(name)
+	if err != nil {
+		common.ExitWithErrorf("%v", err)
+	}
+
+	saveRCFile(rc)
+	fmt.Printf("Switched to context %s\n", name)
+}
+
+// RunSetContext is the entrypoint of the emctl config set-context sub command,
+// it creates the context or modifies fields of the context set in command line.
+func RunSetContext(cmd *cobra.Command, flags *flags.ConfigSetContext) {
+	name := contextName(cmd)
+	rc := loadRCFile()
+
+	context := rc.GetContext(name)
+	created := context == nil
+	if created {
+		context = &rcfile.Context{Name: name}
+	}
+
+	changed := cmd.Flags().Changed
+	if changed("server") {
+		context.Server = flags.Server
+	}
+	if changed("timeout") {
+		context.Timeout = rcfile.Duration(flags.Timeout)
+	}
+	if changed("certificate-authority") {
+		context.CertificateAuthority = flags.CertificateAuthority
+	}
+	if changed("client-certificate") {
+		context.ClientCertificate = flags.ClientCertificate
+	}
+	if changed("client-key") {
+		context.ClientKey = flags.ClientKey
+	}
+	if changed("insecure-skip-tls-verify") {
+		context.InsecureSkipTLSVerify = flags.InsecureSkipTLSVerify
+	}
+	if changed("token") {
+		context.Token = flags.Token
+	}
+	if changed("username") {
+		context.Username = flags.Username
+	}
+	if changed("password") {
+		context.Password = flags.Password
+	}
+	if changed("mesh-namespace") {
+		context.Namespace = flags.Namespace
+	}
+	if changed("tenant") {
+		context.Tenant = flags.Tenant
+	}
+
+	if context.Server == "" {
+		common.ExitWithErrorf("server of context %s is required", name)
+	}
+
+	rc.SetContext(context)
+	if flags.Use || rc.CurrentContext == "" {
+		rc.CurrentContext = name
+	}
+
+	saveRCFile(rc)
+	if created {
+		fmt.Printf("Context %s created\n", name)
+	} else {
+		fmt.Printf("Context %s modified\n", name)
+	}
+}
+
+// RunDeleteContext is the entrypoint of the emctl config delete-context sub command
+func RunDeleteContext(cmd *cobra.Command) {
+	name := contextName(cmd)
+	rc := loadRCFile()
+
+	err := rc.DeleteContext(name)
+	if err != nil {
+		common.ExitWithErrorf("%v", err)
+	}
+
+	saveRCFile(rc)
+	fmt.Printf("Context %s deleted\n", name)
+	if rc.CurrentContext == "" {
+		fmt.Printf("The current context is unset, run emctl config use-context to set it\n")
+	}
+}
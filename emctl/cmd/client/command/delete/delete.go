@@ -19,8 +19,10 @@ package delete
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/get"
 	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
 	"github.com/megaease/easemeshctl/cmd/client/resource"
 	"github.com/megaease/easemeshctl/cmd/client/util"
@@ -32,6 +34,19 @@ import (
 
 // Run is the entrypoint of the emctl delete sub command
 func Run(cmd *cobra.Command, flags *flags.Delete) {
+	if err := flags.AdminDryRun.Validate(); err != nil {
+		common.ExitWithErrorf("%v", err)
+	}
+
+	sel, err := util.ParseSelector(flags.Selector)
+	if err != nil {
+		common.ExitWithErrorf("%v", err)
+	}
+
+	if flags.All && !sel.Empty() {
+		common.ExitWithErrorf("--all and --selector are both specified")
+	}
+
 	visitorBulder := util.NewVisitorBuilder()
 
 	cmdArgs := cmd.Flags().Args()
@@ -44,16 +59,26 @@ func Run(cmd *cobra.Command, flags *flags.Delete) {
 		if flags.YamlFile != "" {
 			common.ExitWithErrorf("file and command args are both specified")
 		}
-		if len(cmdArgs) != 2 {
-			common.ExitWithErrorf("invalid command args: support <resource kind> <resource name>")
+
+		switch {
+		case len(cmdArgs) == 1 && (flags.All || !sel.Empty()):
+			visitorBulder.CommandParam(&util.CommandOptions{
+				Kind: cmdArgs[0],
+			})
+		case len(cmdArgs) == 2 && !flags.All && sel.Empty():
+			visitorBulder.CommandParam(&util.CommandOptions{
+				Kind: cmdArgs[0],
+				Name: cmdArgs[1],
+			})
+		default:
+			common.ExitWithErrorf("invalid command args: support <resource kind> <resource name>, or <resource kind> with --all or --selector")
 		}
-		visitorBulder.CommandParam(&util.CommandOptions{
-			Kind: cmdArgs[0],
-			Name: cmdArgs[1],
-		})
 	}
 
 	if flags.YamlFile != "" {
+		if flags.All {
+			common.ExitWithErrorf("file and --all are both specified")
+		}
 		visitorBulder.FilenameParam(&util.FilenameOptions{
 			Recursive: flags.Recursive,
 			Filenames: []string{flags.YamlFile},
@@ -65,6 +90,8 @@ func Run(cmd *cobra.Command, flags *flags.Delete) {
 		common.ExitWithErrorf("build visitor failed: %s", err)
 	}
 
+	client := meshclient.New(flags.Server, flags.ClientOptions()...)
+
 	var errs []error
 	for _, vs := range vss {
 		err := vs.Visit(func(mo resource.MeshObject, e error) error {
@@ -72,12 +99,21 @@ func Run(cmd *cobra.Command, flags *flags.Delete) {
 				return errors.Wrap(e, "visit failed")
 			}
 
-			err := WrapDeleterByMeshObject(mo, meshclient.New(flags.Server), flags.Timeout).Delete()
+			objects, err := selectObjects(mo, sel, client, flags)
 			if err != nil {
-				return errors.Wrapf(err, "%s/%s deleted failed", mo.Kind(), mo.Name())
+				return err
+			}
+
+			var errs []string
+			for _, object := range objects {
+				if err := deleteObject(object, client, flags); err != nil {
+					errs = append(errs, err.Error())
+				}
+			}
+			if len(errs) > 0 {
+				return errors.New(strings.Join(errs, "\n"))
 			}
 
-			fmt.Printf("%s/%s deleted successfully\n", mo.Kind(), mo.Name())
 			return nil
 		})
 
@@ -87,6 +123,58 @@ func Run(cmd *cobra.Command, flags *flags.Delete) {
 	}
 
 	if len(errs) > 0 {
-		common.ExitWithErrorf("deleting resources has errors occurred")
+		msgs := make([]string, 0, len(errs))
+		for _, err := range errs {
+			msgs = append(msgs, err.Error())
+		}
+		common.ExitWithErrorf("deleting resources has errors occurred:\n%s", strings.Join(msgs, "\n"))
+	}
+}
+
+// selectObjects returns the objects to delete for the visited object. An
+// object without name stands for all objects of its kind in the control
+// plane, which are listed and filtered by the selector, the others are
+// deleted only if they match the selector. The selector is rejected for
+// the kinds listed without labels, which it would select all of.
+func selectObjects(mo resource.MeshObject, sel util.Selector,
+	client meshclient.MeshClient, flags *flags.Delete) ([]resource.MeshObject, error) {
+	if mo.Name() != "" {
+		return util.FilterObjects([]resource.MeshObject{mo}, sel), nil
+	}
+
+	if err := util.ValidateSelectorKind(sel, mo.Kind()); err != nil {
+		return nil, errors.Errorf("%v, use --all to delete all of them", err)
+	}
+
+	objects, err := get.WrapGetterByMeshObject(mo, client, flags.Timeout).Get()
+	if meshclient.IsNotFoundError(err) {
+		objects = nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "list %s failed", mo.Kind())
 	}
+
+	objects = util.FilterObjects(objects, sel)
+	if len(objects) == 0 {
+		fmt.Printf("No %s resource to delete\n", mo.Kind())
+	}
+
+	return objects, nil
+}
+
+func deleteObject(mo resource.MeshObject, client meshclient.MeshClient, flags *flags.Delete) error {
+	if flags.IsDryRun() {
+		err := newDryRunDeleter(mo, client, flags.Timeout, flags.DryRun).Delete()
+		if err != nil {
+			return errors.Wrapf(err, "%s/%s dry run failed", mo.Kind(), mo.Name())
+		}
+		return nil
+	}
+
+	err := WrapDeleterByMeshObject(mo, client, flags.Timeout).Delete()
+	if err != nil {
+		return errors.Wrapf(err, "%s/%s deleted failed", mo.Kind(), mo.Name())
+	}
+
+	fmt.Printf("%s/%s deleted successfully\n", mo.Kind(), mo.Name())
+	return nil
 }
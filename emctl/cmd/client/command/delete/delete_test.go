@@ -0,0 +1,122 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package delete
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+)
+
+func TestSelectObjects(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.AddServiceInstance(&v1alpha1.ServiceInstance{ServiceName: "vets", InstanceID: "vets-1",
+		Labels: map[string]string{"team": "pet", "version": "v1"}})
+	server.AddServiceInstance(&v1alpha1.ServiceInstance{ServiceName: "vets", InstanceID: "vets-2",
+		Labels: map[string]string{"team": "pet", "version": "v2"}})
+	server.AddServiceInstance(&v1alpha1.ServiceInstance{ServiceName: "order", InstanceID: "order-1",
+		Labels: map[string]string{"team": "shop"}})
+	client := meshclient.New(server.URL())
+	kind, err := resource.NewObjectCreator().NewFromKind(resource.VersionKind{
+		APIVersion: resource.DefaultAPIVersion,
+		Kind:       resource.KindServiceInstance,
+	})
+	if err != nil {
+		t.Fatalf("create object failed: %v", err)
+	}
+	deleteFlags := &flags.Delete{AdminGlobal: &flags.AdminGlobal{Timeout: time.Second}}
+
+	tests := []struct {
+		selector string
+		expected []string
+	}{
+		{"", []string{"order/order-1", "vets/vets-1", "vets/vets-2"}},
+		{"team=pet", []string{"vets/vets-1", "vets/vets-2"}},
+		{"team=pet,version!=v1", []string{"vets/vets-2"}},
+		{"team in (shop,bank)", []string{"order/order-1"}},
+		{"!team", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			sel, err := util.ParseSelector(tt.selector)
+			if err != nil {
+				t.Fatalf("parse selector failed: %v", err)
+			}
+
+			server.ResetRequests()
+			objects, err := selectObjects(kind, sel, client, deleteFlags)
+			if err != nil {
+				t.Fatalf("select objects failed: %v", err)
+			}
+
+			names := map[string]bool{}
+			for _, object := range objects {
+				names[object.Name()] = true
+			}
+			if len(names) != len(tt.expected) {
+				t.Fatalf("expect %v but got %v", tt.expected, names)
+			}
+			for _, name := range tt.expected {
+				if !names[name] {
+					t.Errorf("expect %s to be selected but got %v", name, names)
+				}
+			}
+
+			for _, request := range server.Requests() {
+				if request.Method != http.MethodGet {
+					t.Errorf("expect only GET requests but got %s %s", request.Method, request.Path)
+				}
+			}
+		})
+	}
+
+	server.AddTenant(&v1alpha1.Tenant{Name: "pet"})
+	tenants, err := resource.NewObjectCreator().NewFromKind(resource.VersionKind{
+		APIVersion: resource.DefaultAPIVersion,
+		Kind:       resource.KindTenant,
+	})
+	if err != nil {
+		t.Fatalf("create object failed: %v", err)
+	}
+	for _, selector := range []string{"team=pet", "team!=pet", "!team"} {
+		sel, _ := util.ParseSelector(selector)
+		server.ResetRequests()
+		if _, err := selectObjects(tenants, sel, client, deleteFlags); err == nil {
+			t.Errorf("expect error for selector %q on tenants without labels", selector)
+		}
+		if requests := server.Requests(); len(requests) != 0 {
+			t.Errorf("expect no requests for rejected selector %q but got %v", selector, requests)
+		}
+	}
+
+	sel, _ := util.ParseSelector("team=shop")
+	named := resource.ToServiceInstance(&v1alpha1.ServiceInstance{ServiceName: "vets", InstanceID: "vets-1"})
+	objects, err := selectObjects(named, sel, client, deleteFlags)
+	if err != nil || len(objects) != 0 {
+		t.Errorf("expect named object not matching the selector to be skipped but got %v, %v", objects, err)
+	}
+}
@@ -67,6 +67,8 @@ func WrapDeleterByMeshObject(object resource.MeshObject,
 		return &observabilityTracingsDeleter{object: object.(*resource.ObservabilityTracings), baseDeleter: baseDeleter{client: client, timeout: timeout}}
 	case resource.KindIngress:
 		return &ingressDeleter{object: object.(*resource.Ingress), baseDeleter: baseDeleter{client: client, timeout: timeout}}
+	case resource.KindServiceInstance:
+		return &serviceInstanceDeleter{object: object.(*resource.ServiceInstance), baseDeleter: baseDeleter{client: client, timeout: timeout}}
 	default:
 		common.ExitWithErrorf("BUG: unsupported kind: %s", object.Kind())
 	}
@@ -215,3 +217,25 @@ func (i *ingressDeleter) Delete() error {
 
 	return err
 }
+
+type serviceInstanceDeleter struct {
+	baseDeleter
+	object *resource.ServiceInstance
+}
+
+func (s *serviceInstanceDeleter) Delete() error {
+	serviceName, instanceID := resource.ParseServiceInstanceName(s.object.Name())
+	if instanceID == "" {
+		return errors.Errorf("service instance name %s must be in form of <service name>/<instance id>", s.object.Name())
+	}
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), s.timeout)
+	defer cancelFunc()
+
+	err := s.client.V1Alpha1().ServiceInstance().Delete(ctx, serviceName, instanceID)
+	if meshclient.IsNotFoundError(err) {
+		return errors.Wrapf(err, "delete serviceInstance %s", s.object.Name())
+	}
+
+	return err
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package delete
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+func TestDeleter(t *testing.T) {
+	tests := []struct {
+		object resource.MeshObject
+		path   string
+	}{
+		// The resources attached to the service are deleted before the service.
+		{resource.ToCanary("vets", &v1alpha1.Canary{}), "/apis/v1/mesh/services/vets/canary"},
+		{resource.ToLoadBalance("vets", &v1alpha1.LoadBalance{}), "/apis/v1/mesh/services/vets/loadbalance"},
+		{resource.ToResilience("vets", &v1alpha1.Resilience{}), "/apis/v1/mesh/services/vets/resilience"},
+		{resource.ToObservabilityTracings("vets", &v1alpha1.ObservabilityTracings{}), "/apis/v1/mesh/services/vets/tracings"},
+		{resource.ToObservabilityMetrics("vets", &v1alpha1.ObservabilityMetrics{}), "/apis/v1/mesh/services/vets/metrics"},
+		{resource.ToObservabilityOutputServer("vets", &v1alpha1.ObservabilityOutputServer{}), "/apis/v1/mesh/services/vets/outputserver"},
+		{resource.ToService(&v1alpha1.Service{Name: "vets"}), "/apis/v1/mesh/services/vets"},
+		{resource.ToTenant(&v1alpha1.Tenant{Name: "pet"}), "/apis/v1/mesh/tenants/pet"},
+		{resource.ToIngress(&v1alpha1.Ingress{Name: "pet-ingress"}), "/apis/v1/mesh/ingresses/pet-ingress"},
+		{resource.ToServiceInstance(&v1alpha1.ServiceInstance{ServiceName: "vets", InstanceID: "vets-1"}),
+			"/apis/v1/mesh/serviceinstances/vets/vets-1"},
+	}
+
+	server := fake.NewServer()
+	defer server.Close()
+	server.AddTenant(&v1alpha1.Tenant{Name: "pet"})
+	server.AddService(&v1alpha1.Service{
+		Name:        "vets",
+		Canary:      &v1alpha1.Canary{},
+		LoadBalance: &v1alpha1.LoadBalance{Policy: "random"},
+		Resilience:  &v1alpha1.Resilience{},
+		Observability: &v1alpha1.Observability{
+			Tracings:     &v1alpha1.ObservabilityTracings{},
+			Metrics:      &v1alpha1.ObservabilityMetrics{},
+			OutputServer: &v1alpha1.ObservabilityOutputServer{},
+		},
+	})
+	server.AddIngress(&v1alpha1.Ingress{Name: "pet-ingress"})
+	server.AddServiceInstance(&v1alpha1.ServiceInstance{ServiceName: "vets", InstanceID: "vets-1"})
+	client := meshclient.New(server.URL())
+
+	for _, tt := range tests {
+		t.Run(tt.object.Kind(), func(t *testing.T) {
+			server.ResetRequests()
+			deleter := WrapDeleterByMeshObject(tt.object, client, time.Second)
+			if err := deleter.Delete(); err != nil {
+				t.Fatalf("delete %s %s failed: %v", tt.object.Kind(), tt.object.Name(), err)
+			}
+			requests := server.Requests()
+			if len(requests) != 1 || requests[0].Method != http.MethodDelete || requests[0].Path != tt.path {
+				t.Errorf("expect request DELETE %s but got %v", tt.path, requests)
+			}
+
+			_, err := client.V1Alpha1().Resource(tt.object.Kind()).Get(context.Background(), tt.object.Name())
+			if !meshclient.IsNotFoundError(err) {
+				t.Errorf("expect %s deleted but got: %v", tt.object.Kind(), err)
+			}
+
+			if err := deleter.Delete(); !meshclient.IsNotFoundError(err) {
+				t.Errorf("expect NotFoundError deleting again but got: %v", err)
+			}
+		})
+	}
+
+	instance := &resource.ServiceInstance{MeshResource: resource.NewServiceInstanceResource(resource.DefaultAPIVersion, "vets")}
+	if err := WrapDeleterByMeshObject(instance, client, time.Second).Delete(); err == nil {
+		t.Errorf("expect error deleting service instance without instance id")
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package delete
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/get"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+
+	"github.com/pkg/errors"
+)
+
+var _ Deleter = &dryRunDeleter{}
+
+// dryRunDeleter reports what deleting the object would do without issuing
+// any DELETE request to the control plane. In the server mode it checks
+// whether the object exists in the control plane.
+type dryRunDeleter struct {
+	baseDeleter
+	object resource.MeshObject
+	mode   string
+}
+
+func newDryRunDeleter(object resource.MeshObject, client meshclient.MeshClient,
+	timeout time.Duration, mode string) Deleter {
+	return &dryRunDeleter{
+		baseDeleter: baseDeleter{client: client, timeout: timeout},
+		object:      object,
+		mode:        mode,
+	}
+}
+
+func (d *dryRunDeleter) Delete() error {
+	if d.object.Name() == "" {
+		return errors.Errorf("name of %s is required", d.object.Kind())
+	}
+
+	if d.mode == flags.DryRunServer {
+		existed, err := get.Exists(d.object, d.client, d.timeout)
+		if err != nil {
+			return errors.Wrapf(err, "resolve %s %s", d.object.Kind(), d.object.Name())
+		}
+		if !existed {
+			return errors.Wrapf(meshclient.NotFoundError, "delete %s %s", d.object.Kind(), d.object.Name())
+		}
+	}
+
+	fmt.Printf("%s/%s would be deleted (dry run: %s)\n", d.object.Kind(), d.object.Name(), d.mode)
+	return nil
+}
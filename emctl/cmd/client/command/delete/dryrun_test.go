@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package delete
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+func TestDryRunDeleter(t *testing.T) {
+	for _, mode := range []string{flags.DryRunClient, flags.DryRunServer} {
+		t.Run(mode, func(t *testing.T) {
+			server := fake.NewServer()
+			defer server.Close()
+			server.AddTenant(&v1alpha1.Tenant{Name: "pet"})
+			server.AddService(&v1alpha1.Service{Name: "vets", RegisterTenant: "pet", Canary: &v1alpha1.Canary{}})
+			client := meshclient.New(server.URL())
+
+			for _, object := range []resource.MeshObject{
+				resource.ToTenant(&v1alpha1.Tenant{Name: "pet"}),
+				resource.ToService(&v1alpha1.Service{Name: "vets"}),
+				resource.ToCanary("vets", &v1alpha1.Canary{}),
+			} {
+				if err := newDryRunDeleter(object, client, time.Second, mode).Delete(); err != nil {
+					t.Errorf("dry run deleting %s/%s failed: %v", object.Kind(), object.Name(), err)
+				}
+			}
+
+			err := newDryRunDeleter(resource.ToIngress(&v1alpha1.Ingress{Name: "pet-ingress"}), client, time.Second, mode).Delete()
+			if mode == flags.DryRunServer && !meshclient.IsNotFoundError(err) {
+				t.Errorf("expect NotFoundError for the missing ingress but got %v", err)
+			}
+			if mode == flags.DryRunClient && err != nil {
+				t.Errorf("expect the ingress not resolved in client mode but got %v", err)
+			}
+
+			requests := server.Requests()
+			if mode == flags.DryRunClient && len(requests) != 0 {
+				t.Errorf("expect no requests in client mode but got %v", requests)
+			}
+			for _, r := range requests {
+				if r.Method != http.MethodGet {
+					t.Errorf("expect no mutating requests but got %s %s", r.Method, r.Path)
+				}
+			}
+			if _, err := client.V1Alpha1().Service().Get(context.Background(), "vets"); err != nil {
+				t.Errorf("expect service vets kept but got %v", err)
+			}
+		})
+	}
+}
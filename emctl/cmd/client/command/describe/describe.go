@@ -0,0 +1,164 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package describe
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/spf13/cobra"
+)
+
+type (
+	// describeFunc fetches the resource with its related ones and writes
+	// the description.
+	describeFunc func(client meshclient.MeshClient, timeout time.Duration, name string, w io.Writer) error
+
+	// prefixWriter writes the description as indented key value pairs,
+	// the values of the same block are aligned.
+	prefixWriter struct {
+		out      *tabwriter.Writer
+		warnings []string
+	}
+)
+
+var describeFuncs = map[string]describeFunc{
+	resource.KindService: describeService,
+	resource.KindTenant:  describeTenant,
+}
+
+// Run is the entrypoint of the emctl describe sub command
+func Run(cmd *cobra.Command, flags *flags.Describe) {
+	cmdArgs := cmd.Flags().Args()
+	if len(cmdArgs) != 2 {
+		common.ExitWithErrorf("invalid command args: support <resource kind> <resource name>")
+	}
+
+	kinds := []string{}
+	var describe describeFunc
+	for kind, fn := range describeFuncs {
+		kinds = append(kinds, kind)
+		if strings.EqualFold(kind, cmdArgs[0]) {
+			describe = fn
+		}
+	}
+	if describe == nil {
+		sort.Strings(kinds)
+		common.ExitWithErrorf("unsupported kind %s (support %s)", cmdArgs[0], strings.Join(kinds, ", "))
+	}
+
+	client := meshclient.New(flags.Server, flags.ClientOptions()...)
+	err := describe(client, flags.Timeout, cmdArgs[1], os.Stdout)
+	if err != nil {
+		common.ExitWithErrorf("describe %s %s failed: %v", cmdArgs[0], cmdArgs[1], err)
+	}
+}
+
+func newPrefixWriter(w io.Writer) *prefixWriter {
+	return &prefixWriter{out: tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)}
+}
+
+// Write writes a line indented by the level, a tab in format separates the
+// key and the value.
+func (p *prefixWriter) Write(level int, format string, a ...interface{}) {
+	fmt.Fprintf(p.out, strings.Repeat("  ", level)+format+"\n", a...)
+}
+
+// Warnf records a warning, which is written at the end of the description.
+func (p *prefixWriter) Warnf(format string, a ...interface{}) {
+	p.warnings = append(p.warnings, fmt.Sprintf(format, a...))
+}
+
+// Flush writes the warnings and flushes the aligned lines.
+func (p *prefixWriter) Flush() error {
+	if len(p.warnings) != 0 {
+		p.Write(0, "Warnings:")
+		for _, warning := range p.warnings {
+			p.Write(1, "- %s", warning)
+		}
+	}
+	return p.out.Flush()
+}
+
+func enabled(b bool) string {
+	if b {
+		return "enabled"
+	}
+	return "disabled"
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return "<none>"
+	}
+	pairs := make([]string, 0, len(labels))
+	for _, k := range sortedKeys(labels) {
+		pairs = append(pairs, k+"="+labels[k])
+	}
+	return strings.Join(pairs, ",")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatStringMatch(m *v1alpha1.StringMatch) string {
+	switch {
+	case m == nil:
+		return "<any>"
+	case m.Exact != "":
+		return "exact:" + m.Exact
+	case m.Prefix != "":
+		return "prefix:" + m.Prefix
+	case m.Regex != "":
+		return "regex:" + m.Regex
+	default:
+		return "<any>"
+	}
+}
+
+func formatURLRule(rule *v1alpha1.URLRule) string {
+	methods := "*"
+	if len(rule.Methods) != 0 {
+		methods = strings.Join(rule.Methods, ",")
+	}
+	return methods + " " + formatStringMatch(rule.Url)
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package describe
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+)
+
+func newFakeServer() *fake.Server {
+	server := fake.NewServer()
+	server.AddTenant(&v1alpha1.Tenant{Name: "pet", Services: []string{"vets", "owners"}})
+	server.AddService(&v1alpha1.Service{
+		Name:           "vets",
+		RegisterTenant: "pet",
+		Sidecar: &v1alpha1.Sidecar{
+			DiscoveryType:   "eureka",
+			Address:         "127.0.0.1",
+			IngressPort:     13001,
+			IngressProtocol: "http",
+			EgressPort:      13001,
+			EgressProtocol:  "http",
+		},
+		LoadBalance: &v1alpha1.LoadBalance{Policy: "headerHash"},
+		Resilience: &v1alpha1.Resilience{
+			CircuitBreaker: &v1alpha1.CircuitBreaker{
+				Policies: []*v1alpha1.CircuitBreakerPolicy{{
+					Name:                 "default",
+					SlidingWindowType:    "COUNT_BASED",
+					FailureRateThreshold: 50,
+				}},
+				DefaultPolicyRef: "default",
+				Urls: []*v1alpha1.URLRule{
+					{Methods: []string{"GET"}, Url: &v1alpha1.StringMatch{Prefix: "/vets"}},
+					{Url: &v1alpha1.StringMatch{Exact: "/visits"}, PolicyRef: "missing"},
+				},
+			},
+		},
+		Canary: &v1alpha1.Canary{
+			CanaryRules: []*v1alpha1.CanaryRule{{
+				ServiceInstanceLabels: map[string]string{"version": "canary"},
+				Headers:               map[string]*v1alpha1.StringMatch{"X-Canary": {Exact: "true"}},
+			}},
+		},
+		Observability: &v1alpha1.Observability{
+			Tracings: &v1alpha1.ObservabilityTracings{Enabled: true, SampleByQPS: 10},
+		},
+	})
+	server.AddService(&v1alpha1.Service{Name: "visits", RegisterTenant: "pet"})
+	server.AddServiceInstance(&v1alpha1.ServiceInstance{
+		ServiceName: "vets",
+		InstanceID:  "vets-1",
+		Ip:          "10.0.0.1",
+		Port:        13001,
+		Status:      "UP",
+		Labels:      map[string]string{"version": "stable"},
+	})
+	return server
+}
+
+// normalize ignores the alignment of the description
+func normalize(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func TestDescribeService(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	client := meshclient.New(server.URL())
+
+	buff := &bytes.Buffer{}
+	err := describeService(client, time.Second, "vets", buff)
+	if err != nil {
+		t.Fatalf("describe service failed: %v", err)
+	}
+	output := buff.String()
+
+	for _, expected := range []string{
+		"Tenant:          pet",
+		"Ingress:         http :13001",
+		"default:  COUNT_BASED window 0, failure rate 50%",
+		"GET prefix:/vets  -> default",
+		"Headers:            X-Canary=exact:true",
+		"Matched Instances:  0",
+		"Tracings:       enabled, sample by QPS 10",
+		"vets-1  10.0.0.1  13001  UP",
+		"- sidecar ingress and egress listen on the same port 13001",
+		"- load balance policy headerHash has no headerHashKey",
+		"- circuit breaker policy missing referenced by URL * exact:/visits not found",
+		"- canary rule 1 matches no registered instance with labels version=canary",
+		"- tracings is enabled but the output server is not, tracing data is not reported",
+	} {
+		if !strings.Contains(normalize(output), normalize(expected)) {
+			t.Errorf("expect %q in description:\n%s", expected, output)
+		}
+	}
+
+	if err := describeService(client, time.Second, "missing", buff); !meshclient.IsNotFoundError(err) {
+		t.Errorf("expect NotFoundError describing missing service but got: %v", err)
+	}
+}
+
+func TestDescribeTenant(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	client := meshclient.New(server.URL())
+
+	buff := &bytes.Buffer{}
+	err := describeTenant(client, time.Second, "pet", buff)
+	if err != nil {
+		t.Fatalf("describe tenant failed: %v", err)
+	}
+	output := buff.String()
+
+	for _, expected := range []string{
+		"vets    pet",
+		"owners  <not found>",
+		"- service owners not found",
+		"- service visits registers to the tenant but isn't listed in its services",
+	} {
+		if !strings.Contains(normalize(output), normalize(expected)) {
+			t.Errorf("expect %q in description:\n%s", expected, output)
+		}
+	}
+}
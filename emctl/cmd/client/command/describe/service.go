@@ -0,0 +1,380 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package describe
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+)
+
+// instanceStatusUp is the status of the instance serving requests
+const instanceStatusUp = "UP"
+
+// describeService describes the service with the resources attached to it,
+// its tenant and its registered instances.
+func describeService(client meshclient.MeshClient, timeout time.Duration, name string, w io.Writer) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+	defer cancelFunc()
+
+	service, err := client.V1Alpha1().Service().Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	spec := service.ToV1Alpha1()
+
+	pw := newPrefixWriter(w)
+
+	var instances []*v1alpha1.ServiceInstance
+	allInstances, err := client.V1Alpha1().ServiceInstance().List(ctx)
+	if err != nil {
+		pw.Warnf("list service instances failed: %v", err)
+	}
+	for _, instance := range allInstances {
+		if instance.Spec.ServiceName == name {
+			instances = append(instances, instance.Spec)
+		}
+	}
+
+	pw.Write(0, "Name:\t%s", name)
+	pw.Write(0, "Kind:\t%s", resource.KindService)
+	pw.Write(0, "Tenant:\t%s", orNone(spec.RegisterTenant))
+	checkTenant(ctx, pw, client, spec)
+
+	describeSidecar(pw, spec.Sidecar)
+	describeLoadBalance(pw, spec.LoadBalance)
+	describeResilience(pw, spec.Resilience)
+	describeCanary(pw, spec.Canary, instances)
+	describeObservability(pw, spec.Observability)
+	describeInstances(pw, instances)
+
+	return pw.Flush()
+}
+
+func checkTenant(ctx context.Context, pw *prefixWriter, client meshclient.MeshClient, service *v1alpha1.Service) {
+	if service.RegisterTenant == "" {
+		pw.Warnf("service registers to no tenant")
+		return
+	}
+
+	tenant, err := client.V1Alpha1().Tenant().Get(ctx, service.RegisterTenant)
+	switch {
+	case meshclient.IsNotFoundError(err):
+		pw.Warnf("tenant %s not found", service.RegisterTenant)
+		return
+	case err != nil:
+		pw.Warnf("get tenant %s failed: %v", service.RegisterTenant, err)
+		return
+	}
+
+	for _, s := range tenant.Spec.Services {
+		if s == service.Name {
+			return
+		}
+	}
+	pw.Warnf("tenant %s doesn't list the service in its services", service.RegisterTenant)
+}
+
+func describeSidecar(pw *prefixWriter, sidecar *v1alpha1.Sidecar) {
+	if sidecar == nil {
+		pw.Write(0, "Sidecar:\t<none>")
+		pw.Warnf("sidecar is not configured")
+		return
+	}
+
+	pw.Write(0, "Sidecar:")
+	pw.Write(1, "Discovery Type:\t%s", orNone(sidecar.DiscoveryType))
+	pw.Write(1, "Address:\t%s", orNone(sidecar.Address))
+	pw.Write(1, "Ingress:\t%s :%d", orNone(sidecar.IngressProtocol), sidecar.IngressPort)
+	pw.Write(1, "Egress:\t%s :%d", orNone(sidecar.EgressProtocol), sidecar.EgressPort)
+
+	if sidecar.IngressPort != 0 && sidecar.IngressPort == sidecar.EgressPort {
+		pw.Warnf("sidecar ingress and egress listen on the same port %d", sidecar.IngressPort)
+	}
+}
+
+func describeLoadBalance(pw *prefixWriter, lb *v1alpha1.LoadBalance) {
+	if lb == nil {
+		pw.Write(0, "Load Balance:\t<none>")
+		return
+	}
+
+	pw.Write(0, "Load Balance:")
+	pw.Write(1, "Policy:\t%s", orNone(lb.Policy))
+	if lb.HeaderHashKey != "" {
+		pw.Write(1, "Header Hash Key:\t%s", lb.HeaderHashKey)
+	}
+
+	switch {
+	case lb.Policy == resource.LoadBalanceHeaderHashPolicy && lb.HeaderHashKey == "":
+		pw.Warnf("load balance policy %s has no headerHashKey", lb.Policy)
+	case lb.Policy != resource.LoadBalanceHeaderHashPolicy && lb.HeaderHashKey != "":
+		pw.Warnf("load balance headerHashKey %s is ignored by policy %s", lb.HeaderHashKey, orNone(lb.Policy))
+	}
+}
+
+func describeResilience(pw *prefixWriter, r *v1alpha1.Resilience) {
+	if r == nil {
+		pw.Write(0, "Resilience:\t<none>")
+		return
+	}
+
+	pw.Write(0, "Resilience:")
+
+	if cb := r.CircuitBreaker; cb != nil {
+		pw.Write(1, "Circuit Breaker:")
+		policies := map[string]string{}
+		for _, p := range cb.Policies {
+			desc := fmt.Sprintf("%s window %d, failure rate %d%%, slow call rate %d%% over %s, open %s",
+				orNone(p.SlidingWindowType), p.SlidingWindowSize, p.FailureRateThreshold,
+				p.SlowCallRateThreshold, orNone(p.SlowCallDurationThreshold), orNone(p.WaitDurationInOpenState))
+			if len(p.FailureStatusCodes) != 0 {
+				desc += fmt.Sprintf(", failure status codes %v", p.FailureStatusCodes)
+			}
+			policies[p.Name] = desc
+		}
+		describePolicies(pw, "circuit breaker", cb.Policies == nil, policies, cb.DefaultPolicyRef, cb.Urls)
+	}
+
+	if rl := r.RateLimiter; rl != nil {
+		pw.Write(1, "Rate Limiter:")
+		policies := map[string]string{}
+		for _, p := range rl.Policies {
+			policies[p.Name] = fmt.Sprintf("%d requests per %s, timeout %s",
+				p.LimitForPeriod, orNone(p.LimitRefreshPeriod), orNone(p.TimeoutDuration))
+		}
+		describePolicies(pw, "rate limiter", rl.Policies == nil, policies, rl.DefaultPolicyRef, rl.Urls)
+	}
+
+	if rt := r.Retryer; rt != nil {
+		pw.Write(1, "Retryer:")
+		policies := map[string]string{}
+		for _, p := range rt.Policies {
+			policies[p.Name] = fmt.Sprintf("max attempts %d, wait %s, back off %s",
+				p.MaxAttempts, orNone(p.WaitDuration), orNone(p.BackOffPolicy))
+		}
+		describePolicies(pw, "retryer", rt.Policies == nil, policies, rt.DefaultPolicyRef, rt.Urls)
+	}
+
+	if tl := r.TimeLimiter; tl != nil {
+		pw.Write(1, "Time Limiter:")
+		pw.Write(2, "Default Timeout:\t%s", orNone(tl.DefaultTimeoutDuration))
+		if len(tl.Urls) != 0 {
+			pw.Write(2, "URLs:")
+			for _, u := range tl.Urls {
+				pw.Write(3, "%s", formatURLRule(u))
+			}
+		}
+	}
+}
+
+// describePolicies writes the policies and the policy applied to each URL,
+// the references to missing policies are warned.
+func describePolicies(pw *prefixWriter, name string, noPolicy bool, policies map[string]string,
+	defaultPolicyRef string, urls []*v1alpha1.URLRule) {
+	pw.Write(2, "Policies:")
+	if noPolicy {
+		pw.Write(3, "<none>")
+	}
+	for _, policyName := range sortedKeys(policies) {
+		pw.Write(3, "%s:\t%s", policyName, policies[policyName])
+	}
+
+	pw.Write(2, "Default Policy:\t%s", orNone(defaultPolicyRef))
+	if _, exists := policies[defaultPolicyRef]; defaultPolicyRef != "" && !exists {
+		pw.Warnf("%s default policy %s not found", name, defaultPolicyRef)
+	}
+
+	pw.Write(2, "URLs:")
+	if len(urls) == 0 {
+		pw.Write(3, "<none>")
+		pw.Warnf("%s has no URL rules, it applies to no request", name)
+	}
+	for _, u := range urls {
+		policyRef := u.PolicyRef
+		if policyRef == "" {
+			policyRef = defaultPolicyRef
+		}
+		pw.Write(3, "%s\t-> %s", formatURLRule(u), orNone(policyRef))
+
+		if _, exists := policies[policyRef]; policyRef != "" && !exists && policyRef != defaultPolicyRef {
+			pw.Warnf("%s policy %s referenced by URL %s not found", name, policyRef, formatURLRule(u))
+		}
+		if policyRef == "" {
+			pw.Warnf("%s URL %s references no policy", name, formatURLRule(u))
+		}
+	}
+}
+
+func describeCanary(pw *prefixWriter, canary *v1alpha1.Canary, instances []*v1alpha1.ServiceInstance) {
+	if canary == nil || len(canary.CanaryRules) == 0 {
+		pw.Write(0, "Canary:\t<none>")
+		return
+	}
+
+	pw.Write(0, "Canary:")
+	for i, rule := range canary.CanaryRules {
+		pw.Write(1, "Rule %d:", i+1)
+		pw.Write(2, "Instance Labels:\t%s", formatLabels(rule.ServiceInstanceLabels))
+
+		headers := map[string]string{}
+		for k, v := range rule.Headers {
+			headers[k] = formatStringMatch(v)
+		}
+		pw.Write(2, "Headers:\t%s", formatLabels(headers))
+
+		urls := []string{}
+		for _, u := range rule.Urls {
+			urls = append(urls, formatURLRule(u))
+		}
+		pw.Write(2, "URLs:\t%s", orNone(strings.Join(urls, "; ")))
+
+		matched := 0
+		for _, instance := range instances {
+			if matchLabels(instance.Labels, rule.ServiceInstanceLabels) {
+				matched++
+			}
+		}
+		pw.Write(2, "Matched Instances:\t%d", matched)
+
+		switch {
+		case len(rule.ServiceInstanceLabels) == 0:
+			pw.Warnf("canary rule %d has no service instance labels", i+1)
+		case matched == 0 && len(instances) != 0:
+			pw.Warnf("canary rule %d matches no registered instance with labels %s",
+				i+1, formatLabels(rule.ServiceInstanceLabels))
+		}
+	}
+}
+
+func matchLabels(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func describeObservability(pw *prefixWriter, o *v1alpha1.Observability) {
+	if o == nil {
+		pw.Write(0, "Observability:\t<none>")
+		return
+	}
+
+	pw.Write(0, "Observability:")
+
+	outputEnabled := o.OutputServer != nil && o.OutputServer.Enabled
+	if server := o.OutputServer; server != nil {
+		pw.Write(1, "Output Server:\t%s, bootstrap server %s, timeout %dms",
+			enabled(server.Enabled), orNone(server.BootstrapServer), server.Timeout)
+		if server.Enabled && server.BootstrapServer == "" {
+			pw.Warnf("output server is enabled without bootstrap server")
+		}
+	} else {
+		pw.Write(1, "Output Server:\t<none>")
+	}
+
+	if t := o.Tracings; t != nil {
+		pw.Write(1, "Tracings:\t%s, sample by QPS %d", enabled(t.Enabled), t.SampleByQPS)
+		if out := t.Output; out != nil {
+			pw.Write(2, "Output:\t%s, topic %s, report thread %d", enabled(out.Enabled), orNone(out.Topic), out.ReportThread)
+			if t.Enabled && out.Enabled && out.Topic == "" {
+				pw.Warnf("tracings output is enabled without topic")
+			}
+		}
+		details := []struct {
+			name   string
+			detail *v1alpha1.ObservabilityTracingsDetail
+		}{
+			{"Request", t.Request}, {"Remote Invoke", t.RemoteInvoke}, {"Kafka", t.Kafka},
+			{"JDBC", t.Jdbc}, {"Redis", t.Redis}, {"Rabbit", t.Rabbit},
+		}
+		for _, d := range details {
+			if d.detail != nil {
+				pw.Write(2, "%s:\t%s", d.name, enabled(d.detail.Enabled))
+			}
+		}
+
+		if t.Enabled && t.SampleByQPS <= 0 {
+			pw.Warnf("tracings is enabled but sampleByQPS is %d, no request is sampled", t.SampleByQPS)
+		}
+		if t.Enabled && !outputEnabled {
+			pw.Warnf("tracings is enabled but the output server is not, tracing data is not reported")
+		}
+	} else {
+		pw.Write(1, "Tracings:\t<none>")
+	}
+
+	if m := o.Metrics; m != nil {
+		pw.Write(1, "Metrics:\t%s", enabled(m.Enabled))
+		details := []struct {
+			name   string
+			detail *v1alpha1.ObservabilityMetricsDetail
+		}{
+			{"Access", m.Access}, {"Request", m.Request}, {"JDBC Statement", m.JdbcStatement},
+			{"JDBC Connection", m.JdbcConnection}, {"Rabbit", m.Rabbit}, {"Kafka", m.Kafka},
+			{"Redis", m.Redis}, {"JVM GC", m.JvmGc}, {"JVM Memory", m.JvmMemory}, {"MD5 Dictionary", m.Md5Dictionary},
+		}
+		for _, d := range details {
+			if d.detail == nil {
+				continue
+			}
+			pw.Write(2, "%s:\t%s, interval %ds, topic %s", d.name, enabled(d.detail.Enabled), d.detail.Interval, orNone(d.detail.Topic))
+			if m.Enabled && d.detail.Enabled && d.detail.Topic == "" {
+				pw.Warnf("metrics %s is enabled without topic", d.name)
+			}
+		}
+
+		if m.Enabled && !outputEnabled {
+			pw.Warnf("metrics is enabled but the output server is not, metrics data is not reported")
+		}
+	} else {
+		pw.Write(1, "Metrics:\t<none>")
+	}
+}
+
+func describeInstances(pw *prefixWriter, instances []*v1alpha1.ServiceInstance) {
+	if len(instances) == 0 {
+		pw.Write(0, "Instances:\t<none>")
+		pw.Warnf("no instance is registered")
+		return
+	}
+
+	pw.Write(0, "Instances:")
+	pw.Write(1, "ID\tIP\tPort\tStatus\tLabels\tRegistry Time")
+	up := 0
+	for _, instance := range instances {
+		pw.Write(1, "%s\t%s\t%d\t%s\t%s\t%s", instance.InstanceID, instance.Ip, instance.Port,
+			orNone(instance.Status), formatLabels(instance.Labels), orNone(instance.RegistryTime))
+		if instance.Status == instanceStatusUp {
+			up++
+		}
+	}
+
+	if up == 0 {
+		pw.Warnf("none of the %d instances is %s", len(instances), instanceStatusUp)
+	}
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package describe
+
+import (
+	"context"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+// describeTenant describes the tenant with the services in it.
+func describeTenant(client meshclient.MeshClient, timeout time.Duration, name string, w io.Writer) error {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), timeout)
+	defer cancelFunc()
+
+	tenant, err := client.V1Alpha1().Tenant().Get(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	pw := newPrefixWriter(w)
+
+	services := map[string]*resource.Service{}
+	allServices, err := client.V1Alpha1().Service().List(ctx)
+	if err != nil {
+		pw.Warnf("list services failed: %v", err)
+	}
+	for _, service := range allServices {
+		services[service.Name()] = service
+	}
+
+	pw.Write(0, "Name:\t%s", name)
+	pw.Write(0, "Kind:\t%s", resource.KindTenant)
+	pw.Write(0, "Description:\t%s", orNone(tenant.Spec.Description))
+
+	listed := map[string]bool{}
+	if len(tenant.Spec.Services) == 0 {
+		pw.Write(0, "Services:\t<none>")
+	} else {
+		pw.Write(0, "Services:")
+		pw.Write(1, "Name\tRegister Tenant")
+	}
+	for _, serviceName := range tenant.Spec.Services {
+		listed[serviceName] = true
+
+		service, exists := services[serviceName]
+		if !exists {
+			pw.Write(1, "%s\t<not found>", serviceName)
+			if err == nil {
+				pw.Warnf("service %s not found", serviceName)
+			}
+			continue
+		}
+
+		pw.Write(1, "%s\t%s", serviceName, orNone(service.Spec.RegisterTenant))
+		if service.Spec.RegisterTenant != name {
+			pw.Warnf("service %s registers to tenant %s", serviceName, orNone(service.Spec.RegisterTenant))
+		}
+	}
+
+	unlisted := []string{}
+	for serviceName, service := range services {
+		if service.Spec.RegisterTenant == name && !listed[serviceName] {
+			unlisted = append(unlisted, serviceName)
+		}
+	}
+	sort.Strings(unlisted)
+	for _, serviceName := range unlisted {
+		pw.Warnf("service %s registers to the tenant but isn't listed in its services", serviceName)
+	}
+
+	return pw.Flush()
+}
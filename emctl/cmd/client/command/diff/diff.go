@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package diff
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/get"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// Run is the entrypoint of the emctl diff sub command
+func Run(cmd *cobra.Command, flags *flags.Diff) {
+	if flags.YamlFile == "" {
+		common.ExitWithErrorf("no resource specified")
+	}
+
+	vss, err := util.NewVisitorBuilder().
+		DefaultTenant(flags.Tenant).
+		FilenameParam(&util.FilenameOptions{
+			Recursive: flags.Recursive,
+			Filenames: []string{flags.YamlFile},
+		}).
+		Do()
+
+	if err != nil {
+		common.ExitWithErrorf("build visitor failed: %v", err)
+	}
+
+	client := meshclient.New(flags.Server, flags.ClientOptions()...)
+	errs := diffVisitors(vss, client, flags, os.Stdout)
+	if len(errs) > 0 {
+		common.ExitWithErrorf("diffing resources has errors occurred")
+	}
+}
+
+// diffVisitors prints the diffs of all visited objects against the control
+// plane, and returns the errors of the visitors.
+func diffVisitors(vss []util.Visitor, client meshclient.MeshClient, flags *flags.Diff, w io.Writer) []error {
+	var errs []error
+	for _, vs := range vss {
+		err := vs.Visit(func(mo resource.MeshObject, e error) error {
+			if e != nil {
+				return errors.Wrap(e, "visit failed")
+			}
+
+			err := diffObject(mo, client, flags, w)
+			if err != nil {
+				return errors.Wrapf(err, "%s/%s diff failed", mo.Kind(), mo.Name())
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func diffObject(mo resource.MeshObject, client meshclient.MeshClient, flags *flags.Diff, w io.Writer) error {
+	resourceID := mo.Kind() + "/" + mo.Name()
+
+	local, err := util.NormalizedYAML(mo)
+	if err != nil {
+		return err
+	}
+
+	live := ""
+	objects, err := get.WrapGetterByMeshObject(mo, client, flags.Timeout).Get()
+	switch {
+	case meshclient.IsNotFoundError(err):
+	case err != nil:
+		return errors.Wrap(err, "get live object")
+	case len(objects) != 0:
+		live, err = util.NormalizedYAML(objects[0])
+		if err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case live == "":
+		fmt.Fprintf(w, "%s (new)\n", resourceID)
+		fmt.Fprint(w, util.UnifiedDiff("/dev/null", resourceID, "", local))
+	case live == local:
+		fmt.Fprintf(w, "%s (unchanged)\n", resourceID)
+	default:
+		fmt.Fprintf(w, "%s (changed)\n", resourceID)
+		fmt.Fprint(w, util.UnifiedDiff("live/"+resourceID, "local/"+resourceID, live, local))
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package diff
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+)
+
+const resources = `kind: Tenant
+apiVersion: mesh.megaease.com/v1alpha1
+metadata:
+  name: pet
+spec:
+  description: pet clinic
+---
+kind: Service
+apiVersion: mesh.megaease.com/v1alpha1
+metadata:
+  name: vets
+spec:
+  registerTenant: pet
+  sidecar:
+    discoveryType: eureka
+    address: 127.0.0.1
+    ingressPort: 13001
+    ingressProtocol: http
+    egressPort: 13002
+    egressProtocol: http
+---
+kind: Ingress
+apiVersion: mesh.megaease.com/v1alpha1
+metadata:
+  name: pet-ingress
+spec:
+  rules: []
+`
+
+func TestDiff(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diff")
+	if err != nil {
+		t.Fatalf("create temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	file := path.Join(dir, "mesh.yaml")
+	if err := ioutil.WriteFile(file, []byte(resources), 0600); err != nil {
+		t.Fatalf("write file failed: %v", err)
+	}
+
+	server := fake.NewServer()
+	defer server.Close()
+	server.AddTenant(&v1alpha1.Tenant{Name: "pet", Description: "pet clinic"})
+	server.AddService(&v1alpha1.Service{Name: "vets", RegisterTenant: "shop", Sidecar: &v1alpha1.Sidecar{
+		DiscoveryType: "eureka", Address: "127.0.0.1", IngressPort: 13001, IngressProtocol: "http",
+		EgressPort: 13002, EgressProtocol: "http",
+	}})
+
+	vss, err := util.NewVisitorBuilder().
+		FilenameParam(&util.FilenameOptions{Filenames: []string{file}}).
+		Do()
+	if err != nil {
+		t.Fatalf("build visitor failed: %v", err)
+	}
+
+	buff := &bytes.Buffer{}
+	diffFlags := &flags.Diff{AdminGlobal: &flags.AdminGlobal{Timeout: time.Second}}
+	errs := diffVisitors(vss, meshclient.New(server.URL()), diffFlags, buff)
+	if len(errs) != 0 {
+		t.Fatalf("diff failed: %v", errs)
+	}
+
+	output := buff.String()
+	for _, expected := range []string{
+		"Tenant/pet (unchanged)\n",
+		"Service/vets (changed)\n",
+		"-  registerTenant: shop\n+  registerTenant: pet\n",
+		"Ingress/pet-ingress (new)\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expect %q in the output:\n%s", expected, output)
+		}
+	}
+
+	for _, r := range server.Requests() {
+		if r.Method != http.MethodGet {
+			t.Errorf("expect no mutating requests but got %s %s", r.Method, r.Path)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package edit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	yamljsontool "github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const editHeader = `# Please edit the object below. Lines beginning with a '#' will be ignored,
+# and an empty file will abort the edit. If an error occurs while saving this file will be
+# reopened with the relevant failures.
+#
+`
+
+// editorFunc opens the file in an editor and returns after the editor exits.
+type editorFunc func(path string) error
+
+// Run is the entrypoint of the emctl edit sub command
+func Run(cmd *cobra.Command, flags *flags.Edit) {
+	cmdArgs := cmd.Flags().Args()
+	if len(cmdArgs) != 2 {
+		common.ExitWithErrorf("invalid command args: support <resource kind> <resource name>")
+	}
+
+	kind := ""
+	for _, k := range resource.Kinds {
+		if strings.EqualFold(k, cmdArgs[0]) {
+			kind = k
+		}
+	}
+	if kind == "" {
+		common.ExitWithErrorf("unsupported kind %s (support %s)", cmdArgs[0], strings.Join(resource.Kinds, ", "))
+	}
+
+	client := meshclient.New(flags.Server, flags.ClientOptions()...)
+	err := edit(client, flags.Timeout, kind, cmdArgs[1], openEditor)
+	if err != nil {
+		common.ExitWithErrorf("edit %s %s failed: %v", kind, cmdArgs[1], err)
+	}
+}
+
+// edit fetches the object and opens it in the editor, the edited object is
+// saved to the control plane once it's valid. The editor is reopened with
+// the failures as comments until the object is saved, the edit is cancelled,
+// or the same content fails twice in a row.
+func edit(client meshclient.MeshClient, timeout time.Duration, kind, name string, editor editorFunc) error {
+	rest := client.V1Alpha1().Resource(kind)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	object, err := rest.Get(ctx, name)
+	if err != nil {
+		return errors.Wrap(err, "get")
+	}
+
+	original, err := yamljsontool.Marshal(object)
+	if err != nil {
+		return errors.Wrap(err, "marshal to yaml")
+	}
+
+	file, err := ioutil.TempFile("", "emctl-edit-*.yaml")
+	if err != nil {
+		return errors.Wrap(err, "create temporary file")
+	}
+	path := file.Name()
+	file.Close()
+
+	keepFile := false
+	defer func() {
+		if !keepFile {
+			os.Remove(path)
+		}
+	}()
+
+	// content is the last failed attempt if there is a failure.
+	content := original
+	var failure error
+	for {
+		err = ioutil.WriteFile(path, withComments(content, failure), 0600)
+		if err != nil {
+			return errors.Wrapf(err, "write %s", path)
+		}
+
+		err = editor(path)
+		if err != nil {
+			return err
+		}
+
+		edited, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "read %s", path)
+		}
+		edited = stripComments(edited)
+
+		if len(bytes.TrimSpace(edited)) == 0 || bytes.Equal(edited, original) {
+			fmt.Println("Edit cancelled, no changes made.")
+			return nil
+		}
+
+		if failure != nil && bytes.Equal(edited, content) {
+			keepFile = true
+			return errors.Errorf("%v\nthe edit is kept in %s", failure, path)
+		}
+
+		failure = save(rest, timeout, kind, name, edited)
+		if failure == nil {
+			fmt.Printf("%s/%s edited\n", kind, name)
+			return nil
+		}
+
+		content = edited
+	}
+}
+
+// save validates the edited object and puts it to the control plane.
+func save(rest meshclient.ResourceInterface, timeout time.Duration, kind, name string, edited []byte) error {
+	object, err := util.DecodeYAML(edited)
+	if err != nil {
+		return err
+	}
+
+	if object.Kind() != kind || object.Name() != name {
+		return errors.Errorf("kind and name can't be changed: expect %s/%s but got %s/%s",
+			kind, name, object.Kind(), object.Name())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return errors.Wrap(rest.Patch(ctx, object), "update")
+}
+
+// withComments prefixes the content with the header, and the failure of the
+// last attempt if any.
+func withComments(content []byte, failure error) []byte {
+	buff := bytes.NewBufferString(editHeader)
+	if failure != nil {
+		buff.WriteString("# The object could not be saved:\n")
+		for _, line := range strings.Split(failure.Error(), "\n") {
+			fmt.Fprintf(buff, "# %s\n", line)
+		}
+		buff.WriteString("#\n")
+	}
+	buff.Write(content)
+	return buff.Bytes()
+}
+
+// stripComments removes the lines beginning with a '#'.
+func stripComments(content []byte) []byte {
+	buff := &bytes.Buffer{}
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		buff.WriteString(line)
+	}
+	return buff.Bytes()
+}
+
+// openEditor opens the file in the editor of EMCTL_EDITOR or EDITOR, and
+// falls back to vi, the editor may come with arguments such as "code --wait".
+func openEditor(path string) error {
+	editor := os.Getenv("EMCTL_EDITOR")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	args := strings.Fields(editor)
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return errors.Wrapf(cmd.Run(), "run editor %s", editor)
+}
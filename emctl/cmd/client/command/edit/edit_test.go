@@ -0,0 +1,190 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package edit
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+// replaceEditor returns an editor replacing old with new in the file at
+// the call of the same index, the calls beyond the replacements change
+// nothing.
+func replaceEditor(t *testing.T, calls *int, replacements ...[2]string) editorFunc {
+	return func(path string) error {
+		defer func() { *calls++ }()
+		if *calls >= len(replacements) {
+			return nil
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read %s failed: %v", path, err)
+		}
+		old, new := replacements[*calls][0], replacements[*calls][1]
+		if !strings.Contains(string(content), old) {
+			t.Fatalf("expect %q in the edited file but got:\n%s", old, content)
+		}
+		return ioutil.WriteFile(path, []byte(strings.Replace(string(content), old, new, 1)), 0600)
+	}
+}
+
+func newServer() *fake.Server {
+	server := fake.NewServer()
+	server.AddService(&v1alpha1.Service{
+		Name:           "vets",
+		RegisterTenant: "pet",
+		Sidecar: &v1alpha1.Sidecar{
+			DiscoveryType:   "eureka",
+			IngressPort:     13001,
+			IngressProtocol: "http",
+			EgressPort:      13002,
+			EgressProtocol:  "http",
+		},
+	})
+	return server
+}
+
+func registerTenant(t *testing.T, client meshclient.MeshClient) string {
+	service, err := client.V1Alpha1().Service().Get(context.Background(), "vets")
+	if err != nil {
+		t.Fatalf("get service failed: %v", err)
+	}
+	return service.Spec.RegisterTenant
+}
+
+func puts(server *fake.Server) int {
+	count := 0
+	for _, request := range server.Requests() {
+		if request.Method == http.MethodPut {
+			count++
+		}
+	}
+	return count
+}
+
+func TestEdit(t *testing.T) {
+	tests := []struct {
+		name         string
+		replacements [][2]string
+		calls        int
+		puts         int
+		tenant       string
+		err          string
+	}{
+		{"unchanged", nil, 1, 0, "pet", ""},
+		{"changed", [][2]string{{"registerTenant: pet", "registerTenant: shop"}}, 1, 1, "shop", ""},
+		{"fixed after invalid", [][2]string{
+			{"registerTenant: pet", "registerTenant: \"\""},
+			{"# The object could not be saved:\n# invalid resource:", "#"},
+		}, 2, 0, "pet", "spec.registerTenant"},
+		{"fixed after invalid", [][2]string{
+			{"registerTenant: pet", "registerTenant: \"\""},
+			{"registerTenant: \"\"", "registerTenant: shop"},
+		}, 2, 1, "shop", ""},
+		{"renamed", [][2]string{{"name: vets", "name: owners"}}, 2, 0, "pet", "kind and name can't be changed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newServer()
+			defer server.Close()
+			client := meshclient.New(server.URL())
+
+			calls := 0
+			err := edit(client, time.Second, resource.KindService, "vets", replaceEditor(t, &calls, tt.replacements...))
+			if tt.err == "" && err != nil {
+				t.Fatalf("edit failed: %v", err)
+			}
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expect error %q but got: %v", tt.err, err)
+				}
+				if !strings.Contains(err.Error(), "the edit is kept in ") {
+					t.Fatalf("expect the path of the kept edit in error: %v", err)
+				}
+				os.Remove(err.Error()[strings.LastIndex(err.Error(), " ")+1:])
+			}
+
+			if calls != tt.calls {
+				t.Errorf("expect %d calls of editor but got %d", tt.calls, calls)
+			}
+			if n := puts(server); n != tt.puts {
+				t.Errorf("expect %d puts but got %d", tt.puts, n)
+			}
+			if tenant := registerTenant(t, client); tenant != tt.tenant {
+				t.Errorf("expect register tenant %s but got %s", tt.tenant, tenant)
+			}
+		})
+	}
+}
+
+func TestEditEmptied(t *testing.T) {
+	server := newServer()
+	defer server.Close()
+	client := meshclient.New(server.URL())
+
+	err := edit(client, time.Second, resource.KindService, "vets", func(path string) error {
+		return ioutil.WriteFile(path, []byte(editHeader), 0600)
+	})
+	if err != nil {
+		t.Fatalf("edit failed: %v", err)
+	}
+	if n := puts(server); n != 0 {
+		t.Errorf("expect no puts but got %d", n)
+	}
+}
+
+func TestEditConflict(t *testing.T) {
+	server := newServer()
+	defer server.Close()
+	client := meshclient.New(server.URL())
+	server.SetStatus(http.MethodPut, "/apis/v1/mesh/services/vets", http.StatusConflict)
+
+	// The object is left as it is after the failure is shown, so it fails again.
+	calls := 0
+	err := edit(client, time.Second, resource.KindService, "vets", replaceEditor(t, &calls,
+		[2]string{"registerTenant: pet", "registerTenant: shop"},
+		[2]string{"# update: PUT Service vets: resource already exists\n#\n", ""},
+	))
+	if err == nil || !strings.Contains(err.Error(), "resource already exists") {
+		t.Fatalf("expect conflict error but got: %v", err)
+	}
+	os.Remove(err.Error()[strings.LastIndex(err.Error(), " ")+1:])
+	if calls != 2 {
+		t.Errorf("expect 2 calls of editor but got %d", calls)
+	}
+}
+
+func TestStripComments(t *testing.T) {
+	content := "# header\nkind: Service\n  # indented\nmetadata:\n  name: vets # trailing\n"
+	want := "kind: Service\nmetadata:\n  name: vets # trailing\n"
+	if got := string(stripComments([]byte(content))); got != want {
+		t.Errorf("expect %q but got %q", want, got)
+	}
+}
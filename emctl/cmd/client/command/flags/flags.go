@@ -18,11 +18,16 @@
 package flags
 
 import (
+	"os"
+	"path"
+	"strings"
 	"time"
 
 	"github.com/megaease/easemeshctl/cmd/client/command/rcfile"
 	"github.com/megaease/easemeshctl/cmd/common"
+	"github.com/megaease/easemeshctl/cmd/common/client"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
@@ -118,6 +123,20 @@ spec:
 	DefaultEaseMeshOperatorImage = "megaease/easemesh-operator:latest"
 	// DefaultImageRegistryURL is default registry url
 	DefaultImageRegistryURL = "docker.io"
+
+	// DryRunNone indicates that the command modifies the control plane as usual
+	DryRunNone = "none"
+	// DryRunClient indicates that the command only checks resources locally without contacting the control plane
+	DryRunClient = "client"
+	// DryRunServer indicates that the command resolves resources against the control plane without modifying it
+	DryRunServer = "server"
+
+	// ConflictSkip indicates that the restore keeps the existing resources
+	ConflictSkip = "skip"
+	// ConflictOverwrite indicates that the restore overwrites the existing resources
+	ConflictOverwrite = "overwrite"
+	// ConflictFail indicates that the restore stops at the first existing resource
+	ConflictFail = "fail"
 )
 
 type (
@@ -150,6 +169,7 @@ type (
 		MeshControlPlanePersistVolumeHostPath string
 		MeshControlPlanePersistVolumeCapacity string
 		MeshControlPlaneCheckHealthzMaxTime   int
+		MeshControlPlaneTLS                   bool
 
 		MeshIngressReplicas    int
 		MeshIngressServicePort int32
@@ -163,6 +183,9 @@ type (
 		EaseMeshOperatorReplicas int
 
 		SpecFile string
+
+		// Context is the name of the context in the rcfile saving the installed control plane
+		Context string
 	}
 
 	// Reset holds the option for the EaseMesh resest sub command
@@ -172,8 +195,23 @@ type (
 
 	// AdminGlobal holds the option for all the EaseMesh admin command
 	AdminGlobal struct {
+		Context string
 		Server  string
 		Timeout time.Duration
+
+		CertificateAuthority  string
+		ClientCertificate     string
+		ClientKey             string
+		InsecureSkipTLSVerify bool
+		Token                 string
+		Username              string
+		Password              string
+
+		// Tenant is the default tenant of the Services registering to no
+		// tenant, it's attached only by the commands reading resource files.
+		Tenant string
+
+		clientOptions []client.Option
 	}
 
 	// AdminFileInput holds the option for all the EaseMesh admin command
@@ -182,22 +220,104 @@ type (
 		Recursive bool
 	}
 
+	// AdminDryRun holds the dry run option for the admin command modifying resources
+	AdminDryRun struct {
+		DryRun string
+	}
+
+	// AdminSelector holds the label selector option for the admin command
+	AdminSelector struct {
+		Selector string
+	}
+
 	// Apply holds the option for the apply sub command
 	Apply struct {
 		*AdminGlobal
 		*AdminFileInput
+		*AdminDryRun
+		*AdminSelector
+
+		Prune       bool
+		All         bool
+		Yes         bool
+		Concurrency int
 	}
 
 	// Delete holds the option for the emctl delete sub command
 	Delete struct {
 		*AdminGlobal
 		*AdminFileInput
+		*AdminDryRun
+		*AdminSelector
+
+		All bool
+	}
+
+	// Diff holds the option for the emctl diff sub command
+	Diff struct {
+		*AdminGlobal
+		*AdminFileInput
+	}
+
+	// Validate holds the option for the emctl validate sub command
+	Validate struct {
+		*AdminFileInput
+		Tenant string
+	}
+
+	// Schema holds the option for the emctl schema sub command
+	Schema struct {
+		OutputFormat string
+	}
+
+	// ConfigSetContext holds the option for the emctl config set-context sub command
+	ConfigSetContext struct {
+		Server                string
+		Timeout               time.Duration
+		CertificateAuthority  string
+		ClientCertificate     string
+		ClientKey             string
+		InsecureSkipTLSVerify bool
+		Token                 string
+		Username              string
+		Password              string
+		Namespace             string
+		Tenant                string
+		Use                   bool
 	}
 
 	// Get holds the option for the emctl get sub command
 	Get struct {
 		*AdminGlobal
-		OutputFormat string
+		*AdminSelector
+		OutputFormat  string
+		SortBy        string
+		NoHeaders     bool
+		Watch         bool
+		WatchInterval time.Duration
+	}
+
+	// Describe holds the option for the emctl describe sub command
+	Describe struct {
+		*AdminGlobal
+	}
+
+	// Edit holds the option for the emctl edit sub command
+	Edit struct {
+		*AdminGlobal
+	}
+
+	// Backup holds the option for the emctl backup sub command
+	Backup struct {
+		*AdminGlobal
+		OutputFile string
+	}
+
+	// Restore holds the option for the emctl restore sub command
+	Restore struct {
+		*AdminGlobal
+		YamlFile string
+		Conflict string
 	}
 )
 
@@ -214,7 +334,7 @@ func init() {
 	}
 
 	err = rc.Unmarshal()
-	if err != nil {
+	if err != nil && !os.IsNotExist(errors.Cause(err)) {
 		common.OutputErrorf("unmarshal rcfile failed: %v", err)
 	}
 
@@ -236,6 +356,9 @@ func (i *Install) AttachCmd(cmd *cobra.Command) {
 	cmd.Flags().IntVar(&i.EgServicePeerPort, "mesh-control-plane-service-peer-port", DefaultMeshPeerPort, "Port of Easegress cluster peer")
 	cmd.Flags().IntVar(&i.EgServiceAdminPort, "mesh-control-plane-service-admin-port", DefaultMeshAdminPort, "Port of Easegress admin address")
 
+	cmd.Flags().BoolVar(&i.MeshControlPlaneTLS, "mesh-control-plane-tls", false,
+		"Whether to serve the admin API of mesh control plane with TLS, the certificates are generated and saved in a secret")
+
 	cmd.Flags().StringVar(&i.MeshControlPlaneStorageClassName, "mesh-storage-class-name", DefaultMeshControlPlaneStorageClassName, "Mesh storage class name")
 	cmd.Flags().StringVar(&i.MeshControlPlanePersistVolumeCapacity, "mesh-control-plane-pv-capacity", DefaultMeshControlPlanePersistVolumeCapacity,
 		MeshControlPlanePVNotExistedHelpStr)
@@ -254,6 +377,7 @@ func (i *Install) AttachCmd(cmd *cobra.Command) {
 	cmd.Flags().IntVar(&i.EaseMeshOperatorReplicas, "easemesh-operator-replicas", DefaultMeshOperatorReplicas, "Mesh operator controller replicas")
 	cmd.Flags().StringVarP(&i.SpecFile, "file", "f", "", "A yaml file specifying the install params")
 	cmd.Flags().BoolVar(&i.CleanWhenFailed, "clean-when-failed", true, "Clean resources when installation failed")
+	cmd.Flags().StringVar(&i.Context, "context", "", "The name of the context in the rcfile to save the control plane address (default the current context or \"default\")")
 }
 
 // AttachCmd attaches options for reset sub command
@@ -264,19 +388,166 @@ func (r *Reset) AttachCmd(cmd *cobra.Command) {
 
 // AttachCmd attaches options globally
 func (o *OperationGlobal) AttachCmd(cmd *cobra.Command) {
-	cmd.Flags().StringVar(&o.MeshNamespace, "mesh-namespace", DefaultMeshNamespace, "EaseMesh namespace in kubernetes")
+	namespace := DefaultMeshNamespace
+	if current := globalRCFile.Current(); current != nil && current.Namespace != "" {
+		namespace = current.Namespace
+	}
+
+	cmd.Flags().StringVar(&o.MeshNamespace, "mesh-namespace", namespace, "EaseMesh namespace in kubernetes")
 	cmd.Flags().StringVar(&o.EgServiceName, "mesh-control-plane-service-name", DefaultMeshControlPlaneHeadfulServiceName, "Mesh control plane service name")
 }
 
-// AttachCmd attaches options for base administrator command
+// AttachCmd attaches options for base administrator command, the defaults
+// come from the current context of the rcfile, or the one named by --context.
 func (a *AdminGlobal) AttachCmd(cmd *cobra.Command) {
-	server := "127.0.0.1:2381"
-	if globalRCFile.Server != "" {
-		server = globalRCFile.Server
+	server, timeout := "127.0.0.1:2381", 30*time.Second
+	if current := globalRCFile.Current(); current != nil {
+		server, timeout = contextDefaults(current, server, timeout)
+	}
+
+	cmd.Flags().StringVar(&a.Context, "context", "", "The name of the context in the rcfile to use (default the current context)")
+	cmd.Flags().StringVarP(&a.Server, "server", "s", server, "Comma separated addresses to access the EaseMesh control plane, requests fail over between them, prefix an address with https:// to enable TLS")
+	cmd.Flags().DurationVarP(&a.Timeout, "timeout", "t", timeout, "A duration that limit max time out for requesting the EaseMesh control plane")
+
+	cmd.Flags().StringVar(&a.CertificateAuthority, "certificate-authority", "", "Path to a cert file for the certificate authority of the control plane")
+	cmd.Flags().StringVar(&a.ClientCertificate, "client-certificate", "", "Path to a client certificate file for TLS")
+	cmd.Flags().StringVar(&a.ClientKey, "client-key", "", "Path to a client key file for TLS")
+	cmd.Flags().BoolVar(&a.InsecureSkipTLSVerify, "insecure-skip-tls-verify", false, "Whether to skip verifying the certificate of the control plane")
+	cmd.Flags().StringVar(&a.Token, "token", "", "Bearer token for authentication to the control plane")
+	cmd.Flags().StringVar(&a.Username, "username", "", "Username for basic authentication to the control plane")
+	cmd.Flags().StringVar(&a.Password, "password", "", "Password for basic authentication to the control plane")
+
+	preRun := cmd.PreRun
+	cmd.PreRun = func(cmd *cobra.Command, args []string) {
+		if err := a.useContext(cmd); err != nil {
+			common.ExitWithErrorf("%v", err)
+		}
+		if err := a.completeClientOptions(); err != nil {
+			common.ExitWithErrorf("%v", err)
+		}
+		if preRun != nil {
+			preRun(cmd, args)
+		}
+	}
+}
+
+// ClientOptions returns the TLS and authentication options to access the control plane
+func (a *AdminGlobal) ClientOptions() []client.Option {
+	return a.clientOptions
+}
+
+// ContextDir returns the directory keeping the local state of the context in
+// use, such as the objects recorded by emctl apply --prune.
+func (a *AdminGlobal) ContextDir() string {
+	name := a.Context
+	if name == "" {
+		name = globalRCFile.CurrentContext
+	}
+	if name == "" {
+		name = rcfile.DefaultContextName
+	}
+	return path.Join(path.Dir(globalRCFile.Path()), ".emctl", name)
+}
+
+// useContext fills the options from the context specified by --context or
+// the current context, the flags set explicitly in command line take
+// precedence over it.
+func (a *AdminGlobal) useContext(cmd *cobra.Command) error {
+	context := globalRCFile.Current()
+	if a.Context != "" {
+		context = globalRCFile.GetContext(a.Context)
+		if context == nil {
+			return errors.Errorf("context %s not found in %s", a.Context, globalRCFile.Path())
+		}
+	}
+	if context == nil {
+		return nil
+	}
+
+	changed := cmd.Flags().Changed
+	server, timeout := contextDefaults(context, "127.0.0.1:2381", 30*time.Second)
+	if !changed("server") {
+		a.Server = server
+	}
+	if !changed("timeout") {
+		a.Timeout = timeout
+	}
+	if !changed("certificate-authority") {
+		a.CertificateAuthority = context.CertificateAuthority
+	}
+	if !changed("client-certificate") {
+		a.ClientCertificate = context.ClientCertificate
+	}
+	if !changed("client-key") {
+		a.ClientKey = context.ClientKey
+	}
+	if !changed("insecure-skip-tls-verify") {
+		a.InsecureSkipTLSVerify = context.InsecureSkipTLSVerify
+	}
+	if !changed("token") {
+		a.Token = context.Token
+	}
+	if !changed("username") {
+		a.Username = context.Username
+	}
+	if !changed("password") {
+		a.Password = context.Password
+	}
+	if !changed("tenant") {
+		a.Tenant = context.Tenant
 	}
 
-	cmd.Flags().StringVarP(&a.Server, "server", "s", server, "An address to access the EaseMesh control plane")
-	cmd.Flags().DurationVarP(&a.Timeout, "timeout", "t", 30*time.Second, "A duration that limit max time out for requesting the EaseMesh control plane")
+	return nil
+}
+
+// completeClientOptions builds the client options, the endpoints of the
+// server are accessed by https if any TLS option is specified without their
+// scheme.
+func (a *AdminGlobal) completeClientOptions() error {
+	a.clientOptions = nil
+
+	tlsEnabled := a.CertificateAuthority != "" || a.ClientCertificate != "" ||
+		a.ClientKey != "" || a.InsecureSkipTLSVerify
+	if tlsEnabled {
+		tlsConfig, err := client.NewTLSConfig(a.CertificateAuthority,
+			a.ClientCertificate, a.ClientKey, a.InsecureSkipTLSVerify)
+		if err != nil {
+			return errors.Wrap(err, "new tls config failed")
+		}
+		a.clientOptions = append(a.clientOptions, client.WrapTLSOption(tlsConfig))
+
+		endpoints := strings.Split(a.Server, ",")
+		for i, endpoint := range endpoints {
+			endpoint = strings.TrimSpace(endpoint)
+			if endpoint != "" && !strings.Contains(endpoint, "://") {
+				endpoint = "https://" + endpoint
+			}
+			endpoints[i] = endpoint
+		}
+		a.Server = strings.Join(endpoints, ",")
+	}
+
+	if a.Token != "" && a.Username != "" {
+		return errors.Errorf("token and username are both specified")
+	}
+	if a.Token != "" {
+		a.clientOptions = append(a.clientOptions, client.WrapBearerTokenOption(a.Token))
+	}
+	if a.Username != "" {
+		a.clientOptions = append(a.clientOptions, client.WrapBasicAuthOption(a.Username, a.Password))
+	}
+
+	return nil
+}
+
+func contextDefaults(context *rcfile.Context, server string, timeout time.Duration) (string, time.Duration) {
+	if context.Server != "" {
+		server = context.Server
+	}
+	if context.Timeout != 0 {
+		timeout = time.Duration(context.Timeout)
+	}
+	return server, timeout
 }
 
 // AttachCmd attaches file options for base administrator command
@@ -285,6 +556,47 @@ func (a *AdminFileInput) AttachCmd(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&a.Recursive, "recursive", "r", true, "Whether to recursively iterate all sub-directories and files of the location")
 }
 
+// attachTenant attaches the option of the default tenant of the Services
+// in the resource files.
+func attachTenant(cmd *cobra.Command, tenant *string, value string) {
+	cmd.Flags().StringVar(tenant, "tenant", value,
+		"The tenant of the Services without spec.registerTenant in the files (default the tenant of the context)")
+}
+
+// AttachCmd attaches dry run options for base administrator command
+func (a *AdminDryRun) AttachCmd(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&a.DryRun, "dry-run", DryRunNone,
+		`Must be "none", "client", or "server". If client, only check resources locally. If server, resolve resources against the control plane without modifying it`)
+	cmd.Flags().Lookup("dry-run").NoOptDefVal = DryRunClient
+}
+
+// Validate checks whether the dry run mode is supported
+func (a *AdminDryRun) Validate() error {
+	switch a.DryRun {
+	case DryRunNone, DryRunClient, DryRunServer:
+		return nil
+	default:
+		return errors.Errorf("unsupported dry run mode %s (support none, client, server)", a.DryRun)
+	}
+}
+
+// IsDryRun returns whether the command runs in any dry run mode
+func (a *AdminDryRun) IsDryRun() bool {
+	return a.DryRun != DryRunNone
+}
+
+// IsClientDryRun returns whether the command runs without requesting the
+// control plane at all
+func (a *AdminDryRun) IsClientDryRun() bool {
+	return a.DryRun == DryRunClient
+}
+
+// AttachCmd attaches label selector options for base administrator command
+func (a *AdminSelector) AttachCmd(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&a.Selector, "selector", "l", "",
+		"Selector (label query) to filter on, supports '=', '==', '!=', 'in', 'notin' and existence (e.g. -l key1=value1,key2!=value2,key3 in (a,b))")
+}
+
 // AttachCmd attaches options for apply sub command
 func (a *Apply) AttachCmd(cmd *cobra.Command) {
 	a.AdminGlobal = &AdminGlobal{}
@@ -292,6 +604,19 @@ func (a *Apply) AttachCmd(cmd *cobra.Command) {
 
 	a.AdminFileInput = &AdminFileInput{}
 	a.AdminFileInput.AttachCmd(cmd)
+
+	a.AdminDryRun = &AdminDryRun{}
+	a.AdminDryRun.AttachCmd(cmd)
+
+	a.AdminSelector = &AdminSelector{}
+	a.AdminSelector.AttachCmd(cmd)
+
+	attachTenant(cmd, &a.Tenant, "")
+	cmd.Flags().BoolVar(&a.Prune, "prune", false,
+		"Delete resources previously applied with the selector but not declared in the applied files any more, only objects matching the selector are applied")
+	cmd.Flags().BoolVar(&a.All, "all", false, "Prune all resources in the control plane not declared in the applied files, instead of the ones previously applied with the selector")
+	cmd.Flags().BoolVarP(&a.Yes, "yes", "y", false, "Prune resources without confirmation")
+	cmd.Flags().IntVar(&a.Concurrency, "concurrency", 1, "The number of resources of the same kind applied in parallel")
 }
 
 // AttachCmd attaches options for delete sub command
@@ -301,6 +626,43 @@ func (d *Delete) AttachCmd(cmd *cobra.Command) {
 
 	d.AdminFileInput = &AdminFileInput{}
 	d.AdminFileInput.AttachCmd(cmd)
+
+	d.AdminDryRun = &AdminDryRun{}
+	d.AdminDryRun.AttachCmd(cmd)
+
+	d.AdminSelector = &AdminSelector{}
+	d.AdminSelector.AttachCmd(cmd)
+
+	cmd.Flags().BoolVar(&d.All, "all", false, "Delete all resources of the specified kind")
+}
+
+// AttachCmd attaches options for diff sub command
+func (d *Diff) AttachCmd(cmd *cobra.Command) {
+	d.AdminGlobal = &AdminGlobal{}
+	d.AdminGlobal.AttachCmd(cmd)
+
+	d.AdminFileInput = &AdminFileInput{}
+	d.AdminFileInput.AttachCmd(cmd)
+
+	attachTenant(cmd, &d.Tenant, "")
+}
+
+// AttachCmd attaches options for validate sub command, it runs without the
+// control plane, so the default tenant comes from the current context only.
+func (v *Validate) AttachCmd(cmd *cobra.Command) {
+	v.AdminFileInput = &AdminFileInput{}
+	v.AdminFileInput.AttachCmd(cmd)
+
+	tenant := ""
+	if current := globalRCFile.Current(); current != nil {
+		tenant = current.Tenant
+	}
+	attachTenant(cmd, &v.Tenant, tenant)
+}
+
+// AttachCmd attaches options for schema sub command
+func (s *Schema) AttachCmd(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&s.OutputFormat, "output", "o", "json", "Output format (support json, yaml)")
 }
 
 // AttachCmd attaches options for get sub command
@@ -308,5 +670,68 @@ func (g *Get) AttachCmd(cmd *cobra.Command) {
 	g.AdminGlobal = &AdminGlobal{}
 	g.AdminGlobal.AttachCmd(cmd)
 
-	cmd.Flags().StringVarP(&g.OutputFormat, "output", "o", "table", "Output format (support table, yaml, json)")
+	g.AdminSelector = &AdminSelector{}
+	g.AdminSelector.AttachCmd(cmd)
+
+	cmd.Flags().StringVarP(&g.OutputFormat, "output", "o", "table", "Output format (support table, wide, yaml, json, custom-columns=<header>:<json path>[,...], jsonpath=<template>, go-template=<template>)")
+	cmd.Flags().StringVar(&g.SortBy, "sort-by", "", "A JSON path expression to sort the resources, e.g. .metadata.name")
+	cmd.Flags().BoolVar(&g.NoHeaders, "no-headers", false, "Whether to omit the headers of table, wide and custom-columns output")
+	cmd.Flags().BoolVarP(&g.Watch, "watch", "w", false, "After listing the resources, watch for changes of them until interrupted")
+	cmd.Flags().DurationVar(&g.WatchInterval, "watch-interval", 2*time.Second, "The interval of polling the control plane for changes in watch mode")
+}
+
+// AttachCmd attaches options for describe sub command
+func (d *Describe) AttachCmd(cmd *cobra.Command) {
+	d.AdminGlobal = &AdminGlobal{}
+	d.AdminGlobal.AttachCmd(cmd)
+}
+
+// AttachCmd attaches options for edit sub command
+func (e *Edit) AttachCmd(cmd *cobra.Command) {
+	e.AdminGlobal = &AdminGlobal{}
+	e.AdminGlobal.AttachCmd(cmd)
+}
+
+// AttachCmd attaches options for backup sub command
+func (b *Backup) AttachCmd(cmd *cobra.Command) {
+	b.AdminGlobal = &AdminGlobal{}
+	b.AdminGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVarP(&b.OutputFile, "output", "o", "", "The file to write the backup to, the backup is written to stdout if it's not specified")
+}
+
+// AttachCmd attaches options for restore sub command
+func (r *Restore) AttachCmd(cmd *cobra.Command) {
+	r.AdminGlobal = &AdminGlobal{}
+	r.AdminGlobal.AttachCmd(cmd)
+
+	cmd.Flags().StringVarP(&r.YamlFile, "file", "f", "", "The backup file written by emctl backup")
+	cmd.Flags().StringVar(&r.Conflict, "conflict", ConflictFail,
+		`Must be "skip", "overwrite" or "fail". The strategy for the resources existing in the control plane`)
+}
+
+// Validate checks whether the conflict strategy is supported
+func (r *Restore) Validate() error {
+	switch r.Conflict {
+	case ConflictSkip, ConflictOverwrite, ConflictFail:
+		return nil
+	default:
+		return errors.Errorf("unsupported conflict strategy %s (support skip, overwrite, fail)", r.Conflict)
+	}
+}
+
+// AttachCmd attaches options for the config set-context sub command
+func (c *ConfigSetContext) AttachCmd(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&c.Server, "server", "s", "", "Comma separated addresses to access the EaseMesh control plane")
+	cmd.Flags().DurationVarP(&c.Timeout, "timeout", "t", 0, "A duration that limit max time out for requesting the EaseMesh control plane")
+	cmd.Flags().StringVar(&c.CertificateAuthority, "certificate-authority", "", "Path to a cert file for the certificate authority of the control plane")
+	cmd.Flags().StringVar(&c.ClientCertificate, "client-certificate", "", "Path to a client certificate file for TLS")
+	cmd.Flags().StringVar(&c.ClientKey, "client-key", "", "Path to a client key file for TLS")
+	cmd.Flags().BoolVar(&c.InsecureSkipTLSVerify, "insecure-skip-tls-verify", false, "Whether to skip verifying the certificate of the control plane")
+	cmd.Flags().StringVar(&c.Token, "token", "", "Bearer token for authentication to the control plane")
+	cmd.Flags().StringVar(&c.Username, "username", "", "Username for basic authentication to the control plane")
+	cmd.Flags().StringVar(&c.Password, "password", "", "Password for basic authentication to the control plane")
+	cmd.Flags().StringVar(&c.Namespace, "mesh-namespace", "", "EaseMesh namespace in kubernetes")
+	cmd.Flags().StringVar(&c.Tenant, "tenant", "", "The tenant of the Services without spec.registerTenant in the applied files")
+	cmd.Flags().BoolVar(&c.Use, "use", false, "Whether to set the context as the current context")
 }
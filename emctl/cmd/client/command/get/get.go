@@ -18,6 +18,11 @@
 package get
 
 import (
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
 	"github.com/megaease/easemeshctl/cmd/client/command/flags"
 	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
 	"github.com/megaease/easemeshctl/cmd/client/command/printer"
@@ -31,11 +36,21 @@ import (
 
 // Run is the entrypoint of the get sub command
 func Run(cmd *cobra.Command, flags *flags.Get) {
-	switch flags.OutputFormat {
-	case "table", "yaml", "json":
-	default:
-		common.ExitWithErrorf("unsupported output format %s (support table, yaml, json)",
-			flags.OutputFormat)
+	printer, err := printer.New(flags.OutputFormat, printer.Options{
+		SortBy:    flags.SortBy,
+		NoHeaders: flags.NoHeaders,
+	})
+	if err != nil {
+		common.ExitWithErrorf("%v", err)
+	}
+
+	sel, err := util.ParseSelector(flags.Selector)
+	if err != nil {
+		common.ExitWithErrorf("%v", err)
+	}
+
+	if flags.Watch && flags.WatchInterval <= 0 {
+		common.ExitWithErrorf("watch interval must be positive")
 	}
 
 	visitorBulder := util.NewVisitorBuilder()
@@ -63,7 +78,6 @@ func Run(cmd *cobra.Command, flags *flags.Get) {
 		common.ExitWithErrorf("build visitor failed: %s", err)
 	}
 
-	printer := printer.New(flags.OutputFormat)
 	var errs []error
 	for _, vs := range vss {
 		err := vs.Visit(func(mo resource.MeshObject, e error) error {
@@ -76,12 +90,23 @@ func Run(cmd *cobra.Command, flags *flags.Get) {
 				resourceID += "/" + mo.Name()
 			}
 
-			objects, err := WrapGetterByMeshObject(mo, meshclient.New(flags.Server), flags.Timeout).Get()
+			if err := validateSelector(sel, mo.Kind()); err != nil {
+				return err
+			}
+
+			getter := WrapGetterByMeshObject(mo, meshclient.New(flags.Server, flags.ClientOptions()...), flags.Timeout)
+
+			if flags.Watch {
+				newWatcher(getter, sel, printer).run(flags.WatchInterval, interrupted())
+				return nil
+			}
+
+			objects, err := getter.Get()
 			if err != nil {
 				return errors.Wrapf(err, "%s get failed", resourceID)
 			}
 
-			printer.PrintObjects(objects)
+			printer.PrintObjects(util.FilterObjects(objects, sel))
 
 			return nil
 		})
@@ -92,6 +117,32 @@ func Run(cmd *cobra.Command, flags *flags.Get) {
 	}
 
 	if len(errs) > 0 {
-		common.ExitWithErrorf("getting resources has errors occurred")
+		msgs := make([]string, 0, len(errs))
+		for _, err := range errs {
+			msgs = append(msgs, err.Error())
+		}
+		common.ExitWithErrorf("getting resources has errors occurred:\n%s", strings.Join(msgs, "\n"))
+	}
+}
+
+// validateSelector rejects the selector for the kinds carrying no labels in
+// the control plane, as delete does.
+func validateSelector(sel util.Selector, kind string) error {
+	if err := util.ValidateSelectorKind(sel, kind); err != nil {
+		return errors.Errorf("%v, omit the selector to get all of them", err)
 	}
+	return nil
+}
+
+// interrupted returns a channel closed when the process is interrupted.
+func interrupted() <-chan struct{} {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	stop := make(chan struct{})
+	go func() {
+		<-signals
+		close(stop)
+	}()
+	return stop
 }
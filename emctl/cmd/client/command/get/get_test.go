@@ -0,0 +1,54 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package get
+
+import (
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+)
+
+func TestValidateSelector(t *testing.T) {
+	tests := []struct {
+		selector string
+		kind     string
+		valid    bool
+	}{
+		{"", resource.KindService, true},
+		{"app=vets", resource.KindServiceInstance, true},
+		{"!app", resource.KindServiceInstance, true},
+		// Negative requirements would select all objects without labels.
+		{"app=vets", resource.KindService, false},
+		{"!app", resource.KindTenant, false},
+	}
+
+	for _, tt := range tests {
+		sel, err := util.ParseSelector(tt.selector)
+		if err != nil {
+			t.Fatalf("parse selector %q failed: %v", tt.selector, err)
+		}
+		err = validateSelector(sel, tt.kind)
+		if tt.valid && err != nil {
+			t.Errorf("expect selector %q valid for %s but got %v", tt.selector, tt.kind, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("expect selector %q invalid for %s", tt.selector, tt.kind)
+		}
+	}
+}
@@ -71,6 +71,8 @@ func WrapGetterByMeshObject(object resource.MeshObject,
 		return &observabilityTracingsGetter{object: object.(*resource.ObservabilityTracings), baseGetter: base}
 	case resource.KindIngress:
 		return &ingressGetter{object: object.(*resource.Ingress), baseGetter: base}
+	case resource.KindServiceInstance:
+		return &serviceInstanceGetter{object: object.(*resource.ServiceInstance), baseGetter: base}
 	default:
 		common.ExitWithErrorf("BUG: unsupported kind: %s", object.Kind())
 	}
@@ -78,6 +80,19 @@ func WrapGetterByMeshObject(object resource.MeshObject,
 	return nil
 }
 
+// Exists reports whether the object exists in the control plane
+func Exists(object resource.MeshObject, client meshclient.MeshClient, timeout time.Duration) (bool, error) {
+	_, err := WrapGetterByMeshObject(object, client, timeout).Get()
+	switch {
+	case err == nil:
+		return true, nil
+	case meshclient.IsNotFoundError(err):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
 func (s *serviceGetter) Get() ([]resource.MeshObject, error) {
 	ctx, cancelFunc := context.WithTimeout(context.Background(), s.timeout)
 	defer cancelFunc()
@@ -351,3 +366,40 @@ func (i *ingressGetter) Get() ([]resource.MeshObject, error) {
 
 	return objects, nil
 }
+
+type serviceInstanceGetter struct {
+	baseGetter
+	object *resource.ServiceInstance
+}
+
+// Get gets the instance if the name is in form of <service name>/<instance id>,
+// or all instances of the service if the name is a service name.
+func (s *serviceInstanceGetter) Get() ([]resource.MeshObject, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), s.timeout)
+	defer cancelFunc()
+
+	serviceName, instanceID := resource.ParseServiceInstanceName(s.object.Name())
+	if instanceID != "" {
+		instance, err := s.client.V1Alpha1().ServiceInstance().Get(ctx, serviceName, instanceID)
+		if err != nil {
+			return nil, err
+		}
+
+		return []resource.MeshObject{instance}, nil
+	}
+
+	instances, err := s.client.V1Alpha1().ServiceInstance().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := []resource.MeshObject{}
+	for _, instance := range instances {
+		if serviceName != "" && instance.Spec.ServiceName != serviceName {
+			continue
+		}
+		objects = append(objects, instance)
+	}
+
+	return objects, nil
+}
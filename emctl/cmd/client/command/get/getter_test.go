@@ -0,0 +1,130 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package get
+
+import (
+	"testing"
+	"time"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+func newFakeServer() *fake.Server {
+	server := fake.NewServer()
+	server.AddTenant(&v1alpha1.Tenant{Name: "pet", Services: []string{"vets", "visits"}})
+	server.AddService(&v1alpha1.Service{
+		Name:           "vets",
+		RegisterTenant: "pet",
+		Canary:         &v1alpha1.Canary{},
+		LoadBalance:    &v1alpha1.LoadBalance{Policy: "random"},
+		Resilience:     &v1alpha1.Resilience{},
+		Observability: &v1alpha1.Observability{
+			Tracings:     &v1alpha1.ObservabilityTracings{Enabled: true},
+			Metrics:      &v1alpha1.ObservabilityMetrics{Enabled: true},
+			OutputServer: &v1alpha1.ObservabilityOutputServer{Enabled: true},
+		},
+	})
+	server.AddService(&v1alpha1.Service{Name: "visits", RegisterTenant: "pet"})
+	server.AddIngress(&v1alpha1.Ingress{Name: "pet-ingress"})
+	server.AddServiceInstance(&v1alpha1.ServiceInstance{ServiceName: "vets", InstanceID: "vets-1"})
+	server.AddServiceInstance(&v1alpha1.ServiceInstance{ServiceName: "vets", InstanceID: "vets-2"})
+	server.AddServiceInstance(&v1alpha1.ServiceInstance{ServiceName: "visits", InstanceID: "visits-1"})
+	return server
+}
+
+func TestGetter(t *testing.T) {
+	tests := []struct {
+		kind      string
+		newObject func(name string) resource.MeshObject
+		name      string
+		all       int
+	}{
+		{resource.KindTenant, func(name string) resource.MeshObject {
+			return resource.ToTenant(&v1alpha1.Tenant{Name: name})
+		}, "pet", 1},
+		{resource.KindService, func(name string) resource.MeshObject {
+			return resource.ToService(&v1alpha1.Service{Name: name})
+		}, "vets", 2},
+		{resource.KindIngress, func(name string) resource.MeshObject {
+			return resource.ToIngress(&v1alpha1.Ingress{Name: name})
+		}, "pet-ingress", 1},
+		{resource.KindCanary, func(name string) resource.MeshObject {
+			return resource.ToCanary(name, &v1alpha1.Canary{})
+		}, "vets", 1},
+		{resource.KindLoadBalance, func(name string) resource.MeshObject {
+			return resource.ToLoadBalance(name, &v1alpha1.LoadBalance{})
+		}, "vets", 1},
+		{resource.KindResilience, func(name string) resource.MeshObject {
+			return resource.ToResilience(name, &v1alpha1.Resilience{})
+		}, "vets", 1},
+		{resource.KindObservabilityTracings, func(name string) resource.MeshObject {
+			return resource.ToObservabilityTracings(name, &v1alpha1.ObservabilityTracings{})
+		}, "vets", 1},
+		{resource.KindObservabilityMetrics, func(name string) resource.MeshObject {
+			return resource.ToObservabilityMetrics(name, &v1alpha1.ObservabilityMetrics{})
+		}, "vets", 1},
+		{resource.KindObservabilityOutputServer, func(name string) resource.MeshObject {
+			return resource.ToObservabilityOutputServer(name, &v1alpha1.ObservabilityOutputServer{})
+		}, "vets", 1},
+		{resource.KindServiceInstance, func(name string) resource.MeshObject {
+			return &resource.ServiceInstance{MeshResource: resource.NewServiceInstanceResource(resource.DefaultAPIVersion, name)}
+		}, "vets/vets-1", 3},
+	}
+
+	server := newFakeServer()
+	defer server.Close()
+	client := meshclient.New(server.URL())
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			objects, err := WrapGetterByMeshObject(tt.newObject(tt.name), client, time.Second).Get()
+			if err != nil {
+				t.Fatalf("get %s %s failed: %v", tt.kind, tt.name, err)
+			}
+			if len(objects) != 1 || objects[0].Kind() != tt.kind || objects[0].Name() != tt.name {
+				t.Errorf("expect %s %s but got %v", tt.kind, tt.name, objects)
+			}
+
+			objects, err = WrapGetterByMeshObject(tt.newObject(""), client, time.Second).Get()
+			if err != nil {
+				t.Fatalf("list %s failed: %v", tt.kind, err)
+			}
+			if len(objects) != tt.all {
+				t.Errorf("expect %d %s but got %d", tt.all, tt.kind, len(objects))
+			}
+
+			exists, err := Exists(tt.newObject("missing/missing"), client, time.Second)
+			if err != nil || exists {
+				t.Errorf("expect missing %s not exists but got %v, %v", tt.kind, exists, err)
+			}
+		})
+	}
+
+	objects, err := WrapGetterByMeshObject(&resource.ServiceInstance{
+		MeshResource: resource.NewServiceInstanceResource(resource.DefaultAPIVersion, "vets"),
+	}, client, time.Second).Get()
+	if err != nil {
+		t.Fatalf("get instances of service vets failed: %v", err)
+	}
+	if len(objects) != 2 {
+		t.Errorf("expect 2 instances of service vets but got %d", len(objects))
+	}
+}
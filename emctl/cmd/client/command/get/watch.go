@@ -0,0 +1,130 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package get
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/printer"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// watcher watches the objects by polling the control plane, there is
+	// no watch API of the control plane for now. It compares the objects
+	// got by every poll with the ones got by the previous poll, and prints
+	// the changes as events.
+	watcher struct {
+		getter  Getter
+		sel     util.Selector
+		printer printer.Printer
+
+		// objects are the objects got by the previous poll, keyed by
+		// kind/name.
+		objects map[string]*watchedObject
+	}
+
+	watchedObject struct {
+		object  resource.MeshObject
+		content []byte
+	}
+)
+
+func newWatcher(getter Getter, sel util.Selector, printer printer.Printer) *watcher {
+	return &watcher{
+		getter:  getter,
+		sel:     sel,
+		printer: printer,
+		objects: map[string]*watchedObject{},
+	}
+}
+
+// run polls the objects every interval until the stop channel is closed.
+// The objects existing at the beginning are printed as added ones, a failed
+// poll is reported as a warning and the watcher keeps polling.
+func (w *watcher) run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.poll(); err != nil {
+			common.OutputWarningf("%v, retry in %s", err, interval)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll gets the objects and prints the changes since the previous poll.
+func (w *watcher) poll() error {
+	objects, err := w.getter.Get()
+	if meshclient.IsNotFoundError(err) {
+		objects, err = nil, nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "poll failed")
+	}
+
+	current := map[string]*watchedObject{}
+	var events []printer.Event
+	for _, object := range util.FilterObjects(objects, w.sel) {
+		content, err := json.Marshal(object)
+		if err != nil {
+			return errors.Wrapf(err, "marshal %s/%s to json", object.Kind(), object.Name())
+		}
+
+		key := object.Kind() + "/" + object.Name()
+		current[key] = &watchedObject{object: object, content: content}
+
+		previous, exists := w.objects[key]
+		switch {
+		case !exists:
+			events = append(events, printer.Event{Type: printer.EventAdded, Object: object})
+		case !bytes.Equal(previous.content, content):
+			events = append(events, printer.Event{Type: printer.EventModified, Object: object})
+		}
+	}
+
+	var deleted []string
+	for key := range w.objects {
+		if _, exists := current[key]; !exists {
+			deleted = append(deleted, key)
+		}
+	}
+	sort.Strings(deleted)
+	for _, key := range deleted {
+		events = append(events, printer.Event{Type: printer.EventDeleted, Object: w.objects[key].object})
+	}
+
+	w.objects = current
+	w.printer.PrintEvents(events)
+
+	return nil
+}
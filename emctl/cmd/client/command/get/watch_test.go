@@ -0,0 +1,86 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package get
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/printer"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+)
+
+// eventRecorder records the events as <type> <kind>/<name>.
+type eventRecorder struct {
+	events []string
+}
+
+func (r *eventRecorder) PrintObjects(objects []resource.MeshObject) {}
+
+func (r *eventRecorder) PrintEvents(events []printer.Event) {
+	for _, event := range events {
+		r.events = append(r.events, fmt.Sprintf("%s %s/%s", event.Type, event.Object.Kind(), event.Object.Name()))
+	}
+}
+
+func (r *eventRecorder) take() []string {
+	events := r.events
+	r.events = nil
+	return events
+}
+
+func TestWatcher(t *testing.T) {
+	server := newFakeServer()
+	defer server.Close()
+	client := meshclient.New(server.URL())
+	sel, _ := util.ParseSelector("")
+	recorder := &eventRecorder{}
+	w := newWatcher(WrapGetterByMeshObject(&resource.Service{
+		MeshResource: resource.NewServiceResource(resource.DefaultAPIVersion, ""),
+	}, client, time.Second), sel, recorder)
+
+	poll := func(expected ...string) {
+		t.Helper()
+		if err := w.poll(); err != nil {
+			t.Fatalf("poll failed: %v", err)
+		}
+		if events := recorder.take(); !reflect.DeepEqual(events, expected) {
+			t.Errorf("expect events %v but got %v", expected, events)
+		}
+	}
+
+	poll("ADDED Service/vets", "ADDED Service/visits")
+	poll()
+
+	server.AddService(&v1alpha1.Service{Name: "visits", RegisterTenant: "pet",
+		Sidecar: &v1alpha1.Sidecar{IngressPort: 13001}})
+	server.AddService(&v1alpha1.Service{Name: "owners", RegisterTenant: "pet"})
+	poll("ADDED Service/owners", "MODIFIED Service/visits")
+
+	err := client.V1Alpha1().Service().Delete(context.Background(), "vets")
+	if err != nil {
+		t.Fatalf("delete service failed: %v", err)
+	}
+	poll("DELETED Service/vets")
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/backup"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+
+	"github.com/spf13/cobra"
+)
+
+// BackupCmd invokes backup sub command entrypoint
+func BackupCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "backup",
+		Short:   "Back up all resources of easemesh to a file",
+		Example: "emctl backup -o mesh-backup.yaml",
+		Args:    cobra.NoArgs,
+	}
+
+	flags := &flags.Backup{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		backup.RunBackup(flags)
+	}
+
+	return cmd
+}
+
+// RestoreCmd invokes restore sub command entrypoint
+func RestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "restore",
+		Short:   "Restore resources of easemesh from a backup file",
+		Example: "emctl restore -f mesh-backup.yaml --conflict skip",
+		Args:    cobra.NoArgs,
+	}
+
+	flags := &flags.Restore{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		backup.RunRestore(flags)
+	}
+
+	return cmd
+}
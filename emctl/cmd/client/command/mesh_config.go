@@ -0,0 +1,91 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/config"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+
+	"github.com/spf13/cobra"
+)
+
+// ConfigCmd invokes config sub command entrypoint
+func ConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage contexts of the EaseMesh control planes in the rcfile",
+	}
+
+	cmd.AddCommand(
+		configGetContextsCmd(),
+		configUseContextCmd(),
+		configSetContextCmd(),
+		configDeleteContextCmd(),
+	)
+
+	return cmd
+}
+
+func configGetContextsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get-contexts",
+		Short: "List all contexts",
+		Run: func(cmd *cobra.Command, args []string) {
+			config.RunGetContexts(cmd)
+		},
+	}
+}
+
+func configUseContextCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "use-context <name>",
+		Short:   "Set the current context",
+		Example: "emctl config use-context prod",
+		Run: func(cmd *cobra.Command, args []string) {
+			config.RunUseContext(cmd)
+		},
+	}
+}
+
+func configSetContextCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "set-context <name>",
+		Short:   "Create a context or modify fields of an existed context",
+		Example: "emctl config set-context prod --server 10.0.0.1:2381 --timeout 10s",
+	}
+
+	flags := &flags.ConfigSetContext{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		config.RunSetContext(cmd, flags)
+	}
+
+	return cmd
+}
+
+func configDeleteContextCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "delete-context <name>",
+		Short:   "Delete a context",
+		Example: "emctl config delete-context dev",
+		Run: func(cmd *cobra.Command, args []string) {
+			config.RunDeleteContext(cmd)
+		},
+	}
+}
@@ -0,0 +1,43 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/edit"
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+
+	"github.com/spf13/cobra"
+)
+
+// EditCmd invokes edit sub command entrypoint
+func EditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "edit",
+		Short:   "Edit a resource of easemesh in an editor",
+		Example: "emctl edit service service-001 | EDITOR=nano emctl edit loadbalance service-001",
+	}
+
+	flags := &flags.Edit{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		edit.Run(cmd, flags)
+	}
+
+	return cmd
+}
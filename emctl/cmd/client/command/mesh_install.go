@@ -21,6 +21,9 @@ import (
 	stdcontext "context"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path"
+	"strings"
 
 	"github.com/megaease/easemeshctl/cmd/client/command/flags"
 	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
@@ -32,6 +35,7 @@ import (
 	"github.com/megaease/easemeshctl/cmd/client/command/rcfile"
 	"github.com/megaease/easemeshctl/cmd/common"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -120,20 +124,98 @@ func postInstall(context *installbase.StageContext) {
 		common.ExitWithErrorf("new rcfile failed: %v", err)
 	}
 
+	err = rc.Unmarshal()
+	if err != nil && !os.IsNotExist(errors.Cause(err)) {
+		common.ExitWithErrorf("unmarshal rcfile failed: %v", err)
+	}
+
+	contextName := context.Flags.Context
+	if contextName == "" {
+		contextName = rc.CurrentContext
+	}
+	if contextName == "" {
+		contextName = rcfile.DefaultContextName
+	}
+
+	rcContext := rc.GetContext(contextName)
+	if rcContext == nil {
+		rcContext = &rcfile.Context{Name: contextName}
+	}
+	rcContext.Namespace = namespace
+	rcContext.Server = ""
+
 	for _, port := range service.Spec.Ports {
 		if port.Name == installbase.DefaultMeshAdminPortName {
-			rc.Server = fmt.Sprintf("%s:%d", service.Spec.ClusterIP, port.Port)
+			rcContext.Server = fmt.Sprintf("%s:%d", service.Spec.ClusterIP, port.Port)
 		}
 	}
 
-	if rc.Server == "" {
+	if rcContext.Server == "" {
 		common.ExitWithErrorf("%s of service %s/%s not found", installbase.DefaultMeshAdminPortName, namespace, name)
 	}
 
+	scheme := "http://"
+	if context.Flags.MeshControlPlaneTLS {
+		caFile, err := saveControlPlaneCA(context, rc, contextName)
+		if err != nil {
+			common.ExitWithErrorf("save certificate authority of mesh control plane failed: %v", err)
+		}
+		scheme = "https://"
+		rcContext.CertificateAuthority = caFile
+	}
+
+	// The node ports are fallbacks when the cluster IP is unreachable,
+	// e.g. emctl runs outside of the cluster.
+	endpoints := []string{scheme + rcContext.Server}
+	entrypoints, err := installbase.GetMeshControlPanelEntryPoints(context.Client, namespace,
+		name, installbase.DefaultMeshAdminPortName)
+	if err != nil {
+		common.OutputErrorf("get entrypoints of service %s/%s failed: %v", namespace, name, err)
+	}
+	for _, entrypoint := range entrypoints {
+		if strings.HasSuffix(entrypoint, ":0") {
+			continue
+		}
+		endpoints = append(endpoints, scheme+strings.TrimPrefix(entrypoint, "http://"))
+	}
+	rcContext.Server = strings.Join(endpoints, ",")
+
+	rc.SetContext(rcContext)
+	if rc.CurrentContext == "" {
+		rc.CurrentContext = contextName
+	}
+
 	err = rc.Marshal()
 	if err != nil {
 		common.ExitWithError(err)
 	} else {
-		fmt.Printf("run commands file: %s\n", rc.Path())
+		fmt.Printf("run commands file: %s (context: %s)\n", rc.Path(), contextName)
+	}
+}
+
+// saveControlPlaneCA saves the certificate authority of the control plane
+// next to the rcfile, so that the context could verify the control plane.
+func saveControlPlaneCA(context *installbase.StageContext, rc *rcfile.RCFile, contextName string) (string, error) {
+	caPEM, err := installbase.GetMeshControlPlaneCA(context.Client, context.Flags.MeshNamespace)
+	if err != nil {
+		return "", err
+	}
+	if caPEM == nil {
+		return "", errors.Errorf("secret %s/%s not found",
+			context.Flags.MeshNamespace, installbase.DefaultMeshControlPlaneTLSSecret)
 	}
+
+	dir := path.Join(path.Dir(rc.Path()), ".emctl", contextName)
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return "", errors.Wrapf(err, "create directory %s", dir)
+	}
+
+	caFile := path.Join(dir, installbase.TLSCAKey)
+	err = ioutil.WriteFile(caFile, caPEM, 0600)
+	if err != nil {
+		return "", errors.Wrapf(err, "write file %s", caFile)
+	}
+
+	return caFile, nil
 }
@@ -0,0 +1,44 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package command
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/command/schema"
+
+	"github.com/spf13/cobra"
+)
+
+// SchemaCmd invokes schema sub command entrypoint
+func SchemaCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "schema [kind]",
+		Short:   "Print the JSON Schema of easemesh resources",
+		Long:    "",
+		Example: "emctl schema service",
+	}
+
+	flags := &flags.Schema{}
+	flags.AttachCmd(cmd)
+
+	cmd.Run = func(cmd *cobra.Command, args []string) {
+		schema.Run(cmd, flags)
+	}
+
+	return cmd
+}
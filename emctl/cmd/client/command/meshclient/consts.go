@@ -50,10 +50,10 @@ const (
 	// MeshServiceMetricsURL is the mesh service metrics path.
 	MeshServiceMetricsURL = apiURL + "/mesh/services/%s/metrics"
 
-	// MeshServiceInstancesURL is the mesh service prefix.
+	// MeshServiceInstancesURL is the mesh service instance prefix.
 	MeshServiceInstancesURL = apiURL + "/mesh/serviceinstances"
 
-	// MeshServiceInstanceURL is the mesh service path.
+	// MeshServiceInstanceURL is the mesh service instance path.
 	MeshServiceInstanceURL = apiURL + "/mesh/serviceinstances/%s/%s"
 
 	// MeshIngressesURL is the mesh ingress prefix.
@@ -17,7 +17,11 @@
 
 package meshclient
 
-import "github.com/pkg/errors"
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
 
 var (
 	// ConflictError indicate that the resource already exists
@@ -41,3 +45,15 @@ func IsNotFoundError(err error) (result bool) {
 	}
 	return
 }
+
+// StatusError indicates that the control plane responds an unexpected status code
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("call %s %s failed, return status code %d text %s", e.Method, e.URL, e.StatusCode, e.Body)
+}
@@ -0,0 +1,287 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/common"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+)
+
+// unhealthyDuration is how long a failed endpoint is tried after the others
+const unhealthyDuration = 30 * time.Second
+
+type (
+	// endpointPool holds endpoints of the control plane replicas, it's
+	// shared by the clients of the same servers so that the health of
+	// endpoints is kept across requests.
+	endpointPool struct {
+		mutex     sync.Mutex
+		endpoints []string
+		next      int
+		unhealthy map[string]time.Time
+	}
+
+	// failoverClient sends a request to the endpoints in round robin, the
+	// request is retried on the next endpoint when the connection fails or
+	// the endpoint returns 5xx for idempotent methods.
+	failoverClient struct {
+		pool    *endpointPool
+		options []client.Option
+	}
+
+	responseFunc func(client.UnmarshalFunc) (interface{}, error)
+
+	requestFunc func(c client.HTTPJSONClient, url string) client.HTTPJSONResponseHandler
+)
+
+var (
+	poolsMutex sync.Mutex
+	pools      = map[string]*endpointPool{}
+)
+
+var _ client.HTTPJSONClient = &failoverClient{}
+
+func (r responseFunc) HandleResponse(fn client.UnmarshalFunc) (interface{}, error) {
+	return r(fn)
+}
+
+// parseEndpoints parses the comma separated servers, the server is accessed
+// by http unless its scheme is specified, e.g. https://127.0.0.1:2381.
+func parseEndpoints(server string) []string {
+	endpoints := []string{}
+	for _, s := range strings.Split(server, ",") {
+		s = strings.TrimSuffix(strings.TrimSpace(s), "/")
+		if s == "" {
+			continue
+		}
+		if !strings.HasPrefix(s, "http://") && !strings.HasPrefix(s, "https://") {
+			s = "http://" + s
+		}
+		endpoints = append(endpoints, s)
+	}
+	return endpoints
+}
+
+func getEndpointPool(server string) *endpointPool {
+	poolsMutex.Lock()
+	defer poolsMutex.Unlock()
+
+	pool, exists := pools[server]
+	if !exists {
+		pool = &endpointPool{
+			endpoints: parseEndpoints(server),
+			unhealthy: map[string]time.Time{},
+		}
+		pools[server] = pool
+	}
+	return pool
+}
+
+// order returns endpoints starting from the next one of round robin, the
+// unhealthy endpoints are put at the end.
+func (p *endpointPool) order() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	n := len(p.endpoints)
+	healthy, unhealthy := []string{}, []string{}
+	now := time.Now()
+	for i := 0; i < n; i++ {
+		endpoint := p.endpoints[(p.next+i)%n]
+		if until, exists := p.unhealthy[endpoint]; exists && now.Before(until) {
+			unhealthy = append(unhealthy, endpoint)
+		} else {
+			healthy = append(healthy, endpoint)
+		}
+	}
+	if n != 0 {
+		p.next = (p.next + 1) % n
+	}
+
+	return append(healthy, unhealthy...)
+}
+
+func (p *endpointPool) markHealthy(endpoint string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	delete(p.unhealthy, endpoint)
+}
+
+func (p *endpointPool) markUnhealthy(endpoint string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.unhealthy[endpoint] = time.Now().Add(unhealthyDuration)
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// isDialError reports whether the request failed before being sent, so it's
+// safe to retry the non-idempotent ones.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func (f *failoverClient) do(done func() bool, method, path string, request requestFunc) client.HTTPJSONResponseHandler {
+	endpoints := f.pool.order()
+	if len(endpoints) == 0 {
+		return responseFunc(func(client.UnmarshalFunc) (interface{}, error) {
+			return nil, errors.Errorf("no endpoint of the control plane specified")
+		})
+	}
+
+	var failures []string
+	for i, endpoint := range endpoints {
+		var body []byte
+		var statusCode int
+		_, err := request(client.NewHTTPJSON(f.options...), endpoint+path).
+			HandleResponse(func(b []byte, s int) (interface{}, error) {
+				body, statusCode = b, s
+				return nil, nil
+			})
+
+		var reason string
+		retriable := false
+		switch {
+		case err != nil:
+			reason = err.Error()
+			retriable = !done() && (idempotent(method) || isDialError(err))
+		case statusCode >= 500 && idempotent(method):
+			reason = fmt.Sprintf("status code %d", statusCode)
+			retriable = true
+		}
+
+		if reason == "" {
+			f.pool.markHealthy(endpoint)
+			if len(failures) != 0 {
+				common.OutputWarningf("%s %s served by %s after failures: %s",
+					method, path, endpoint, strings.Join(failures, "; "))
+			}
+		} else {
+			f.pool.markUnhealthy(endpoint)
+			failures = append(failures, endpoint+": "+reason)
+			if retriable && i != len(endpoints)-1 {
+				continue
+			}
+		}
+
+		if err != nil {
+			if len(endpoints) > 1 {
+				err = errors.Errorf("%s %s failed on all tried endpoints: %s",
+					method, path, strings.Join(failures, "; "))
+			}
+			return responseFunc(func(client.UnmarshalFunc) (interface{}, error) {
+				return nil, err
+			})
+		}
+
+		return responseFunc(func(fn client.UnmarshalFunc) (interface{}, error) {
+			return fn(body, statusCode)
+		})
+	}
+
+	// unreachable: the last endpoint always returns
+	return nil
+}
+
+// deadline reports whether the timeout of the whole request is exceeded
+func deadline(timeout time.Duration) func() bool {
+	d := time.Now().Add(timeout)
+	return func() bool { return time.Now().After(d) }
+}
+
+func canceled(ctx context.Context) func() bool {
+	return func() bool { return ctx.Err() != nil }
+}
+
+func (f *failoverClient) Post(path string, reqBody interface{}, timeout time.Duration, extraHeaders map[string]string) client.HTTPJSONResponseHandler {
+	return f.do(deadline(timeout), http.MethodPost, path, func(c client.HTTPJSONClient, url string) client.HTTPJSONResponseHandler {
+		return c.Post(url, reqBody, timeout, extraHeaders)
+	})
+}
+
+func (f *failoverClient) PostByContext(ctx context.Context, path string, reqBody interface{}, extraHeaders map[string]string) client.HTTPJSONResponseHandler {
+	return f.do(canceled(ctx), http.MethodPost, path, func(c client.HTTPJSONClient, url string) client.HTTPJSONResponseHandler {
+		return c.PostByContext(ctx, url, reqBody, extraHeaders)
+	})
+}
+
+func (f *failoverClient) Delete(path string, reqBody interface{}, timeout time.Duration, extraHeaders map[string]string) client.HTTPJSONResponseHandler {
+	return f.do(deadline(timeout), http.MethodDelete, path, func(c client.HTTPJSONClient, url string) client.HTTPJSONResponseHandler {
+		return c.Delete(url, reqBody, timeout, extraHeaders)
+	})
+}
+
+func (f *failoverClient) DeleteByContext(ctx context.Context, path string, reqBody interface{}, extraHeaders map[string]string) client.HTTPJSONResponseHandler {
+	return f.do(canceled(ctx), http.MethodDelete, path, func(c client.HTTPJSONClient, url string) client.HTTPJSONResponseHandler {
+		return c.DeleteByContext(ctx, url, reqBody, extraHeaders)
+	})
+}
+
+func (f *failoverClient) Patch(path string, reqBody interface{}, timeout time.Duration, extraHeaders map[string]string) client.HTTPJSONResponseHandler {
+	return f.do(deadline(timeout), http.MethodPatch, path, func(c client.HTTPJSONClient, url string) client.HTTPJSONResponseHandler {
+		return c.Patch(url, reqBody, timeout, extraHeaders)
+	})
+}
+
+func (f *failoverClient) PatchByContext(ctx context.Context, path string, reqBody interface{}, extraHeaders map[string]string) client.HTTPJSONResponseHandler {
+	return f.do(canceled(ctx), http.MethodPatch, path, func(c client.HTTPJSONClient, url string) client.HTTPJSONResponseHandler {
+		return c.PatchByContext(ctx, url, reqBody, extraHeaders)
+	})
+}
+
+func (f *failoverClient) Put(path string, reqBody interface{}, timeout time.Duration, extraHeaders map[string]string) client.HTTPJSONResponseHandler {
+	return f.do(deadline(timeout), http.MethodPut, path, func(c client.HTTPJSONClient, url string) client.HTTPJSONResponseHandler {
+		return c.Put(url, reqBody, timeout, extraHeaders)
+	})
+}
+
+func (f *failoverClient) PutByContext(ctx context.Context, path string, reqBody interface{}, extraHeaders map[string]string) client.HTTPJSONResponseHandler {
+	return f.do(canceled(ctx), http.MethodPut, path, func(c client.HTTPJSONClient, url string) client.HTTPJSONResponseHandler {
+		return c.PutByContext(ctx, url, reqBody, extraHeaders)
+	})
+}
+
+func (f *failoverClient) Get(path string, reqBody interface{}, timeout time.Duration, extraHeaders map[string]string) client.HTTPJSONResponseHandler {
+	return f.do(deadline(timeout), http.MethodGet, path, func(c client.HTTPJSONClient, url string) client.HTTPJSONResponseHandler {
+		return c.Get(url, reqBody, timeout, extraHeaders)
+	})
+}
+
+func (f *failoverClient) GetByContext(ctx context.Context, path string, reqBody interface{}, extraHeaders map[string]string) client.HTTPJSONResponseHandler {
+	return f.do(canceled(ctx), http.MethodGet, path, func(c client.HTTPJSONClient, url string) client.HTTPJSONResponseHandler {
+		return c.GetByContext(ctx, url, reqBody, extraHeaders)
+	})
+}
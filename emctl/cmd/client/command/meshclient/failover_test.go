@@ -0,0 +1,114 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+func newTenantServer(t *testing.T, statusCode int, hits *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		if statusCode != http.StatusOK {
+			w.WriteHeader(statusCode)
+			return
+		}
+		buff, err := json.Marshal(&v1alpha1.Tenant{Name: "pet"})
+		if err != nil {
+			t.Fatalf("marshal tenant failed: %v", err)
+		}
+		w.Write(buff)
+	}))
+}
+
+func TestParseEndpoints(t *testing.T) {
+	endpoints := parseEndpoints(" 127.0.0.1:2381, https://10.0.0.1:2381/,,http://10.0.0.2:2381")
+	expected := []string{"http://127.0.0.1:2381", "https://10.0.0.1:2381", "http://10.0.0.2:2381"}
+	if strings.Join(endpoints, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expect endpoints %v but got %v", expected, endpoints)
+	}
+}
+
+func TestFailover(t *testing.T) {
+	var brokenHits, healthyHits int32
+	broken := newTenantServer(t, http.StatusBadGateway, &brokenHits)
+	defer broken.Close()
+	healthy := newTenantServer(t, http.StatusOK, &healthyHits)
+	defer healthy.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	server := strings.Join([]string{down.URL, broken.URL, healthy.URL}, ",")
+	client := New(server)
+	for i := 0; i < 3; i++ {
+		tenant, err := client.V1Alpha1().Tenant().Get(context.Background(), "pet")
+		if err != nil {
+			t.Fatalf("get tenant failed: %v", err)
+		}
+		if tenant.Name() != "pet" {
+			t.Fatalf("expect tenant pet but got %s", tenant.Name())
+		}
+	}
+
+	if healthyHits != 3 {
+		t.Errorf("expect 3 requests served by the healthy endpoint but got %d", healthyHits)
+	}
+	// The broken endpoint is tried at most once before marked unhealthy,
+	// after that the healthy endpoint is always tried first.
+	if brokenHits > 1 {
+		t.Errorf("expect the broken endpoint tried at most once but got %d", brokenHits)
+	}
+
+	// Create is not idempotent, it's not retried after the request was sent.
+	atomic.StoreInt32(&healthyHits, 0)
+	client = New(strings.Join([]string{broken.URL, healthy.URL}, ","))
+	err := client.V1Alpha1().Tenant().Create(context.Background(), resource.ToTenant(&v1alpha1.Tenant{Name: "pet"}))
+	if err == nil {
+		t.Errorf("expect create failed on the broken endpoint")
+	}
+	if healthyHits != 0 {
+		t.Errorf("expect create not retried but got %d requests on the healthy endpoint", healthyHits)
+	}
+}
+
+func TestAllEndpointsFailed(t *testing.T) {
+	down1 := httptest.NewServer(http.NotFoundHandler())
+	down1.Close()
+	down2 := httptest.NewServer(http.NotFoundHandler())
+	down2.Close()
+
+	client := New(down1.URL + "," + down2.URL)
+	_, err := client.V1Alpha1().Tenant().Get(context.Background(), "pet")
+	if err == nil {
+		t.Fatalf("expect error when all endpoints are down")
+	}
+	for _, url := range []string{down1.URL, down2.URL} {
+		if !strings.Contains(err.Error(), url) {
+			t.Errorf("expect endpoint %s reported in error: %v", url, err)
+		}
+	}
+}
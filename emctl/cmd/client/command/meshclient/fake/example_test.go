@@ -0,0 +1,53 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fake_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+func ExampleServer() {
+	server := fake.NewServer()
+	defer server.Close()
+	server.AddService(&v1alpha1.Service{Name: "vets", RegisterTenant: "pet"})
+
+	client := meshclient.New(server.URL())
+	ctx := context.Background()
+
+	err := client.V1Alpha1().Service().Create(ctx, resource.ToService(&v1alpha1.Service{Name: "vets"}))
+	fmt.Println(meshclient.IsConflictError(err))
+
+	err = client.V1Alpha1().Canary().Create(ctx, resource.ToCanary("vets", &v1alpha1.Canary{}))
+	fmt.Println(err)
+
+	for _, r := range server.Requests() {
+		fmt.Println(r.Method, r.Path)
+	}
+
+	// Output:
+	// true
+	// <nil>
+	// POST /apis/v1/mesh/services/vets
+	// POST /apis/v1/mesh/services/vets/canary
+}
@@ -0,0 +1,406 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package fake provides an in-memory fake of the EaseMesh control plane for
+// testing emctl and the tools built on meshclient without a live Easegress.
+package fake
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+)
+
+const apiPrefix = "/apis/v1/mesh/"
+
+type (
+	// Server is an in-memory fake of the REST apis of the EaseMesh control
+	// plane served by an httptest.Server. It follows the semantics of the
+	// control plane: creating an existing resource responds 409, getting,
+	// updating or deleting a missing one responds 404. Every request is
+	// recorded for assertions.
+	Server struct {
+		server *httptest.Server
+
+		mutex       sync.Mutex
+		collections map[string]map[string]interface{}
+		requests    []Request
+		statuses    map[string]int
+	}
+
+	// Request is a request received by the Server.
+	Request struct {
+		Method string
+		Path   string
+		Body   []byte
+	}
+
+	// subresource is a resource attached to the service, such as canary.
+	subresource struct {
+		newObject func() interface{}
+		// get returns nil if the service has no such resource.
+		get func(service *v1alpha1.Service) interface{}
+		// set removes the resource from the service if the object is nil.
+		set func(service *v1alpha1.Service, object interface{})
+	}
+)
+
+// newObjects news the top level resources keyed by the segment of their path.
+var newObjects = map[string]func() interface{}{
+	"tenants":          func() interface{} { return &v1alpha1.Tenant{} },
+	"services":         func() interface{} { return &v1alpha1.Service{} },
+	"ingresses":        func() interface{} { return &v1alpha1.Ingress{} },
+	"serviceinstances": func() interface{} { return &v1alpha1.ServiceInstance{} },
+}
+
+func observability(service *v1alpha1.Service) *v1alpha1.Observability {
+	if service.Observability == nil {
+		service.Observability = &v1alpha1.Observability{}
+	}
+	return service.Observability
+}
+
+var subresources = map[string]subresource{
+	"canary": {
+		newObject: func() interface{} { return &v1alpha1.Canary{} },
+		get: func(s *v1alpha1.Service) interface{} {
+			if s.Canary == nil {
+				return nil
+			}
+			return s.Canary
+		},
+		set: func(s *v1alpha1.Service, o interface{}) {
+			s.Canary, _ = o.(*v1alpha1.Canary)
+		},
+	},
+	"resilience": {
+		newObject: func() interface{} { return &v1alpha1.Resilience{} },
+		get: func(s *v1alpha1.Service) interface{} {
+			if s.Resilience == nil {
+				return nil
+			}
+			return s.Resilience
+		},
+		set: func(s *v1alpha1.Service, o interface{}) {
+			s.Resilience, _ = o.(*v1alpha1.Resilience)
+		},
+	},
+	"loadbalance": {
+		newObject: func() interface{} { return &v1alpha1.LoadBalance{} },
+		get: func(s *v1alpha1.Service) interface{} {
+			if s.LoadBalance == nil {
+				return nil
+			}
+			return s.LoadBalance
+		},
+		set: func(s *v1alpha1.Service, o interface{}) {
+			s.LoadBalance, _ = o.(*v1alpha1.LoadBalance)
+		},
+	},
+	"outputserver": {
+		newObject: func() interface{} { return &v1alpha1.ObservabilityOutputServer{} },
+		get: func(s *v1alpha1.Service) interface{} {
+			if s.Observability == nil || s.Observability.OutputServer == nil {
+				return nil
+			}
+			return s.Observability.OutputServer
+		},
+		set: func(s *v1alpha1.Service, o interface{}) {
+			observability(s).OutputServer, _ = o.(*v1alpha1.ObservabilityOutputServer)
+		},
+	},
+	"tracings": {
+		newObject: func() interface{} { return &v1alpha1.ObservabilityTracings{} },
+		get: func(s *v1alpha1.Service) interface{} {
+			if s.Observability == nil || s.Observability.Tracings == nil {
+				return nil
+			}
+			return s.Observability.Tracings
+		},
+		set: func(s *v1alpha1.Service, o interface{}) {
+			observability(s).Tracings, _ = o.(*v1alpha1.ObservabilityTracings)
+		},
+	},
+	"metrics": {
+		newObject: func() interface{} { return &v1alpha1.ObservabilityMetrics{} },
+		get: func(s *v1alpha1.Service) interface{} {
+			if s.Observability == nil || s.Observability.Metrics == nil {
+				return nil
+			}
+			return s.Observability.Metrics
+		},
+		set: func(s *v1alpha1.Service, o interface{}) {
+			observability(s).Metrics, _ = o.(*v1alpha1.ObservabilityMetrics)
+		},
+	},
+}
+
+// NewServer starts a new fake control plane, the caller should Close it.
+func NewServer() *Server {
+	s := &Server{
+		collections: map[string]map[string]interface{}{},
+		statuses:    map[string]int{},
+	}
+	for collection := range newObjects {
+		s.collections[collection] = map[string]interface{}{}
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// URL returns the address of the server, which is used as the server of meshclient.
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []Request {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]Request{}, s.requests...)
+}
+
+// ResetRequests clears the recorded requests.
+func (s *Server) ResetRequests() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.requests = nil
+}
+
+// SetStatus makes the server respond the status code to the requests of the
+// method and path, e.g. SetStatus("GET", "/apis/v1/mesh/tenants", 500).
+// A zero status code restores the normal handling.
+func (s *Server) SetStatus(method, path string, statusCode int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if statusCode == 0 {
+		delete(s.statuses, method+" "+path)
+		return
+	}
+	s.statuses[method+" "+path] = statusCode
+}
+
+// AddTenant stores the tenant without recording a request.
+func (s *Server) AddTenant(tenant *v1alpha1.Tenant) {
+	s.add("tenants", tenant.Name, tenant)
+}
+
+// AddService stores the service without recording a request.
+func (s *Server) AddService(service *v1alpha1.Service) {
+	s.add("services", service.Name, service)
+}
+
+// AddIngress stores the ingress without recording a request.
+func (s *Server) AddIngress(ingress *v1alpha1.Ingress) {
+	s.add("ingresses", ingress.Name, ingress)
+}
+
+// AddServiceInstance stores the service instance without recording a
+// request, the instances are registered by sidecars in the real mesh.
+func (s *Server) AddServiceInstance(instance *v1alpha1.ServiceInstance) {
+	s.add("serviceinstances", instance.ServiceName+"/"+instance.InstanceID, instance)
+}
+
+func (s *Server) add(collection, name string, object interface{}) {
+	// Store a copy, so the caller is free to modify the object.
+	buff, err := json.Marshal(object)
+	if err != nil {
+		panic(err)
+	}
+	stored := newObjects[collection]()
+	if err := json.Unmarshal(buff, stored); err != nil {
+		panic(err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.collections[collection][name] = stored
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Body: body})
+	if statusCode, exists := s.statuses[r.Method+" "+r.URL.Path]; exists {
+		w.WriteHeader(statusCode)
+		return
+	}
+
+	if !strings.HasPrefix(r.URL.Path, apiPrefix) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	segments := strings.Split(strings.TrimPrefix(r.URL.Path, apiPrefix), "/")
+	collection := segments[0]
+	if _, exists := newObjects[collection]; !exists {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch {
+	case len(segments) == 1:
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		s.list(w, collection)
+	case collection == "serviceinstances" && len(segments) == 3:
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		s.handleObject(w, r.Method, collection, segments[1]+"/"+segments[2], body)
+	case collection != "serviceinstances" && len(segments) == 2:
+		s.handleObject(w, r.Method, collection, segments[1], body)
+	case collection == "services" && len(segments) == 3:
+		sub, exists := subresources[segments[2]]
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		s.handleSubresource(w, r.Method, segments[1], sub, body)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *Server) list(w http.ResponseWriter, collection string) {
+	names := []string{}
+	for name := range s.collections[collection] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	objects := []interface{}{}
+	for _, name := range names {
+		objects = append(objects, s.collections[collection][name])
+	}
+	writeJSON(w, objects)
+}
+
+func (s *Server) handleObject(w http.ResponseWriter, method, collection, name string, body []byte) {
+	objects := s.collections[collection]
+	object, exists := objects[name]
+
+	switch method {
+	case http.MethodGet:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeJSON(w, object)
+	case http.MethodPost, http.MethodPut:
+		if method == http.MethodPost && exists {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if method == http.MethodPut && !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		object := newObjects[collection]()
+		if err := json.Unmarshal(body, object); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		objects[name] = object
+		if method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+	case http.MethodDelete:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(objects, name)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleSubresource(w http.ResponseWriter, method, serviceName string, sub subresource, body []byte) {
+	object, exists := s.collections["services"][serviceName]
+	if !exists {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	service := object.(*v1alpha1.Service)
+	current := sub.get(service)
+
+	switch method {
+	case http.MethodGet:
+		if current == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeJSON(w, current)
+	case http.MethodPost, http.MethodPut:
+		if method == http.MethodPost && current != nil {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if method == http.MethodPut && current == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		object := sub.newObject()
+		if err := json.Unmarshal(body, object); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sub.set(service, object)
+		if method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+	case http.MethodDelete:
+		if current == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		sub.set(service, nil)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	buff, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(buff)
+}
@@ -37,6 +37,8 @@ type V1Alpha1Interface interface {
 	ObservabilityGetter
 	ResilienceGetter
 	IngressGetter
+	ServiceInstanceGetter
+	ResourceGetter
 }
 
 // TenantGetter represents a Tenant resource accessor
@@ -76,6 +78,16 @@ type IngressGetter interface {
 	Ingress() IngressInterface
 }
 
+// ServiceInstanceGetter represents a ServiceInstance resource accessor
+type ServiceInstanceGetter interface {
+	ServiceInstance() ServiceInstanceInterface
+}
+
+// ResourceGetter represents an accessor of the resources of any kind
+type ResourceGetter interface {
+	Resource(kind string) ResourceInterface
+}
+
 // TenantInterface captures the set of operations for interacting with the EaseMesh REST apis of the tenant resource.
 type TenantInterface interface {
 	Get(context.Context, string) (*resource.Tenant, error)
@@ -156,3 +168,22 @@ type IngressInterface interface {
 	Delete(context.Context, string) error
 	List(context.Context) ([]*resource.Ingress, error)
 }
+
+// ServiceInstanceInterface captures the set of operations for interacting with the EaseMesh REST apis of the service instance resource.
+// The instances are registered by sidecars, so there is no Create, and Patch puts the whole instance, it should be based on the one got from the control plane.
+type ServiceInstanceInterface interface {
+	Get(ctx context.Context, serviceName, instanceID string) (*resource.ServiceInstance, error)
+	Patch(context.Context, *resource.ServiceInstance) error
+	Delete(ctx context.Context, serviceName, instanceID string) error
+	List(context.Context) ([]*resource.ServiceInstance, error)
+}
+
+// ResourceInterface captures the set of operations for interacting with the EaseMesh REST apis of the resources of a kind,
+// the operations of an unsupported kind always fail.
+type ResourceInterface interface {
+	Get(context.Context, string) (resource.MeshObject, error)
+	Patch(context.Context, resource.MeshObject) error
+	Create(context.Context, resource.MeshObject) error
+	Delete(context.Context, string) error
+	List(context.Context) ([]resource.MeshObject, error)
+}
@@ -17,8 +17,13 @@
 
 package meshclient
 
+import (
+	"github.com/megaease/easemeshctl/cmd/common/client"
+)
+
 type meshClient struct {
 	server   string
+	options  []client.Option
 	v1Alpha1 V1Alpha1Interface
 }
 
@@ -26,6 +31,17 @@ func (m *meshClient) V1Alpha1() V1Alpha1Interface {
 	return m.v1Alpha1
 }
 
+// httpJSON returns the client sending requests to the endpoints of the
+// control plane with failover.
+func (m *meshClient) httpJSON() client.HTTPJSONClient {
+	return &failoverClient{pool: getEndpointPool(m.server), options: m.options}
+}
+
+// resource returns the REST client of the resources of the kind.
+func (m *meshClient) resource(kind string) ResourceInterface {
+	return &restClient{client: m, kind: kind}
+}
+
 type v1alpha1Interface struct {
 	loadbalanceGetter
 	canaryGetter
@@ -34,21 +50,27 @@ type v1alpha1Interface struct {
 	tenantGetter
 	observabilityGetter
 	ingressGetter
+	serviceInstanceGetter
+	resourceGetter
 }
 
 var _ V1Alpha1Interface = &v1alpha1Interface{}
 
-// New initials a new MeshClient
-func New(server string) MeshClient {
-	client := &meshClient{server: server}
+// New initials a new MeshClient, the server is a comma separated list of
+// endpoints of the control plane, requests fail over between them. The
+// options such as TLS and authentication are applied to every request.
+func New(server string, options ...client.Option) MeshClient {
+	client := &meshClient{server: server, options: options}
 	alpha1 := v1alpha1Interface{
-		loadbalanceGetter:   loadbalanceGetter{client: client},
-		canaryGetter:        canaryGetter{client: client},
-		resilienceGetter:    resilienceGetter{client: client},
-		tenantGetter:        tenantGetter{client: client},
-		observabilityGetter: observabilityGetter{client: client},
-		serviceGetter:       serviceGetter{client: client},
-		ingressGetter:       ingressGetter{client: client},
+		loadbalanceGetter:     loadbalanceGetter{client: client},
+		canaryGetter:          canaryGetter{client: client},
+		resilienceGetter:      resilienceGetter{client: client},
+		tenantGetter:          tenantGetter{client: client},
+		observabilityGetter:   observabilityGetter{client: client},
+		serviceGetter:         serviceGetter{client: client},
+		ingressGetter:         ingressGetter{client: client},
+		serviceInstanceGetter: serviceInstanceGetter{client: client},
+		resourceGetter:        resourceGetter{client: client},
 	}
 	client.v1Alpha1 = &alpha1
 	return client
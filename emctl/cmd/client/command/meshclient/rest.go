@@ -0,0 +1,370 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// restKind describes how the resources of a kind are accessed by the
+	// REST apis of the control plane, adding a kind is adding an entry of
+	// restKinds.
+	restKind struct {
+		// url is the path of a resource, formatted with the segments of its name.
+		url string
+		// listURL is the path listing all resources of the kind.
+		listURL string
+		// nameSegments splits the name to the segments of url, the name is
+		// the only segment if it's nil.
+		nameSegments func(name string) []interface{}
+
+		// newObject news a v1alpha1 object of the kind to unmarshal.
+		newObject func() interface{}
+		// toResource converts the v1alpha1 object to the resource with the name.
+		toResource func(name string, object interface{}) resource.MeshObject
+		// toObject converts the resource to the v1alpha1 object.
+		toObject func(mo resource.MeshObject) interface{}
+		// fromService extracts the resource from the service, it's only set
+		// for the kinds attached to services, which are listed by listing
+		// services, it returns nil if the service has no such resource.
+		fromService func(service *v1alpha1.Service) resource.MeshObject
+	}
+
+	// restClient is the REST client of all kinds in restKinds, the errors
+	// of all kinds share the same semantics:
+	//   - 404 is NotFoundError
+	//   - 409 is ConflictError
+	//   - other non 2xx status codes are StatusError
+	restClient struct {
+		client *meshClient
+		kind   string
+	}
+)
+
+var restKinds = map[string]*restKind{
+	resource.KindTenant: {
+		url:       MeshTenantURL,
+		listURL:   MeshTenantsURL,
+		newObject: func() interface{} { return &v1alpha1.Tenant{} },
+		toResource: func(_ string, object interface{}) resource.MeshObject {
+			return resource.ToTenant(object.(*v1alpha1.Tenant))
+		},
+		toObject: func(mo resource.MeshObject) interface{} { return mo.(*resource.Tenant).ToV1Alpha1() },
+	},
+	resource.KindService: {
+		url:       MeshServiceURL,
+		listURL:   MeshServicesURL,
+		newObject: func() interface{} { return &v1alpha1.Service{} },
+		toResource: func(_ string, object interface{}) resource.MeshObject {
+			return resource.ToService(object.(*v1alpha1.Service))
+		},
+		toObject: func(mo resource.MeshObject) interface{} { return mo.(*resource.Service).ToV1Alpha1() },
+	},
+	resource.KindIngress: {
+		url:       MeshIngressURL,
+		listURL:   MeshIngressesURL,
+		newObject: func() interface{} { return &v1alpha1.Ingress{} },
+		toResource: func(_ string, object interface{}) resource.MeshObject {
+			return resource.ToIngress(object.(*v1alpha1.Ingress))
+		},
+		toObject: func(mo resource.MeshObject) interface{} { return mo.(*resource.Ingress).ToV1Alpha1() },
+	},
+	resource.KindServiceInstance: {
+		url:     MeshServiceInstanceURL,
+		listURL: MeshServiceInstancesURL,
+		nameSegments: func(name string) []interface{} {
+			serviceName, instanceID := resource.ParseServiceInstanceName(name)
+			return []interface{}{serviceName, instanceID}
+		},
+		newObject: func() interface{} { return &v1alpha1.ServiceInstance{} },
+		toResource: func(_ string, object interface{}) resource.MeshObject {
+			return resource.ToServiceInstance(object.(*v1alpha1.ServiceInstance))
+		},
+		toObject: func(mo resource.MeshObject) interface{} { return mo.(*resource.ServiceInstance).ToV1Alpha1() },
+	},
+	resource.KindLoadBalance: {
+		url:       MeshServiceLoadBalanceURL,
+		listURL:   MeshServicesURL,
+		newObject: func() interface{} { return &v1alpha1.LoadBalance{} },
+		toResource: func(name string, object interface{}) resource.MeshObject {
+			return resource.ToLoadBalance(name, object.(*v1alpha1.LoadBalance))
+		},
+		toObject: func(mo resource.MeshObject) interface{} { return mo.(*resource.LoadBalance).ToV1Alpha1() },
+		fromService: func(service *v1alpha1.Service) resource.MeshObject {
+			if service.LoadBalance == nil {
+				return nil
+			}
+			return resource.ToLoadBalance(service.Name, service.LoadBalance)
+		},
+	},
+	resource.KindCanary: {
+		url:       MeshServiceCanaryURL,
+		listURL:   MeshServicesURL,
+		newObject: func() interface{} { return &v1alpha1.Canary{} },
+		toResource: func(name string, object interface{}) resource.MeshObject {
+			return resource.ToCanary(name, object.(*v1alpha1.Canary))
+		},
+		toObject: func(mo resource.MeshObject) interface{} { return mo.(*resource.Canary).ToV1Alpha1() },
+		fromService: func(service *v1alpha1.Service) resource.MeshObject {
+			if service.Canary == nil {
+				return nil
+			}
+			return resource.ToCanary(service.Name, service.Canary)
+		},
+	},
+	resource.KindResilience: {
+		url:       MeshServiceResilienceURL,
+		listURL:   MeshServicesURL,
+		newObject: func() interface{} { return &v1alpha1.Resilience{} },
+		toResource: func(name string, object interface{}) resource.MeshObject {
+			return resource.ToResilience(name, object.(*v1alpha1.Resilience))
+		},
+		toObject: func(mo resource.MeshObject) interface{} { return mo.(*resource.Resilience).ToV1Alpha1() },
+		fromService: func(service *v1alpha1.Service) resource.MeshObject {
+			if service.Resilience == nil {
+				return nil
+			}
+			return resource.ToResilience(service.Name, service.Resilience)
+		},
+	},
+	resource.KindObservabilityTracings: {
+		url:       MeshServiceTracingsURL,
+		listURL:   MeshServicesURL,
+		newObject: func() interface{} { return &v1alpha1.ObservabilityTracings{} },
+		toResource: func(name string, object interface{}) resource.MeshObject {
+			return resource.ToObservabilityTracings(name, object.(*v1alpha1.ObservabilityTracings))
+		},
+		toObject: func(mo resource.MeshObject) interface{} { return mo.(*resource.ObservabilityTracings).ToV1Alpha1() },
+		fromService: func(service *v1alpha1.Service) resource.MeshObject {
+			if service.Observability == nil || service.Observability.Tracings == nil {
+				return nil
+			}
+			return resource.ToObservabilityTracings(service.Name, service.Observability.Tracings)
+		},
+	},
+	resource.KindObservabilityMetrics: {
+		url:       MeshServiceMetricsURL,
+		listURL:   MeshServicesURL,
+		newObject: func() interface{} { return &v1alpha1.ObservabilityMetrics{} },
+		toResource: func(name string, object interface{}) resource.MeshObject {
+			return resource.ToObservabilityMetrics(name, object.(*v1alpha1.ObservabilityMetrics))
+		},
+		toObject: func(mo resource.MeshObject) interface{} { return mo.(*resource.ObservabilityMetrics).ToV1Alpha1() },
+		fromService: func(service *v1alpha1.Service) resource.MeshObject {
+			if service.Observability == nil || service.Observability.Metrics == nil {
+				return nil
+			}
+			return resource.ToObservabilityMetrics(service.Name, service.Observability.Metrics)
+		},
+	},
+	resource.KindObservabilityOutputServer: {
+		url:       MeshServiceOutputServerURL,
+		listURL:   MeshServicesURL,
+		newObject: func() interface{} { return &v1alpha1.ObservabilityOutputServer{} },
+		toResource: func(name string, object interface{}) resource.MeshObject {
+			return resource.ToObservabilityOutputServer(name, object.(*v1alpha1.ObservabilityOutputServer))
+		},
+		toObject: func(mo resource.MeshObject) interface{} { return mo.(*resource.ObservabilityOutputServer).ToV1Alpha1() },
+		fromService: func(service *v1alpha1.Service) resource.MeshObject {
+			if service.Observability == nil || service.Observability.OutputServer == nil {
+				return nil
+			}
+			return resource.ToObservabilityOutputServer(service.Name, service.Observability.OutputServer)
+		},
+	},
+}
+
+var _ ResourceInterface = &restClient{}
+
+type resourceGetter struct {
+	client *meshClient
+}
+
+func (r *resourceGetter) Resource(kind string) ResourceInterface {
+	return r.client.resource(kind)
+}
+
+func (r *restClient) restKind() (*restKind, error) {
+	rk, exists := restKinds[r.kind]
+	if !exists {
+		return nil, errors.Errorf("kind %s is not supported", r.kind)
+	}
+	return rk, nil
+}
+
+func (r *restClient) resourceURL(rk *restKind, name string) string {
+	if rk.nameSegments == nil {
+		return fmt.Sprintf(rk.url, name)
+	}
+	return fmt.Sprintf(rk.url, rk.nameSegments(name)...)
+}
+
+// checkStatus maps the status code of the response to the error.
+func (r *restClient) checkStatus(method, url, name string, b []byte, statusCode int) error {
+	switch {
+	case statusCode >= 200 && statusCode < 300:
+		return nil
+	case statusCode == http.StatusNotFound:
+		return errors.Wrapf(NotFoundError, "%s %s %s", method, r.kind, name)
+	case statusCode == http.StatusConflict:
+		return errors.Wrapf(ConflictError, "%s %s %s", method, r.kind, name)
+	default:
+		return &StatusError{Method: method, URL: url, StatusCode: statusCode, Body: string(b)}
+	}
+}
+
+func (r *restClient) Get(ctx context.Context, name string) (resource.MeshObject, error) {
+	rk, err := r.restKind()
+	if err != nil {
+		return nil, err
+	}
+
+	url := r.resourceURL(rk, name)
+	result, err := r.client.httpJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			err := r.checkStatus(http.MethodGet, url, name, b, statusCode)
+			if err != nil {
+				return nil, err
+			}
+
+			object := rk.newObject()
+			err = json.Unmarshal(b, object)
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal data to %T", object)
+			}
+			return rk.toResource(name, object), nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.(resource.MeshObject), nil
+}
+
+func (r *restClient) Patch(ctx context.Context, mo resource.MeshObject) error {
+	rk, err := r.restKind()
+	if err != nil {
+		return err
+	}
+
+	url := r.resourceURL(rk, mo.Name())
+	_, err = r.client.httpJSON().
+		PutByContext(ctx, url, rk.toObject(mo), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			return nil, r.checkStatus(http.MethodPut, url, mo.Name(), b, statusCode)
+		})
+	return err
+}
+
+func (r *restClient) Create(ctx context.Context, mo resource.MeshObject) error {
+	rk, err := r.restKind()
+	if err != nil {
+		return err
+	}
+
+	url := r.resourceURL(rk, mo.Name())
+	_, err = r.client.httpJSON().
+		// FIXME: the standard RESTful URL of create resource is POST /v1/api/{resources} instead of POST /v1/api/{resources}/{id}.
+		// Current URL form should be corrected in the feature
+		PostByContext(ctx, url, rk.toObject(mo), nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			return nil, r.checkStatus(http.MethodPost, url, mo.Name(), b, statusCode)
+		})
+	return err
+}
+
+func (r *restClient) Delete(ctx context.Context, name string) error {
+	rk, err := r.restKind()
+	if err != nil {
+		return err
+	}
+
+	url := r.resourceURL(rk, name)
+	_, err = r.client.httpJSON().
+		DeleteByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			return nil, r.checkStatus(http.MethodDelete, url, name, b, statusCode)
+		})
+	return err
+}
+
+func (r *restClient) List(ctx context.Context) ([]resource.MeshObject, error) {
+	rk, err := r.restKind()
+	if err != nil {
+		return nil, err
+	}
+
+	url := rk.listURL
+	result, err := r.client.httpJSON().
+		GetByContext(ctx, url, nil, nil).
+		HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+			err := r.checkStatus(http.MethodGet, url, "", b, statusCode)
+			if err != nil {
+				return nil, err
+			}
+
+			if rk.fromService != nil {
+				return r.listFromServices(rk, b)
+			}
+
+			// The elements are pointers, the protobuf messages must not be copied.
+			list := reflect.New(reflect.SliceOf(reflect.TypeOf(rk.newObject())))
+			err = json.Unmarshal(b, list.Interface())
+			if err != nil {
+				return nil, errors.Wrapf(err, "unmarshal data to %s", list.Elem().Type())
+			}
+
+			results := []resource.MeshObject{}
+			for i := 0; i < list.Elem().Len(); i++ {
+				results = append(results, rk.toResource("", list.Elem().Index(i).Interface()))
+			}
+			return results, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.([]resource.MeshObject), nil
+}
+
+func (r *restClient) listFromServices(rk *restKind, b []byte) ([]resource.MeshObject, error) {
+	services := []*v1alpha1.Service{}
+	err := json.Unmarshal(b, &services)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal data to []*v1alpha1.Service")
+	}
+
+	results := []resource.MeshObject{}
+	for _, service := range services {
+		if mo := rk.fromService(service); mo != nil {
+			results = append(results, mo)
+		}
+	}
+	return results, nil
+}
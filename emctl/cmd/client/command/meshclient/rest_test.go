@@ -0,0 +1,153 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+
+	"github.com/pkg/errors"
+)
+
+func newRESTServer(t *testing.T, routes map[string]func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, exists := routes[r.Method+" "+r.URL.Path]
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		route(w, r)
+	}))
+}
+
+func writeJSON(t *testing.T, w http.ResponseWriter, v interface{}) {
+	buff, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal %T failed: %v", v, err)
+	}
+	w.Write(buff)
+}
+
+func TestRESTErrors(t *testing.T) {
+	server := newRESTServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"POST /apis/v1/mesh/services/vets/canary": func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusConflict)
+		},
+		"PUT /apis/v1/mesh/services/vets": func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("invalid spec"))
+		},
+	})
+	defer server.Close()
+
+	client := New(server.URL)
+	ctx := context.Background()
+
+	for _, kind := range resource.Kinds {
+		_, err := client.V1Alpha1().Resource(kind).Get(ctx, "missing")
+		if !IsNotFoundError(err) {
+			t.Errorf("expect NotFoundError getting %s but got: %v", kind, err)
+		}
+	}
+
+	err := client.V1Alpha1().Service().Delete(ctx, "missing")
+	if !IsNotFoundError(err) {
+		t.Errorf("expect NotFoundError deleting service but got: %v", err)
+	}
+
+	err = client.V1Alpha1().Canary().Create(ctx, resource.ToCanary("vets", &v1alpha1.Canary{}))
+	if !IsConflictError(err) {
+		t.Errorf("expect ConflictError creating canary but got: %v", err)
+	}
+
+	err = client.V1Alpha1().Service().Patch(ctx, resource.ToService(&v1alpha1.Service{Name: "vets"}))
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("expect StatusError patching service but got: %v", err)
+	}
+	if statusErr.StatusCode != http.StatusBadRequest || statusErr.Body != "invalid spec" {
+		t.Errorf("expect status code 400 and body of the response but got: %v", statusErr)
+	}
+
+	_, err = client.V1Alpha1().Resource("Unknown").List(ctx)
+	if err == nil {
+		t.Errorf("expect error for unsupported kind")
+	}
+}
+
+func TestRESTList(t *testing.T) {
+	server := newRESTServer(t, map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /apis/v1/mesh/services": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(t, w, []*v1alpha1.Service{
+				{Name: "vets", LoadBalance: &v1alpha1.LoadBalance{Policy: "random"}},
+				{Name: "visits"},
+			})
+		},
+		"GET /apis/v1/mesh/serviceinstances": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(t, w, []*v1alpha1.ServiceInstance{
+				{ServiceName: "vets", InstanceID: "vets-1"},
+				{ServiceName: "vets", InstanceID: "vets-2"},
+			})
+		},
+		"GET /apis/v1/mesh/serviceinstances/vets/vets-1": func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(t, w, &v1alpha1.ServiceInstance{ServiceName: "vets", InstanceID: "vets-1"})
+		},
+	})
+	defer server.Close()
+
+	client := New(server.URL)
+	ctx := context.Background()
+
+	services, err := client.V1Alpha1().Service().List(ctx)
+	if err != nil {
+		t.Fatalf("list services failed: %v", err)
+	}
+	if len(services) != 2 {
+		t.Errorf("expect 2 services but got %d", len(services))
+	}
+
+	loadBalances, err := client.V1Alpha1().LoadBalance().List(ctx)
+	if err != nil {
+		t.Fatalf("list load balances failed: %v", err)
+	}
+	if len(loadBalances) != 1 || loadBalances[0].Name() != "vets" || loadBalances[0].Spec.Policy != "random" {
+		t.Errorf("expect load balance of service vets but got %v", loadBalances)
+	}
+
+	instances, err := client.V1Alpha1().ServiceInstance().List(ctx)
+	if err != nil {
+		t.Fatalf("list service instances failed: %v", err)
+	}
+	if len(instances) != 2 || instances[1].Name() != "vets/vets-2" {
+		t.Errorf("expect 2 instances of service vets but got %v", instances)
+	}
+
+	instance, err := client.V1Alpha1().ServiceInstance().Get(ctx, "vets", "vets-1")
+	if err != nil {
+		t.Fatalf("get service instance failed: %v", err)
+	}
+	if instance.Name() != "vets/vets-1" {
+		t.Errorf("expect instance vets/vets-1 but got %s", instance.Name())
+	}
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+type serviceInstanceGetter struct {
+	client *meshClient
+}
+
+func (s *serviceInstanceGetter) ServiceInstance() ServiceInstanceInterface {
+	return &serviceInstanceInterface{rest: s.client.resource(resource.KindServiceInstance)}
+}
+
+var _ ServiceInstanceInterface = &serviceInstanceInterface{}
+
+type serviceInstanceInterface struct {
+	rest ResourceInterface
+}
+
+func (s *serviceInstanceInterface) Get(ctx context.Context, serviceName, instanceID string) (*resource.ServiceInstance, error) {
+	object, err := s.rest.Get(ctx, resource.ServiceInstanceName(serviceName, instanceID))
+	if err != nil {
+		return nil, err
+	}
+	return object.(*resource.ServiceInstance), nil
+}
+
+func (s *serviceInstanceInterface) Patch(ctx context.Context, instance *resource.ServiceInstance) error {
+	return s.rest.Patch(ctx, instance)
+}
+
+func (s *serviceInstanceInterface) Delete(ctx context.Context, serviceName, instanceID string) error {
+	return s.rest.Delete(ctx, resource.ServiceInstanceName(serviceName, instanceID))
+}
+
+func (s *serviceInstanceInterface) List(ctx context.Context) ([]*resource.ServiceInstance, error) {
+	objects, err := s.rest.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*resource.ServiceInstance, 0, len(objects))
+	for _, object := range objects {
+		results = append(results, object.(*resource.ServiceInstance))
+	}
+	return results, nil
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/command/meshclient/fake"
+)
+
+func TestServiceInstance(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	server.AddServiceInstance(&v1alpha1.ServiceInstance{
+		ServiceName: "vets", InstanceID: "vets-0", Ip: "10.0.0.1", Port: 13001,
+		Labels: map[string]string{"version": "v1"}, Status: "UP",
+	})
+	server.AddServiceInstance(&v1alpha1.ServiceInstance{
+		ServiceName: "vets", InstanceID: "vets-1", Ip: "10.0.0.2", Port: 13001, Status: "UP",
+	})
+
+	client := New(server.URL()).V1Alpha1().ServiceInstance()
+	ctx := context.Background()
+
+	instances, err := client.List(ctx)
+	if err != nil {
+		t.Fatalf("list service instances failed: %v", err)
+	}
+	if len(instances) != 2 {
+		t.Fatalf("expect 2 service instances but got %d", len(instances))
+	}
+	if name := instances[0].Name(); name != "vets/vets-0" {
+		t.Errorf("expect instance vets/vets-0 but got %s", name)
+	}
+	if labels := instances[0].Labels(); labels["version"] != "v1" {
+		t.Errorf("expect labels of the instance but got %v", labels)
+	}
+
+	instance, err := client.Get(ctx, "vets", "vets-0")
+	if err != nil {
+		t.Fatalf("get service instance failed: %v", err)
+	}
+	if instance.Spec.Ip != "10.0.0.1" {
+		t.Errorf("expect ip 10.0.0.1 but got %s", instance.Spec.Ip)
+	}
+
+	if _, err := client.Get(ctx, "vets", "missing"); !IsNotFoundError(err) {
+		t.Errorf("expect NotFoundError getting missing instance but got: %v", err)
+	}
+
+	// Patch puts the whole instance, the fields not changed are sent as
+	// they were got.
+	server.ResetRequests()
+	instance.Spec.Status = "OUT_OF_SERVICE"
+	if err := client.Patch(ctx, instance); err != nil {
+		t.Fatalf("patch service instance failed: %v", err)
+	}
+	requests := server.Requests()
+	if len(requests) != 1 || requests[0].Method != http.MethodPut ||
+		requests[0].Path != "/apis/v1/mesh/serviceinstances/vets/vets-0" {
+		t.Fatalf("expect PUT of the instance but got %v", requests)
+	}
+	sent := &v1alpha1.ServiceInstance{}
+	if err := json.Unmarshal(requests[0].Body, sent); err != nil {
+		t.Fatalf("unmarshal request body failed: %v", err)
+	}
+	if !reflect.DeepEqual(sent, instance.Spec) {
+		t.Errorf("expect the whole instance %+v sent but got %+v", instance.Spec, sent)
+	}
+
+	if err := client.Delete(ctx, "vets", "vets-1"); err != nil {
+		t.Fatalf("delete service instance failed: %v", err)
+	}
+	if err := client.Delete(ctx, "vets", "vets-1"); !IsNotFoundError(err) {
+		t.Errorf("expect NotFoundError deleting instance twice but got: %v", err)
+	}
+
+	instances, err = client.List(ctx)
+	if err != nil {
+		t.Fatalf("list service instances failed: %v", err)
+	}
+	if len(instances) != 1 || instances[0].Spec.Status != "OUT_OF_SERVICE" {
+		t.Errorf("expect the patched instance left but got %v", instances)
+	}
+}
@@ -47,6 +47,9 @@ type EasegressConfig struct {
 	LogDir                  string   `yaml:"log-dir" jsonschema:"required"`
 	MemberDir               string   `yaml:"member-dir" jsonschema:"required"`
 	StdLogLevel             string   `yaml:"std-log-level" jsonschema:"required"`
+	TLS                     bool     `yaml:"tls,omitempty" jsonschema:"omitempty"`
+	CertFile                string   `yaml:"cert-file,omitempty" jsonschema:"omitempty"`
+	KeyFile                 string   `yaml:"key-file,omitempty" jsonschema:"omitempty"`
 }
 
 type MeshControllerConfig struct {
@@ -64,6 +67,8 @@ type MeshOperatorConfig struct {
 	MetricsAddr          string `yaml:"metrics-bind-address" jsonschema:"required"`
 	EnableLeaderElection bool   `yaml:"leader-elect" jsonschema:"required"`
 	ProbeAddr            string `yaml:"health-probe-bind-address" jsonschema:"required"`
+	MeshServer           string `yaml:"mesh-server" jsonschema:"required"`
+	MeshServerCA         string `yaml:"mesh-server-ca,omitempty" jsonschema:"omitempty"`
 }
 
 type EasegressReaderParams struct {
@@ -38,6 +38,8 @@ const (
 	DefaultMeshControlPlanePVName     = "easegress-control-plane-pv"
 	DefaultMeshControlPlanePVHostPath = "/opt/easemesh"
 	DefaultMeshControlPlaneConfig     = "easemesh-cluster-cm"
+	DefaultMeshControlPlaneTLSSecret  = "easemesh-control-plane-tls"
+	DefaultMeshControlPlaneTLSDir     = "/opt/eg-tls"
 
 	DefaultMeshControllerName = "easemesh-controller"
 
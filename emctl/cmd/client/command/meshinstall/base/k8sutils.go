@@ -133,6 +133,18 @@ func DeployConfigMap(configMap *v1.ConfigMap, clientSet *kubernetes.Clientset, n
 		})
 }
 
+func DeploySecret(secret *v1.Secret, clientSet *kubernetes.Clientset, namespaces string) error {
+	return applyResource(
+		func() error {
+			_, err := clientSet.CoreV1().Secrets(namespaces).Create(context.TODO(), secret, metav1.CreateOptions{})
+			return err
+		},
+		func() error {
+			_, err := clientSet.CoreV1().Secrets(namespaces).Update(context.TODO(), secret, metav1.UpdateOptions{})
+			return err
+		})
+}
+
 func ListPersistentVolume(clientSet *kubernetes.Clientset) (*v1.PersistentVolumeList, error) {
 	return clientSet.CoreV1().PersistentVolumes().List(context.TODO(), metav1.ListOptions{})
 }
@@ -0,0 +1,122 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package installbase
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// TLSCAKey is the key of the certificate authority in the TLS secret
+	TLSCAKey = "ca.crt"
+	// TLSCertKey is the key of the server certificate in the TLS secret
+	TLSCertKey = "tls.crt"
+	// TLSPrivateKeyKey is the key of the server private key in the TLS secret
+	TLSPrivateKeyKey = "tls.key"
+
+	certificateValidity = 10 * 365 * 24 * time.Hour
+)
+
+// GenerateCertificates generates a self-signed certificate authority and a
+// server certificate signed by it for the DNS names and IPs, all of them
+// are PEM encoded.
+func GenerateCertificates(commonName string, dnsNames []string, ips []net.IP) (caPEM, certPEM, keyPEM []byte, err error) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "generate ca key")
+	}
+
+	notBefore := time.Now().Add(-time.Hour)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName + "-ca"},
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.Add(certificateValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "create ca certificate")
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "parse ca certificate")
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "generate server key")
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    notBefore,
+		NotAfter:     notBefore.Add(certificateValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     dnsNames,
+		IPAddresses:  ips,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "create server certificate")
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, "marshal server key")
+	}
+
+	caPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return caPEM, certPEM, keyPEM, nil
+}
+
+// GetMeshControlPlaneCA returns the PEM encoded certificate authority of the
+// control plane, it returns nil if the control plane isn't installed with TLS.
+func GetMeshControlPlaneCA(client *kubernetes.Clientset, namespace string) ([]byte, error) {
+	secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), DefaultMeshControlPlaneTLSSecret, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	caPEM, exists := secret.Data[TLSCAKey]
+	if !exists {
+		return nil, errors.Errorf("%s not found in secret %s/%s", TLSCAKey, namespace, DefaultMeshControlPlaneTLSSecret)
+	}
+	return caPEM, nil
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package installbase
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/megaease/easemeshctl/cmd/common/client"
+)
+
+func TestGenerateCertificates(t *testing.T) {
+	caPEM, certPEM, keyPEM, err := GenerateCertificates("easemesh-control-plane",
+		[]string{"localhost"}, []net.IP{net.ParseIP("127.0.0.1")})
+	if err != nil {
+		t.Fatalf("generate certificates failed: %v", err)
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("load server certificate failed: %v", err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("{}"))
+	}))
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	server.StartTLS()
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatalf("create temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	caFile := path.Join(dir, TLSCAKey)
+	if err := ioutil.WriteFile(caFile, caPEM, 0600); err != nil {
+		t.Fatalf("write ca failed: %v", err)
+	}
+
+	tlsConfig, err := client.NewTLSConfig(caFile, "", "", false)
+	if err != nil {
+		t.Fatalf("new tls config failed: %v", err)
+	}
+
+	get := func(options ...client.Option) (int, error) {
+		re, err := client.NewHTTPJSON(options...).
+			Get(server.URL, nil, 5*time.Second, nil).
+			HandleResponse(func(b []byte, statusCode int) (interface{}, error) {
+				return statusCode, nil
+			})
+		if err != nil {
+			return 0, err
+		}
+		return re.(int), nil
+	}
+
+	if _, err := get(client.WrapBearerTokenOption("secret")); err == nil {
+		t.Errorf("expect error without the certificate authority")
+	}
+
+	statusCode, err := get(client.WrapTLSOption(tlsConfig), client.WrapBearerTokenOption("secret"))
+	if err != nil || statusCode != http.StatusOK {
+		t.Errorf("expect status code 200 but got %d, error: %v", statusCode, err)
+	}
+
+	statusCode, err = get(client.WrapTLSOption(tlsConfig))
+	if err != nil || statusCode != http.StatusUnauthorized {
+		t.Errorf("expect status code 401 without token but got %d, error: %v", statusCode, err)
+	}
+}
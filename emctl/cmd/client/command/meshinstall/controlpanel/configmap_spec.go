@@ -20,6 +20,7 @@ package controlpanel
 import (
 	"encoding/json"
 	"fmt"
+	"path"
 	"strconv"
 
 	"github.com/megaease/easemeshctl/cmd/client/command/flags"
@@ -53,6 +54,12 @@ func configMapSpec(installFlags *flags.Install) installbase.InstallFunc {
 		StdLogLevel:             "INFO",
 	}
 
+	if installFlags.MeshControlPlaneTLS {
+		config.TLS = true
+		config.CertFile = path.Join(installbase.DefaultMeshControlPlaneTLSDir, installbase.TLSCertKey)
+		config.KeyFile = path.Join(installbase.DefaultMeshControlPlaneTLSDir, installbase.TLSPrivateKeyKey)
+	}
+
 	for i := 0; i < installFlags.EasegressControlPlaneReplicas; i++ {
 		config.ClusterJoinUrls = append(config.ClusterJoinUrls,
 			fmt.Sprintf("http://%s-%d.%s.%s:%d",
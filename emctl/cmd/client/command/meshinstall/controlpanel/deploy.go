@@ -42,6 +42,7 @@ func Deploy(context *installbase.StageContext) error {
 		namespaceSpec(context.Flags),
 		configMapSpec(context.Flags),
 		serviceSpec(context.Flags),
+		tlsSecretSpec(context.Flags),
 		statefulsetSpec(context.Flags),
 	}
 
@@ -117,6 +118,7 @@ func Clear(context *installbase.StageContext) error {
 		{"services", installbase.DefaultMeshControlPlanePlubicServiceName},
 		{"services", installbase.DefaultMeshControlPlaneHeadlessServiceName},
 		{"configmaps", installbase.DefaultMeshControlPlaneConfig},
+		{"secrets", installbase.DefaultMeshControlPlaneTLSSecret},
 	}
 
 	clearEaseMeshControlPanelProvision(context.Cmd, context.Client, context.Flags)
@@ -154,9 +156,7 @@ func checkEasegressControlPlaneStatus(cmd *cobra.Command, kubeClient *kubernetes
 	// Wait a fix time for the Easegress cluster to start
 	time.Sleep(time.Second * 10)
 
-	entrypoints, err := installbase.GetMeshControlPanelEntryPoints(kubeClient, installFlags.MeshNamespace,
-		installbase.DefaultMeshControlPlanePlubicServiceName,
-		installbase.DefaultMeshAdminPortName)
+	entrypoints, options, err := controlPlaneEntryPoints(kubeClient, installFlags)
 	if err != nil {
 		return errors.Wrap(err, "get mesh control plane entrypoint failed")
 	}
@@ -164,7 +164,7 @@ func checkEasegressControlPlaneStatus(cmd *cobra.Command, kubeClient *kubernetes
 	timeOutPerTry := installFlags.MeshControlPlaneCheckHealthzMaxTime / len(entrypoints)
 
 	for i := 0; i < len(entrypoints); i++ {
-		_, err := client.NewHTTPJSON(
+		_, err := client.NewHTTPJSON(append(options,
 			client.WrapRetryOptions(3, time.Second*time.Duration(timeOutPerTry)/3, func(body []byte, err error) bool {
 				if err != nil && strings.Contains(err.Error(), "connection refused") {
 					return true
@@ -177,7 +177,7 @@ func checkEasegressControlPlaneStatus(cmd *cobra.Command, kubeClient *kubernetes
 				}
 
 				return len(members) < (installFlags.EaseMeshOperatorReplicas/2 + 1)
-			})...).
+			})...)...).
 			Get(entrypoints[i]+installbase.MemberList, nil, time.Second*time.Duration(timeOutPerTry), nil).
 			HandleResponse(func(body []byte, statusCode int) (interface{}, error) {
 				if statusCode != 200 {
@@ -36,9 +36,7 @@ import (
 
 func provisionEaseMeshControlPanel(cmd *cobra.Command, kubeClient *kubernetes.Clientset, installFlags *flags.Install) error {
 
-	entrypoints, err := installbase.GetMeshControlPanelEntryPoints(kubeClient, installFlags.MeshNamespace,
-		installbase.DefaultMeshControlPlanePlubicServiceName,
-		installbase.DefaultMeshAdminPortName)
+	entrypoints, options, err := controlPlaneEntryPoints(kubeClient, installFlags)
 	if err != nil {
 		return errors.Wrap(err, "get mesh control panel entrypoint failed")
 	}
@@ -58,7 +56,7 @@ func provisionEaseMeshControlPanel(cmd *cobra.Command, kubeClient *kubernetes.Cl
 
 	for _, entrypoint := range entrypoints {
 		url := entrypoint + installbase.ObjectsURL
-		_, err = client.NewHTTPJSON().
+		_, err = client.NewHTTPJSON(options...).
 			Post(url, configBody, time.Second*5, nil).
 			HandleResponse(func(body []byte, statusCode int) (interface{}, error) {
 				if statusCode >= 400 {
@@ -76,9 +74,7 @@ func provisionEaseMeshControlPanel(cmd *cobra.Command, kubeClient *kubernetes.Cl
 
 func clearEaseMeshControlPanelProvision(cmd *cobra.Command, kubeClient *kubernetes.Clientset, installFlags *flags.Install) {
 
-	entrypoints, err := installbase.GetMeshControlPanelEntryPoints(kubeClient, installFlags.MeshNamespace,
-		installbase.DefaultMeshControlPlanePlubicServiceName,
-		installbase.DefaultMeshAdminPortName)
+	entrypoints, options, err := controlPlaneEntryPoints(kubeClient, installFlags)
 	if err != nil {
 		common.OutputErrorf("clear: get mesh control panel entrypoint failed %s", err)
 		return
@@ -86,7 +82,7 @@ func clearEaseMeshControlPanelProvision(cmd *cobra.Command, kubeClient *kubernet
 
 	for _, entrypoint := range entrypoints {
 		url := fmt.Sprintf(entrypoint+installbase.ObjectURL, installbase.DefaultMeshControllerName)
-		_, err = client.NewHTTPJSON().
+		_, err = client.NewHTTPJSON(options...).
 			Delete(url, nil, time.Second*5, nil).
 			HandleResponse(func(body []byte, statusCode int) (interface{}, error) {
 				if statusCode == http.StatusNotFound {
@@ -83,6 +83,16 @@ func baseStatefulSetSpec(fn statefulsetSpecFunc) statefulsetSpecFunc {
 				},
 			},
 		}
+		if installFlags.MeshControlPlaneTLS {
+			spec.Spec.Template.Spec.Volumes = append(spec.Spec.Template.Spec.Volumes, v1.Volume{
+				Name: installbase.DefaultMeshControlPlaneTLSSecret,
+				VolumeSource: v1.VolumeSource{
+					Secret: &v1.SecretVolumeSource{
+						SecretName: installbase.DefaultMeshControlPlaneTLSSecret,
+					},
+				},
+			})
+		}
 		return spec
 	}
 }
@@ -214,7 +224,7 @@ func (m *containerVisitor) VisitorResourceRequirements(c *v1.Container) (*v1.Res
 }
 
 func (m *containerVisitor) VisitorVolumeMounts(c *v1.Container) ([]v1.VolumeMount, error) {
-	return []v1.VolumeMount{
+	mounts := []v1.VolumeMount{
 		{
 			Name:      installbase.DefaultMeshControlPlanePVName,
 			MountPath: "/opt/eg-data/",
@@ -224,7 +234,15 @@ func (m *containerVisitor) VisitorVolumeMounts(c *v1.Container) ([]v1.VolumeMoun
 			MountPath: "/opt/eg-config/eg-master.yaml",
 			SubPath:   "eg-master.yaml",
 		},
-	}, nil
+	}
+	if m.installFlags.MeshControlPlaneTLS {
+		mounts = append(mounts, v1.VolumeMount{
+			Name:      installbase.DefaultMeshControlPlaneTLSSecret,
+			MountPath: installbase.DefaultMeshControlPlaneTLSDir,
+			ReadOnly:  true,
+		})
+	}
+	return mounts, nil
 }
 
 func (m *containerVisitor) VisitorVolumeDevices(c *v1.Container) ([]v1.VolumeDevice, error) {
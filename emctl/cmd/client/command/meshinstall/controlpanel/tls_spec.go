@@ -0,0 +1,159 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controlpanel
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	installbase "github.com/megaease/easemeshctl/cmd/client/command/meshinstall/base"
+	"github.com/megaease/easemeshctl/cmd/common/client"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// tlsSecretSpec generates the certificates of the control plane admin API,
+// the existed secret is kept so that the saved certificate authority of
+// the clients is still valid after reinstalling.
+func tlsSecretSpec(installFlags *flags.Install) installbase.InstallFunc {
+	return func(cmd *cobra.Command, kubeClient *kubernetes.Clientset, installFlags *flags.Install) error {
+		if !installFlags.MeshControlPlaneTLS {
+			return nil
+		}
+
+		namespace := installFlags.MeshNamespace
+		_, err := kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(),
+			installbase.DefaultMeshControlPlaneTLSSecret, metav1.GetOptions{})
+		if err == nil {
+			return nil
+		}
+		if !k8serrors.IsNotFound(err) {
+			return errors.Wrapf(err, "get secret %s", installbase.DefaultMeshControlPlaneTLSSecret)
+		}
+
+		dnsNames, ips, err := controlPlaneHosts(kubeClient, installFlags)
+		if err != nil {
+			return err
+		}
+
+		caPEM, certPEM, keyPEM, err := installbase.GenerateCertificates(installbase.DefaultMeshControlPlaneName, dnsNames, ips)
+		if err != nil {
+			return errors.Wrap(err, "generate certificates of mesh control plane")
+		}
+
+		secret := &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      installbase.DefaultMeshControlPlaneTLSSecret,
+				Namespace: namespace,
+			},
+			Type: v1.SecretTypeTLS,
+			Data: map[string][]byte{
+				installbase.TLSCAKey:         caPEM,
+				installbase.TLSCertKey:       certPEM,
+				installbase.TLSPrivateKeyKey: keyPEM,
+			},
+		}
+
+		return installbase.DeploySecret(secret, kubeClient, namespace)
+	}
+}
+
+// controlPlaneHosts returns the names and addresses the admin API could be
+// accessed by: services in the cluster, and node ports of the public service.
+func controlPlaneHosts(kubeClient *kubernetes.Clientset, installFlags *flags.Install) ([]string, []net.IP, error) {
+	namespace := installFlags.MeshNamespace
+	dnsNames := []string{"localhost"}
+	for _, name := range []string{installbase.DefaultMeshControlPlanePlubicServiceName, installFlags.EgServiceName} {
+		dnsNames = append(dnsNames, name, name+"."+namespace, name+"."+namespace+".svc")
+	}
+	dnsNames = append(dnsNames, fmt.Sprintf("*.%s.%s", installbase.DefaultMeshControlPlaneHeadlessServiceName, namespace))
+
+	ips := []net.IP{net.ParseIP("127.0.0.1")}
+	service, err := kubeClient.CoreV1().Services(namespace).Get(context.TODO(),
+		installbase.DefaultMeshControlPlanePlubicServiceName, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "get service %s", installbase.DefaultMeshControlPlanePlubicServiceName)
+	}
+	if ip := net.ParseIP(service.Spec.ClusterIP); ip != nil {
+		ips = append(ips, ip)
+	}
+
+	nodes, err := kubeClient.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "list nodes")
+	}
+	for _, n := range nodes.Items {
+		for _, address := range n.Status.Addresses {
+			if address.Type != v1.NodeInternalIP {
+				continue
+			}
+			if ip := net.ParseIP(address.Address); ip != nil {
+				ips = append(ips, ip)
+			}
+		}
+	}
+
+	return dnsNames, ips, nil
+}
+
+// controlPlaneEntryPoints returns entrypoints of the admin API with the
+// client options to access them, they're https if TLS is enabled.
+func controlPlaneEntryPoints(kubeClient *kubernetes.Clientset, installFlags *flags.Install) ([]string, []client.Option, error) {
+	entrypoints, err := installbase.GetMeshControlPanelEntryPoints(kubeClient, installFlags.MeshNamespace,
+		installbase.DefaultMeshControlPlanePlubicServiceName,
+		installbase.DefaultMeshAdminPortName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !installFlags.MeshControlPlaneTLS {
+		return entrypoints, nil, nil
+	}
+
+	caPEM, err := installbase.GetMeshControlPlaneCA(kubeClient, installFlags.MeshNamespace)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "get certificate authority of mesh control plane")
+	}
+	if caPEM == nil {
+		return nil, nil, errors.Errorf("secret %s/%s of mesh control plane not found",
+			installFlags.MeshNamespace, installbase.DefaultMeshControlPlaneTLSSecret)
+	}
+	pool, err := client.NewCertPool(caPEM)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "load certificate authority of mesh control plane")
+	}
+
+	for i := range entrypoints {
+		entrypoints[i] = "https://" + strings.TrimPrefix(entrypoints[i], "http://")
+	}
+	tlsConfig, err := client.NewTLSConfig("", "", "", false)
+	if err != nil {
+		return nil, nil, err
+	}
+	tlsConfig.RootCAs = pool
+
+	return entrypoints, []client.Option{client.WrapTLSOption(tlsConfig)}, nil
+}
@@ -29,6 +29,9 @@ import (
 	clientGoScheme "k8s.io/client-go/kubernetes/scheme"
 )
 
+// The CRD is copied from the one generated in the operator, keep them in sync.
+//go:generate cp ../../../../../../operator/config/crd/bases/mesh.megaease.com_meshdeployments.yaml crd.yaml
+
 //go:embed  crd.yaml
 var easemeshDeploymentCRD []byte
 
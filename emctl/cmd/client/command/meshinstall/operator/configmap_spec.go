@@ -19,6 +19,7 @@ package operator
 
 import (
 	"fmt"
+	"path"
 	"strconv"
 
 	"github.com/megaease/easemeshctl/cmd/client/command/flags"
@@ -43,6 +44,16 @@ func configMapSpec(installFlags *flags.Install) installbase.InstallFunc {
 		ProbeAddr:            ":8081",
 	}
 
+	// The operator accesses the admin API of the control plane by its
+	// service, verifying it by the certificate authority mounted from the
+	// TLS secret of the control plane.
+	scheme := "http://"
+	if installFlags.MeshControlPlaneTLS {
+		scheme = "https://"
+		cfg.MeshServerCA = path.Join(installbase.DefaultMeshControlPlaneTLSDir, installbase.TLSCAKey)
+	}
+	cfg.MeshServer = scheme + installFlags.EgServiceName + "." + installFlags.MeshNamespace + ":" + strconv.Itoa(installFlags.EgAdminPort)
+
 	configMap := &v1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      meshOperatorConfigMap,
@@ -123,6 +123,20 @@ func deploymentConfigVolumeSpec(fn deploymentSpecFunc) deploymentSpecFunc {
 				},
 			},
 		}
+		if installFlags.MeshControlPlaneTLS {
+			// Only the certificate authority is needed to verify the control plane.
+			spec.Spec.Template.Spec.Volumes = append(spec.Spec.Template.Spec.Volumes, v1.Volume{
+				Name: installbase.DefaultMeshControlPlaneTLSSecret,
+				VolumeSource: v1.VolumeSource{
+					Secret: &v1.SecretVolumeSource{
+						SecretName: installbase.DefaultMeshControlPlaneTLSSecret,
+						Items: []v1.KeyToPath{
+							{Key: installbase.TLSCAKey, Path: installbase.TLSCAKey},
+						},
+					},
+				},
+			})
+		}
 		return spec
 	}
 
@@ -201,13 +215,21 @@ func (v *containerVisitor) VisitorResourceRequirements(c *v1.Container) (*v1.Res
 
 func (v *containerVisitor) VisitorVolumeMounts(c *v1.Container) ([]v1.VolumeMount, error) {
 
-	return []v1.VolumeMount{
+	mounts := []v1.VolumeMount{
 		{
 			Name:      "config-volume",
 			MountPath: "/opt/mesh/operator-config.yaml",
 			SubPath:   "operator-config.yaml",
 		},
-	}, nil
+	}
+	if v.installFlags.MeshControlPlaneTLS {
+		mounts = append(mounts, v1.VolumeMount{
+			Name:      installbase.DefaultMeshControlPlaneTLSSecret,
+			MountPath: installbase.DefaultMeshControlPlaneTLSDir,
+			ReadOnly:  true,
+		})
+	}
+	return mounts, nil
 }
 
 func (v *containerVisitor) VisitorVolumeDevices(c *v1.Container) ([]v1.VolumeDevice, error) {
@@ -82,7 +82,22 @@ func clusterRoleSpec(installFlags *flags.Install) installbase.InstallFunc {
 			{
 				APIGroups: []string{""},
 				Resources: []string{"pods"},
-				Verbs:     []string{roleVerbGet, roleVerbList},
+				Verbs:     []string{roleVerbGet, roleVerbList, roleVerbWatch},
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"namespaces"},
+				Verbs:     []string{roleVerbGet, roleVerbList, roleVerbWatch},
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"events"},
+				Verbs:     []string{roleVerbCreate, roleVerbPatch},
+			},
+			{
+				APIGroups: []string{""},
+				Resources: []string{"configmaps", "secrets"},
+				Verbs:     []string{roleVerbGet, roleVerbList, roleVerbWatch, roleVerbDelete},
 			},
 			{
 				APIGroups: []string{"mesh.megaease.com"},
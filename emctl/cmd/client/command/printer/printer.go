@@ -20,49 +20,266 @@ package printer
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
 
 	yamljsontool "github.com/ghodss/yaml"
 	"github.com/megaease/easemeshctl/cmd/client/resource"
 	"github.com/megaease/easemeshctl/cmd/common"
 	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+const (
+	// EventAdded means the object is added
+	EventAdded EventType = "ADDED"
+	// EventModified means the object is modified
+	EventModified EventType = "MODIFIED"
+	// EventDeleted means the object is deleted
+	EventDeleted EventType = "DELETED"
+)
+
+const (
+	formatCustomColumns = "custom-columns"
+	formatJSONPath      = "jsonpath"
+	formatGoTemplate    = "go-template"
+
+	// OutputFormats describes the supported output formats
+	OutputFormats = "table, wide, yaml, json, custom-columns=<header>:<json path>[,...], jsonpath=<template>, go-template=<template>"
 )
 
 type (
 	// Printer prints information about the EaseMesh objects
 	Printer interface {
 		PrintObjects(objects []resource.MeshObject)
+		PrintEvents(events []Event)
+	}
+
+	// EventType is the type of a change of an object
+	EventType string
+
+	// Event is a change of an object observed by watching the resources
+	Event struct {
+		Type   EventType
+		Object resource.MeshObject
+	}
+
+	// Options holds the options of the printer except the output format
+	Options struct {
+		// SortBy is a JSON path expression sorting the objects, e.g. .metadata.name
+		SortBy string
+		// NoHeaders omits the headers of table, wide and custom-columns output
+		NoHeaders bool
 	}
+
 	printer struct {
 		outputFormat string
+		options      Options
+		out          io.Writer
+
+		// eventHeaderPrinted is set once the header of the events is
+		// printed, the following events are printed without it.
+		eventHeaderPrinted bool
+
+		sortBy     *jsonpath.JSONPath
+		columns    []column
+		jsonPath   *jsonpath.JSONPath
+		goTemplate *template.Template
 	}
 )
 
-// New creates a Printer
-func New(outputFormat string) Printer {
-	return &printer{outputFormat: outputFormat}
+// New creates a Printer, it fails if the output format or the options are invalid.
+func New(outputFormat string, options Options) (Printer, error) {
+	p := &printer{outputFormat: outputFormat, options: options, out: os.Stdout}
+
+	format, arg := outputFormat, ""
+	if i := strings.Index(outputFormat, "="); i != -1 {
+		format, arg = outputFormat[:i], outputFormat[i+1:]
+	}
+
+	var err error
+	switch format {
+	case "table", "wide", "json", "yaml":
+		if arg != "" {
+			err = errors.Errorf("output format %s takes no argument", format)
+		}
+	case formatCustomColumns:
+		p.columns, err = parseColumns(arg)
+	case formatJSONPath:
+		p.jsonPath, err = parseJSONPath("output", arg)
+	case formatGoTemplate:
+		p.goTemplate, err = template.New("output").Parse(arg)
+	default:
+		err = errors.Errorf("unsupported output format %s (support %s)", outputFormat, OutputFormats)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse output format %s", outputFormat)
+	}
+	p.outputFormat = format
+
+	if options.SortBy != "" {
+		p.sortBy, err = parseJSONPath("sort-by", options.SortBy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse sort-by %s", options.SortBy)
+		}
+	}
+
+	return p, nil
 }
 
 func (p *printer) PrintObjects(objects []resource.MeshObject) {
-	if len(objects) == 0 {
-		fmt.Println("No resource")
+	if len(objects) == 0 && p.jsonPath == nil && p.goTemplate == nil {
+		fmt.Fprintln(p.out, "No resource")
 		return
 	}
+
+	if p.sortBy != nil {
+		err := p.sortObjects(objects)
+		if err != nil {
+			common.ExitWithErrorf("sort objects failed: %v", err)
+		}
+	}
+
+	var err error
 	switch p.outputFormat {
-	case "table":
-		p.printTable(objects)
+	case "table", "wide", formatCustomColumns:
+		var header []string
+		var rows [][]string
+		header, rows, err = p.tableRows(objects)
+		if p.options.NoHeaders {
+			header = nil
+		}
+		p.renderTable(header, rows)
 	case "json":
 		p.printJSON(objects)
 	case "yaml":
 		p.printYAML(objects)
+	case formatJSONPath:
+		err = p.printJSONPath(objects)
+	case formatGoTemplate:
+		err = p.printGoTemplate(objects)
 	default:
 		common.ExitWithErrorf("unsupported output format: %s", p.outputFormat)
 	}
+
+	if err != nil {
+		common.ExitWithErrorf("print objects failed: %v", err)
+	}
+}
+
+// PrintEvents prints the events observed by watching the resources. The
+// table, wide and custom-columns output print the events as rows with an
+// additional Event column, and print the header only once. The other output
+// formats print every event in form of {"type": ..., "object": ...}.
+func (p *printer) PrintEvents(events []Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	var err error
+	switch p.outputFormat {
+	case "table", "wide", formatCustomColumns:
+		err = p.printEventTable(events)
+	default:
+		err = p.printEventDocuments(events)
+	}
+
+	if err != nil {
+		common.ExitWithErrorf("print events failed: %v", err)
+	}
 }
 
-func (p *printer) printTable(objects []resource.MeshObject) {
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Kind", "Name", "Labels"})
+func (p *printer) printEventTable(events []Event) error {
+	objects := make([]resource.MeshObject, len(events))
+	for i, event := range events {
+		objects[i] = event.Object
+	}
+
+	header, rows, err := p.tableRows(objects)
+	if err != nil {
+		return err
+	}
+
+	header = append([]string{"Event"}, header...)
+	if p.options.NoHeaders || p.eventHeaderPrinted {
+		header = nil
+	}
+	p.eventHeaderPrinted = true
+
+	for i := range rows {
+		rows[i] = append([]string{string(events[i].Type)}, rows[i]...)
+	}
+	p.renderTable(header, rows)
+
+	return nil
+}
+
+func (p *printer) printEventDocuments(events []Event) error {
+	for _, event := range events {
+		object, err := toGeneric(event.Object)
+		if err != nil {
+			return err
+		}
+		data := map[string]interface{}{"type": event.Type, "object": object}
+
+		switch p.outputFormat {
+		case "json":
+			buff, err := json.MarshalIndent(data, "", "  ")
+			if err != nil {
+				return errors.Wrap(err, "marshal event to json")
+			}
+			fmt.Fprintf(p.out, "%s\n", buff)
+		case "yaml":
+			buff, err := yamljsontool.Marshal(data)
+			if err != nil {
+				return errors.Wrap(err, "marshal event to yaml")
+			}
+			fmt.Fprintf(p.out, "---\n%s", buff)
+		case formatJSONPath:
+			err = p.jsonPath.Execute(p.out, data)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(p.out)
+		case formatGoTemplate:
+			err = p.goTemplate.Execute(p.out, data)
+			if err != nil {
+				return err
+			}
+		default:
+			return errors.Errorf("unsupported output format: %s", p.outputFormat)
+		}
+	}
+
+	return nil
+}
+
+// tableRows returns the header and the rows of the objects in the table,
+// wide or custom-columns output.
+func (p *printer) tableRows(objects []resource.MeshObject) ([]string, [][]string, error) {
+	switch {
+	case p.outputFormat == formatCustomColumns:
+		return p.customColumnRows(objects)
+	case objects[0].Kind() == resource.KindServiceInstance:
+		header, rows := serviceInstanceRows(objects, p.outputFormat == "wide")
+		return header, rows, nil
+	default:
+		header, rows := objectRows(objects, p.outputFormat == "wide")
+		return header, rows, nil
+	}
+}
+
+// renderTable prints the rows as a table, the header is omitted if it's nil.
+func (p *printer) renderTable(header []string, rows [][]string) {
+	table := tablewriter.NewWriter(p.out)
+	if header != nil {
+		table.SetHeader(header)
+	}
 
 	table.SetBorder(false)
 	table.SetRowLine(false)
@@ -70,20 +287,76 @@ func (p *printer) printTable(objects []resource.MeshObject) {
 	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
 	table.SetHeaderLine(false)
 	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoWrapText(false)
 
+	table.AppendBulk(rows)
+	table.Render()
+}
+
+func objectRows(objects []resource.MeshObject, wide bool) ([]string, [][]string) {
+	header := []string{"Kind", "Name", "Labels"}
+	columns, hasWideColumns := wideColumns[objects[0].Kind()]
+	if wide && hasWideColumns {
+		header = append(header, columns.header...)
+	}
+
+	var rows [][]string
 	for _, object := range objects {
-		var labels string
-		for k, v := range object.Labels() {
-			labels += k + "=" + v
-		}
-		table.Append([]string{
+		row := []string{
 			object.Kind(),
 			object.Name(),
-			labels,
-		})
+			formatLabels(object.Labels()),
+		}
+		if wide && hasWideColumns {
+			row = append(row, columns.values(object)...)
+		}
+		rows = append(rows, row)
 	}
 
-	table.Render()
+	return header, rows
+}
+
+func serviceInstanceRows(objects []resource.MeshObject, wide bool) ([]string, [][]string) {
+	header := []string{"Service", "Instance ID", "IP", "Port", "Status", "Registry Time"}
+	if wide {
+		header = append(header, "Labels")
+	}
+
+	var rows [][]string
+	for _, object := range objects {
+		row := make([]string, len(header))
+		instance, ok := object.(*resource.ServiceInstance)
+		if ok && instance.Spec != nil {
+			row = []string{
+				instance.Spec.ServiceName,
+				instance.Spec.InstanceID,
+				instance.Spec.Ip,
+				strconv.Itoa(int(instance.Spec.Port)),
+				instance.Spec.Status,
+				instance.Spec.RegistryTime,
+			}
+			if wide {
+				row = append(row, formatLabels(instance.Spec.Labels))
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return header, rows
+}
+
+func formatLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+labels[k])
+	}
+	return strings.Join(pairs, ",")
 }
 
 func (p *printer) printYAML(objects []resource.MeshObject) {
@@ -97,7 +370,7 @@ func (p *printer) printYAML(objects []resource.MeshObject) {
 		common.ExitWithErrorf("transform yaml %s to json failed: %v", yamlBuff, err)
 	}
 
-	fmt.Printf("%s", yamlBuff)
+	fmt.Fprintf(p.out, "%s", yamlBuff)
 }
 
 func (p *printer) printJSON(objects []resource.MeshObject) {
@@ -106,5 +379,5 @@ func (p *printer) printJSON(objects []resource.MeshObject) {
 		common.ExitWithErrorf("unmarshal %#v to json failed: %v", objects, err)
 	}
 
-	fmt.Printf("%s\n", prettyJSONBuff)
+	fmt.Fprintf(p.out, "%s\n", prettyJSONBuff)
 }
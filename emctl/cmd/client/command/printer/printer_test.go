@@ -0,0 +1,215 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+func testServices() []resource.MeshObject {
+	return []resource.MeshObject{
+		resource.ToService(&v1alpha1.Service{
+			Name:           "vets",
+			RegisterTenant: "pet",
+			Sidecar:        &v1alpha1.Sidecar{DiscoveryType: "eureka", IngressPort: 13001, EgressPort: 13002},
+		}),
+		resource.ToService(&v1alpha1.Service{
+			Name:           "api-gateway",
+			RegisterTenant: "gateway",
+			Sidecar:        &v1alpha1.Sidecar{DiscoveryType: "nacos", IngressPort: 13011, EgressPort: 13012},
+		}),
+	}
+}
+
+func printObjects(t *testing.T, outputFormat string, options Options, objects []resource.MeshObject) string {
+	p, err := New(outputFormat, options)
+	if err != nil {
+		t.Fatalf("new printer of %s failed: %v", outputFormat, err)
+	}
+	buff := &bytes.Buffer{}
+	p.(*printer).out = buff
+	p.PrintObjects(objects)
+	return buff.String()
+}
+
+// lines returns the lines of the output without the alignment.
+func lines(output string) []string {
+	var result []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		result = append(result, strings.Join(strings.Fields(line), " "))
+	}
+	return result
+}
+
+func TestPrinter(t *testing.T) {
+	tests := []struct {
+		name         string
+		outputFormat string
+		options      Options
+		expected     []string
+	}{
+		{
+			name:         "wide",
+			outputFormat: "wide",
+			expected: []string{
+				"KIND NAME LABELS TENANT DISCOVERY TYPE INGRESS PORT EGRESS PORT",
+				"Service vets pet eureka 13001 13002",
+				"Service api-gateway gateway nacos 13011 13012",
+			},
+		},
+		{
+			name:         "custom-columns-sort-by-no-headers",
+			outputFormat: "custom-columns=NAME:.metadata.name,PORT:.spec.sidecar.ingressPort,CANARY:.spec.canary",
+			options:      Options{SortBy: "{.metadata.name}", NoHeaders: true},
+			expected: []string{
+				"api-gateway 13011 <none>",
+				"vets 13001 <none>",
+			},
+		},
+		{
+			name:         "sort-by-number",
+			outputFormat: "custom-columns=NAME:.metadata.name",
+			options:      Options{SortBy: ".spec.sidecar.egressPort"},
+			expected:     []string{"NAME", "vets", "api-gateway"},
+		},
+		{
+			name:         "jsonpath",
+			outputFormat: "jsonpath={.items[*].metadata.name}",
+			expected:     []string{"vets api-gateway"},
+		},
+		{
+			name:         "go-template",
+			outputFormat: `go-template={{range .items}}{{.metadata.name}}={{.spec.registerTenant}}{{"\n"}}{{end}}`,
+			expected:     []string{"vets=pet", "api-gateway=gateway"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := lines(printObjects(t, tt.outputFormat, tt.options, testServices()))
+			if strings.Join(output, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("expect output:\n%s\nbut got:\n%s", strings.Join(tt.expected, "\n"), strings.Join(output, "\n"))
+			}
+		})
+	}
+}
+
+func TestPrinterWideColumns(t *testing.T) {
+	objects := []resource.MeshObject{
+		resource.ToLoadBalance("vets", &v1alpha1.LoadBalance{Policy: "headerHash", HeaderHashKey: "X-User"}),
+	}
+	output := lines(printObjects(t, "wide", Options{NoHeaders: true}, objects))
+	if len(output) != 1 || output[0] != "LoadBalance vets headerHash X-User" {
+		t.Errorf("expect wide columns of load balance but got %v", output)
+	}
+
+	objects = []resource.MeshObject{
+		resource.ToCanary("vets", &v1alpha1.Canary{CanaryRules: []*v1alpha1.CanaryRule{{}, {}}}),
+	}
+	output = lines(printObjects(t, "wide", Options{NoHeaders: true}, objects))
+	if len(output) != 1 || output[0] != "Canary vets 2" {
+		t.Errorf("expect rule count of canary but got %v", output)
+	}
+}
+
+func TestNewPrinterErrors(t *testing.T) {
+	for _, outputFormat := range []string{
+		"xml",
+		"table=name",
+		"custom-columns=",
+		"custom-columns=NAME",
+		"jsonpath={.items[",
+		"go-template={{.items",
+	} {
+		if _, err := New(outputFormat, Options{}); err == nil {
+			t.Errorf("expect error for output format %s", outputFormat)
+		}
+	}
+
+	if _, err := New("table", Options{SortBy: "{.metadata"}); err == nil {
+		t.Errorf("expect error for invalid sort-by")
+	}
+}
+
+func TestPrintEvents(t *testing.T) {
+	services := testServices()
+	batches := [][]Event{
+		{{Type: EventAdded, Object: services[0]}, {Type: EventAdded, Object: services[1]}},
+		{{Type: EventDeleted, Object: services[1]}},
+	}
+
+	tests := []struct {
+		outputFormat string
+		expected     []string
+	}{
+		{
+			outputFormat: "table",
+			expected: []string{
+				"EVENT KIND NAME LABELS",
+				"ADDED Service vets",
+				"ADDED Service api-gateway",
+				"DELETED Service api-gateway",
+			},
+		},
+		{
+			outputFormat: "custom-columns=NAME:.metadata.name",
+			expected:     []string{"EVENT NAME", "ADDED vets", "ADDED api-gateway", "DELETED api-gateway"},
+		},
+		{
+			outputFormat: "jsonpath={.type} {.object.metadata.name}",
+			expected:     []string{"ADDED vets", "ADDED api-gateway", "DELETED api-gateway"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.outputFormat, func(t *testing.T) {
+			p, err := New(tt.outputFormat, Options{})
+			if err != nil {
+				t.Fatalf("new printer of %s failed: %v", tt.outputFormat, err)
+			}
+			buff := &bytes.Buffer{}
+			p.(*printer).out = buff
+			for _, events := range batches {
+				p.PrintEvents(events)
+			}
+
+			output := lines(buff.String())
+			if strings.Join(output, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("expect output:\n%s\nbut got:\n%s", strings.Join(tt.expected, "\n"), strings.Join(output, "\n"))
+			}
+		})
+	}
+
+	p, _ := New("json", Options{})
+	buff := &bytes.Buffer{}
+	p.(*printer).out = buff
+	p.PrintEvents(batches[1])
+	event := map[string]interface{}{}
+	if err := json.Unmarshal(buff.Bytes(), &event); err != nil {
+		t.Fatalf("unmarshal event %s failed: %v", buff, err)
+	}
+	if event["type"] != "DELETED" || event["object"].(map[string]interface{})["kind"] != "Service" {
+		t.Errorf("expect deleted service event but got %s", buff)
+	}
+}
@@ -0,0 +1,267 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// column is a column of the custom-columns output
+type column struct {
+	header   string
+	jsonPath *jsonpath.JSONPath
+}
+
+// parseJSONPath parses the JSON path expression, the braces could be
+// omitted, e.g. .metadata.name is the same with {.metadata.name}.
+func parseJSONPath(name, expression string) (*jsonpath.JSONPath, error) {
+	if expression == "" {
+		return nil, errors.Errorf("empty json path")
+	}
+	if !strings.Contains(expression, "{") {
+		expression = "{" + expression + "}"
+	}
+
+	j := jsonpath.New(name)
+	j.AllowMissingKeys(true)
+	err := j.Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// parseColumns parses the spec of custom columns in form of
+// <header>:<json path>[,<header>:<json path>...]
+func parseColumns(spec string) ([]column, error) {
+	if spec == "" {
+		return nil, errors.Errorf("no column specified")
+	}
+
+	var columns []column
+	for i, c := range strings.Split(spec, ",") {
+		parts := strings.SplitN(c, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, errors.Errorf("column %s is not in form of <header>:<json path>", c)
+		}
+
+		j, err := parseJSONPath(fmt.Sprintf("column%d", i), parts[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse column %s", parts[0])
+		}
+		columns = append(columns, column{header: parts[0], jsonPath: j})
+	}
+	return columns, nil
+}
+
+// toGeneric converts the object to the form of the unmarshalled JSON,
+// so that the JSON paths and templates use the same fields with -o json.
+func toGeneric(v interface{}) (interface{}, error) {
+	buff, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrapf(err, "marshal %T to json", v)
+	}
+
+	var generic interface{}
+	err = json.Unmarshal(buff, &generic)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshal %s", buff)
+	}
+	return generic, nil
+}
+
+// toList converts the objects to a list, which is the data of the jsonpath
+// and go-template output, e.g. {.items[*].metadata.name}.
+func toList(objects []resource.MeshObject) (interface{}, error) {
+	items, err := toGeneric(objects)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []interface{}{}
+	}
+	return map[string]interface{}{"kind": "List", "items": items}, nil
+}
+
+func findValues(j *jsonpath.JSONPath, data interface{}) ([]interface{}, error) {
+	results, err := j.FindResults(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []interface{}
+	for _, result := range results {
+		for _, r := range result {
+			if r.IsValid() && r.CanInterface() {
+				values = append(values, r.Interface())
+			}
+		}
+	}
+	return values, nil
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "<none>"
+	case string:
+		return v
+	case map[string]interface{}, []interface{}:
+		buff, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(buff)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func (p *printer) customColumnRows(objects []resource.MeshObject) ([]string, [][]string, error) {
+	header := []string{}
+	for _, c := range p.columns {
+		header = append(header, c.header)
+	}
+
+	var rows [][]string
+	for _, object := range objects {
+		data, err := toGeneric(object)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		row := []string{}
+		for _, c := range p.columns {
+			values, err := findValues(c.jsonPath, data)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "find column %s of %s/%s", c.header, object.Kind(), object.Name())
+			}
+			if len(values) == 0 {
+				row = append(row, "<none>")
+				continue
+			}
+
+			cells := []string{}
+			for _, v := range values {
+				cells = append(cells, formatValue(v))
+			}
+			row = append(row, strings.Join(cells, ","))
+		}
+		rows = append(rows, row)
+	}
+
+	return header, rows, nil
+}
+
+func (p *printer) printJSONPath(objects []resource.MeshObject) error {
+	data, err := toList(objects)
+	if err != nil {
+		return err
+	}
+
+	err = p.jsonPath.Execute(p.out, data)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(p.out)
+	return nil
+}
+
+func (p *printer) printGoTemplate(objects []resource.MeshObject) error {
+	data, err := toList(objects)
+	if err != nil {
+		return err
+	}
+
+	return p.goTemplate.Execute(p.out, data)
+}
+
+// sortObjects sorts the objects by the value of the sort-by JSON path, the
+// numbers are compared numerically, the objects without the value are the
+// first ones.
+func (p *printer) sortObjects(objects []resource.MeshObject) error {
+	keys := make([]interface{}, len(objects))
+	for i, object := range objects {
+		data, err := toGeneric(object)
+		if err != nil {
+			return err
+		}
+
+		values, err := findValues(p.sortBy, data)
+		if err != nil {
+			return errors.Wrapf(err, "find sort-by value of %s/%s", object.Kind(), object.Name())
+		}
+		if len(values) > 1 {
+			return errors.Errorf("sort-by %s of %s/%s has %d values, expect one", p.options.SortBy,
+				object.Kind(), object.Name(), len(values))
+		}
+		if len(values) == 1 {
+			keys[i] = values[0]
+		}
+	}
+
+	indexes := make([]int, len(objects))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return lessValue(keys[indexes[i]], keys[indexes[j]])
+	})
+
+	sorted := make([]resource.MeshObject, len(objects))
+	for i, index := range indexes {
+		sorted[i] = objects[index]
+	}
+	copy(objects, sorted)
+
+	return nil
+}
+
+func lessValue(a, b interface{}) bool {
+	switch {
+	case a == nil:
+		return b != nil
+	case b == nil:
+		return false
+	}
+
+	af, aIsNumber := a.(float64)
+	bf, bIsNumber := b.(float64)
+	if aIsNumber && bIsNumber {
+		return af < bf
+	}
+
+	if reflect.TypeOf(a) == reflect.TypeOf(b) {
+		if as, ok := a.(string); ok {
+			return as < b.(string)
+		}
+		if ab, ok := a.(bool); ok {
+			return !ab && b.(bool)
+		}
+	}
+	return formatValue(a) < formatValue(b)
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package printer
+
+import (
+	"strconv"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+// kindColumns are the extra columns of a kind in the wide output
+type kindColumns struct {
+	header []string
+	values func(object resource.MeshObject) []string
+}
+
+var wideColumns = map[string]kindColumns{
+	resource.KindService: {
+		header: []string{"Tenant", "Discovery Type", "Ingress Port", "Egress Port"},
+		values: func(object resource.MeshObject) []string {
+			service := object.(*resource.Service)
+			if service.Spec == nil {
+				return []string{"", "", "", ""}
+			}
+			if service.Spec.Sidecar == nil {
+				return []string{service.Spec.RegisterTenant, "", "", ""}
+			}
+			return []string{
+				service.Spec.RegisterTenant,
+				service.Spec.Sidecar.DiscoveryType,
+				strconv.Itoa(int(service.Spec.Sidecar.IngressPort)),
+				strconv.Itoa(int(service.Spec.Sidecar.EgressPort)),
+			}
+		},
+	},
+	resource.KindLoadBalance: {
+		header: []string{"Policy", "Header Hash Key"},
+		values: func(object resource.MeshObject) []string {
+			lb := object.(*resource.LoadBalance)
+			if lb.Spec == nil {
+				return []string{"", ""}
+			}
+			return []string{lb.Spec.Policy, lb.Spec.HeaderHashKey}
+		},
+	},
+	resource.KindCanary: {
+		header: []string{"Rules"},
+		values: func(object resource.MeshObject) []string {
+			canary := object.(*resource.Canary)
+			if canary.Spec == nil {
+				return []string{"0"}
+			}
+			return []string{strconv.Itoa(len(canary.Spec.CanaryRules))}
+		},
+	},
+	resource.KindIngress: {
+		header: []string{"Rules"},
+		values: func(object resource.MeshObject) []string {
+			ingress := object.(*resource.Ingress)
+			if ingress.Spec == nil {
+				return []string{"0"}
+			}
+			return []string{strconv.Itoa(len(ingress.Spec.Rules))}
+		},
+	},
+	resource.KindTenant: {
+		header: []string{"Services", "Description"},
+		values: func(object resource.MeshObject) []string {
+			tenant := object.(*resource.Tenant)
+			if tenant.Spec == nil {
+				return []string{"0", ""}
+			}
+			return []string{strconv.Itoa(len(tenant.Spec.Services)), tenant.Spec.Description}
+		},
+	},
+}
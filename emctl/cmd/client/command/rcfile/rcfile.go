@@ -1,9 +1,27 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
 package rcfile
 
 import (
 	"io/ioutil"
 	"os"
 	"path"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -11,17 +29,53 @@ import (
 )
 
 type (
+	// RCFile holds the named contexts of the EaseMesh control planes, it's
+	// a kubeconfig-style file with the current context used by default.
 	RCFile struct {
-		Server string `yaml:"server"`
+		// Server is the single control plane address written by the old
+		// versions, it's migrated to the default context in Unmarshal.
+		Server string `yaml:"server,omitempty"`
+
+		CurrentContext string     `yaml:"current-context"`
+		Contexts       []*Context `yaml:"contexts"`
 
 		path string
 	}
+
+	// Context describes how to access an EaseMesh control plane
+	Context struct {
+		Name    string   `yaml:"name"`
+		Server  string   `yaml:"server"`
+		Timeout Duration `yaml:"timeout,omitempty"`
+
+		CertificateAuthority  string `yaml:"certificate-authority,omitempty"`
+		ClientCertificate     string `yaml:"client-certificate,omitempty"`
+		ClientKey             string `yaml:"client-key,omitempty"`
+		InsecureSkipTLSVerify bool   `yaml:"insecure-skip-tls-verify,omitempty"`
+
+		Token    string `yaml:"token,omitempty"`
+		Username string `yaml:"username,omitempty"`
+		Password string `yaml:"password,omitempty"`
+
+		// Namespace is the kubernetes namespace the EaseMesh is installed in
+		Namespace string `yaml:"namespace,omitempty"`
+		// Tenant is the default tenant of the Services registering to no tenant
+		Tenant string `yaml:"tenant,omitempty"`
+	}
+
+	// Duration is a time.Duration written as a duration string such as 30s
+	Duration time.Duration
 )
 
 const (
 	rcfileName = ".emctlrc"
+
+	// DefaultContextName is the name of the context migrated from the old
+	// rcfile or created by the installation without specifying a context
+	DefaultContextName = "default"
 )
 
+// New creates a RCFile located in the home directory of the user
 func New() (*RCFile, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -35,24 +89,44 @@ func New() (*RCFile, error) {
 	}, nil
 }
 
+// Path returns the path of the rcfile
 func (r *RCFile) Path() string {
 	return r.path
 }
 
+// Marshal writes the rcfile
 func (r *RCFile) Marshal() error {
 	buff, err := yaml.Marshal(r)
 	if err != nil {
 		return errors.Wrapf(err, "marshal %+v to yaml failed", r)
 	}
 
-	err = ioutil.WriteFile(r.path, buff, 0644)
+	// The rcfile keeps credentials, write it to a temporary file, which is
+	// created with mode 0600, then rename it to replace the old one with a
+	// looser mode written by the old versions.
+	f, err := ioutil.TempFile(path.Dir(r.path), rcfileName+"-*")
+	if err != nil {
+		return errors.Wrapf(err, "create temporary file for %s failed", r.path)
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(buff)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrapf(err, "write file %s failed", f.Name())
+	}
+
+	err = os.Rename(f.Name(), r.path)
 	if err != nil {
-		return errors.Wrapf(err, "write file %s failed", r.path)
+		return errors.Wrapf(err, "rename %s to %s failed", f.Name(), r.path)
 	}
 
 	return nil
 }
 
+// Unmarshal reads the rcfile
 func (r *RCFile) Unmarshal() error {
 	buff, err := ioutil.ReadFile(r.path)
 	if err != nil {
@@ -64,5 +138,105 @@ func (r *RCFile) Unmarshal() error {
 		return errors.Wrapf(err, "unmarshal %s to yaml failed", buff)
 	}
 
+	r.migrate()
+
+	return nil
+}
+
+func (r *RCFile) migrate() {
+	if r.Server == "" {
+		return
+	}
+
+	if r.GetContext(DefaultContextName) == nil {
+		r.SetContext(&Context{Name: DefaultContextName, Server: r.Server})
+	}
+	if r.CurrentContext == "" {
+		r.CurrentContext = DefaultContextName
+	}
+	r.Server = ""
+}
+
+// GetContext returns the context with the name, nil if not found
+func (r *RCFile) GetContext(name string) *Context {
+	for _, c := range r.Contexts {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Current returns the current context, nil if there's no current context
+func (r *RCFile) Current() *Context {
+	if r.CurrentContext == "" {
+		return nil
+	}
+	return r.GetContext(r.CurrentContext)
+}
+
+// SetContext adds the context or replaces the one with the same name
+func (r *RCFile) SetContext(context *Context) {
+	for i, c := range r.Contexts {
+		if c.Name == context.Name {
+			r.Contexts[i] = context
+			return
+		}
+	}
+	r.Contexts = append(r.Contexts, context)
+}
+
+// UseContext sets the current context
+func (r *RCFile) UseContext(name string) error {
+	if r.GetContext(name) == nil {
+		return errors.Errorf("context %s not found", name)
+	}
+	r.CurrentContext = name
+	return nil
+}
+
+// DeleteContext deletes the context, the current context is unset if it's deleted
+func (r *RCFile) DeleteContext(name string) error {
+	for i, c := range r.Contexts {
+		if c.Name == name {
+			r.Contexts = append(r.Contexts[:i], r.Contexts[i+1:]...)
+			if r.CurrentContext == name {
+				r.CurrentContext = ""
+			}
+			return nil
+		}
+	}
+	return errors.Errorf("context %s not found", name)
+}
+
+// String returns the duration string
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// MarshalYAML writes the duration as a duration string
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return d.String(), nil
+}
+
+// UnmarshalYAML reads the duration string, or the integer nanoseconds
+// written by the old versions.
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var nanoseconds int64
+	if err := unmarshal(&nanoseconds); err == nil {
+		*d = Duration(nanoseconds)
+		return nil
+	}
+
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return errors.Wrapf(err, "parse duration %q failed", s)
+	}
+	*d = Duration(duration)
+
 	return nil
 }
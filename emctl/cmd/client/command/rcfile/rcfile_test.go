@@ -0,0 +1,147 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rcfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUnmarshalMigrate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rcfile")
+	if err != nil {
+		t.Fatalf("create temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := &RCFile{path: path.Join(dir, rcfileName)}
+	err = ioutil.WriteFile(r.path, []byte("server: 10.0.0.1:2381\n"), 0600)
+	if err != nil {
+		t.Fatalf("write rcfile failed: %v", err)
+	}
+
+	if err := r.Unmarshal(); err != nil {
+		t.Fatalf("unmarshal rcfile failed: %v", err)
+	}
+
+	current := r.Current()
+	if current == nil || current.Name != DefaultContextName || current.Server != "10.0.0.1:2381" {
+		t.Fatalf("expect migrated default context but got %+v", current)
+	}
+	if r.Server != "" {
+		t.Errorf("expect legacy server cleared but got %s", r.Server)
+	}
+}
+
+func TestContexts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rcfile")
+	if err != nil {
+		t.Fatalf("create temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := &RCFile{path: path.Join(dir, rcfileName)}
+	r.SetContext(&Context{Name: "dev", Server: "dev:2381"})
+	r.SetContext(&Context{Name: "prod", Server: "prod:2381", Timeout: Duration(10 * time.Second), Tenant: "pet"})
+	r.SetContext(&Context{Name: "dev", Server: "dev2:2381"})
+	if err := r.UseContext("prod"); err != nil {
+		t.Fatalf("use context failed: %v", err)
+	}
+	if err := r.UseContext("staging"); err == nil {
+		t.Errorf("expect error for unknown context")
+	}
+
+	// The old versions wrote the rcfile with mode 0644.
+	if err := ioutil.WriteFile(r.path, nil, 0644); err != nil {
+		t.Fatalf("write rcfile failed: %v", err)
+	}
+	if err := r.Marshal(); err != nil {
+		t.Fatalf("marshal rcfile failed: %v", err)
+	}
+	info, err := os.Stat(r.path)
+	if err != nil {
+		t.Fatalf("stat rcfile failed: %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("expect mode 0600 but got %o", mode)
+	}
+	buff, err := ioutil.ReadFile(r.path)
+	if err != nil {
+		t.Fatalf("read rcfile failed: %v", err)
+	}
+	if !strings.Contains(string(buff), "timeout: 10s") {
+		t.Errorf("expect timeout written as duration string but got:\n%s", buff)
+	}
+
+	loaded := &RCFile{path: r.path}
+	if err := loaded.Unmarshal(); err != nil {
+		t.Fatalf("unmarshal rcfile failed: %v", err)
+	}
+
+	if len(loaded.Contexts) != 2 || loaded.GetContext("dev").Server != "dev2:2381" {
+		t.Fatalf("unexpected contexts: %+v", loaded.Contexts)
+	}
+	if current := loaded.Current(); current.Name != "prod" || current.Timeout != Duration(10*time.Second) || current.Tenant != "pet" {
+		t.Fatalf("unexpected current context: %+v", current)
+	}
+
+	if err := loaded.DeleteContext("prod"); err != nil {
+		t.Fatalf("delete context failed: %v", err)
+	}
+	if loaded.Current() != nil || loaded.CurrentContext != "" {
+		t.Errorf("expect current context unset but got %s", loaded.CurrentContext)
+	}
+}
+
+func TestUnmarshalTimeout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rcfile")
+	if err != nil {
+		t.Fatalf("create temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The old versions wrote the timeout as integer nanoseconds.
+	file := path.Join(dir, rcfileName)
+	content := `current-context: dev
+contexts:
+- name: dev
+  server: dev:2381
+  timeout: 1m30s
+- name: legacy
+  server: legacy:2381
+  timeout: 10000000000
+`
+	if err := ioutil.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatalf("write rcfile failed: %v", err)
+	}
+
+	r := &RCFile{path: file}
+	if err := r.Unmarshal(); err != nil {
+		t.Fatalf("unmarshal rcfile failed: %v", err)
+	}
+	if timeout := r.GetContext("dev").Timeout; timeout != Duration(90*time.Second) {
+		t.Errorf("expect timeout 1m30s but got %s", timeout)
+	}
+	if timeout := r.GetContext("legacy").Timeout; timeout != Duration(10*time.Second) {
+		t.Errorf("expect timeout 10s but got %s", timeout)
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	yamljsontool "github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+)
+
+// Run is the entrypoint of the emctl schema sub command
+func Run(cmd *cobra.Command, flags *flags.Schema) {
+	switch flags.OutputFormat {
+	case "json", "yaml":
+	default:
+		common.ExitWithErrorf("unsupported output format %s (support json, yaml)", flags.OutputFormat)
+	}
+
+	var schema resource.JSONSchema
+	var err error
+
+	cmdArgs := cmd.Flags().Args()
+	switch len(cmdArgs) {
+	case 0:
+		schema, err = resource.NewAllJSONSchema()
+	case 1:
+		schema, err = resource.NewJSONSchema(adaptKind(cmdArgs[0]))
+	default:
+		common.ExitWithErrorf("invalid command args: support [resource kind]")
+	}
+
+	if err != nil {
+		common.ExitWithErrorf("generate schema failed: %v (support %s)", err, strings.Join(resource.Kinds, ", "))
+	}
+
+	jsonBuff, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		common.ExitWithErrorf("marshal schema to json failed: %v", err)
+	}
+
+	if flags.OutputFormat == "json" {
+		fmt.Printf("%s\n", jsonBuff)
+		return
+	}
+
+	yamlBuff, err := yamljsontool.JSONToYAML(jsonBuff)
+	if err != nil {
+		common.ExitWithErrorf("transform json to yaml failed: %v", err)
+	}
+	fmt.Printf("%s", yamlBuff)
+}
+
+// adaptKind makes the kind case-insensitive in command line
+func adaptKind(kind string) string {
+	for _, k := range resource.Kinds {
+		if strings.EqualFold(k, kind) {
+			return k
+		}
+	}
+	return kind
+}
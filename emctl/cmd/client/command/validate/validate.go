@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package validate
+
+import (
+	"fmt"
+
+	"github.com/megaease/easemeshctl/cmd/client/command/flags"
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+	"github.com/megaease/easemeshctl/cmd/client/util"
+	"github.com/megaease/easemeshctl/cmd/common"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// Run is the entrypoint of the emctl validate sub command, it validates
+// resources offline without contacting the control plane.
+func Run(cmd *cobra.Command, flags *flags.Validate) {
+	if flags.YamlFile == "" {
+		common.ExitWithErrorf("no resource specified")
+	}
+
+	vss, err := util.NewVisitorBuilder().
+		DefaultTenant(flags.Tenant).
+		FilenameParam(&util.FilenameOptions{
+			Recursive: flags.Recursive,
+			Filenames: []string{flags.YamlFile},
+		}).
+		Do()
+
+	if err != nil {
+		common.ExitWithErrorf("build visitor failed: %v", err)
+	}
+
+	valid, invalid := 0, 0
+	for _, vs := range vss {
+		// The visitor has reported errors of every document already.
+		vs.Visit(func(mo resource.MeshObject, e error) error {
+			if e != nil {
+				invalid++
+				return errors.Wrap(e, "validate failed")
+			}
+
+			valid++
+			fmt.Printf("%s/%s is valid\n", mo.Kind(), mo.Name())
+			return nil
+		})
+	}
+
+	if invalid > 0 {
+		common.ExitWithErrorf("%d resources are valid, %d resources are invalid", valid, invalid)
+	}
+
+	fmt.Printf("%d resources are valid\n", valid)
+}
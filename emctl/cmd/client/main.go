@@ -46,15 +46,41 @@ emctl apply -f loadbalance.yaml
 # Apply Ingress
 emctl apply -f ingress.yaml
 
+# Apply a directory of resources, 8 resources of the same kind in parallel
+emctl apply -f mesh/ --concurrency 8
+
+# Diff local configuration against the one in the control plane
+emctl diff -f service-001.yaml
+
+# Validate configuration offline
+emctl validate -f service-001.yaml
+
+# Print JSON Schema of Service
+emctl schema service
+
 # Get service.
 emctl get service
 emctl get service -o yaml
 emctl get service service-001 -o json
+emctl get service -o wide --sort-by .spec.registerTenant
+emctl get service -o jsonpath='{.items[*].metadata.name}'
 
 # Get LoadBalance
 emctl get loadbalance
 emctl get loadbalance service-001 -o yaml
 
+# Watch changes of canaries
+emctl get canary --watch
+
+# Get registered instances of service
+emctl get serviceinstance service-001
+emctl get serviceinstance -l version=v1
+
+# Describe service with its resilience, canary, observability and instances
+emctl describe service service-001
+
+# Edit service in $EDITOR, the editor is reopened with the failures until it's saved
+emctl edit service service-001
 
 # Delete service
 emctl delete service service-001
@@ -63,6 +89,21 @@ emctl delete service -f service-001.yaml
 # Delete LoadBalance
 emctl delete loadbalance service-001
 
+# Evict a stale service instance
+emctl delete serviceinstance service-001/instance-001
+
+# Evict service instances by labels
+emctl delete serviceinstance -l 'version in (v1,v2)'
+
+# Back up all resources before upgrading the control plane, and restore them
+emctl backup -o mesh-backup.yaml
+emctl restore -f mesh-backup.yaml --conflict skip
+
+# Switch between control planes
+emctl config set-context prod --server 10.0.0.1:2381
+emctl config use-context prod
+emctl get service --context dev
+
 # NOTE: The manipulation of the kinds attached to Service below is the same with LoadBalance:
 # - Sidecar
 # - Resilience
@@ -97,8 +138,16 @@ func main() {
 		command.InstallCmd(),
 		command.ResetCmd(),
 		command.ApplyCmd(),
+		command.DiffCmd(),
+		command.ValidateCmd(),
+		command.SchemaCmd(),
 		command.DeleteCmd(),
 		command.GetCmd(),
+		command.DescribeCmd(),
+		command.EditCmd(),
+		command.BackupCmd(),
+		command.RestoreCmd(),
+		command.ConfigCmd(),
 		completionCmd,
 	)
 
@@ -87,6 +87,10 @@ func (oc *objectCreator) new(kind VersionKind, metaData MetaData) (MeshObject, e
 		return &Ingress{
 			MeshResource: NewIngressResource(apiVersion, metaData.Name),
 		}, nil
+	case KindServiceInstance:
+		return &ServiceInstance{
+			MeshResource: NewServiceInstanceResource(apiVersion, metaData.Name),
+		}, nil
 	default:
 		return nil, errors.Errorf("unsupported kind %s", kind.Kind)
 	}
@@ -132,6 +136,11 @@ func NewObservabilityOutputServerResource(apiVersion, name string) MeshResource
 	return NewMeshResource(apiVersion, KindObservabilityOutputServer, name)
 }
 
+// NewServiceInstanceResource returns a MeshResource with the service instance kind
+func NewServiceInstanceResource(apiVersion, name string) MeshResource {
+	return NewMeshResource(apiVersion, KindServiceInstance, name)
+}
+
 // NewTenantResource returns a MeshResource with the tenant kind
 func NewTenantResource(apiVersion, name string) MeshResource {
 	return NewMeshResource(apiVersion, KindTenant, name)
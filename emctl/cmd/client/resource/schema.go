@@ -0,0 +1,197 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+	// durationPattern matches the non-negative durations accepted by
+	// time.ParseDuration, empty for the default as Validate accepts.
+	durationPattern = `^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)?$`
+)
+
+type (
+	// JSONSchema is a JSON Schema document
+	JSONSchema map[string]interface{}
+
+	fieldSchema struct {
+		enum     []string
+		duration bool
+		port     bool
+		percent  bool
+		// defaulted fields are required by Validate after their defaults are
+		// filled by the decoder, but optional in the documents.
+		defaulted bool
+	}
+)
+
+// fieldSchemas holds constraints which can't be derived from the Go types,
+// keyed by the struct name and the JSON name of the field. They are kept
+// the same with the ones checked by Validate.
+var fieldSchemas = map[string]fieldSchema{
+	"ServiceSpec.registerTenant": {defaulted: true},
+
+	"Sidecar.discoveryType":   {enum: DiscoveryTypes},
+	"Sidecar.ingressProtocol": {enum: SidecarProtocols},
+	"Sidecar.egressProtocol":  {enum: SidecarProtocols},
+	"Sidecar.ingressPort":     {port: true},
+	"Sidecar.egressPort":      {port: true},
+
+	"LoadBalance.policy": {enum: LoadBalancePolicies},
+
+	"RateLimiterPolicy.timeoutDuration":    {duration: true},
+	"RateLimiterPolicy.limitRefreshPeriod": {duration: true},
+
+	"CircuitBreakerPolicy.slidingWindowType":              {enum: SlidingWindowTypes},
+	"CircuitBreakerPolicy.failureRateThreshold":           {percent: true},
+	"CircuitBreakerPolicy.slowCallRateThreshold":          {percent: true},
+	"CircuitBreakerPolicy.slowCallDurationThreshold":      {duration: true},
+	"CircuitBreakerPolicy.maxWaitDurationInHalfOpenState": {duration: true},
+	"CircuitBreakerPolicy.waitDurationInOpenState":        {duration: true},
+
+	"RetryerPolicy.waitDuration":  {duration: true},
+	"RetryerPolicy.backOffPolicy": {enum: BackOffPolicies},
+
+	"TimeLimiter.defaultTimeoutDuration": {duration: true},
+}
+
+// NewJSONSchema generates the JSON Schema of the kind from its Go type, only
+// the kinds declarable in files are supported.
+func NewJSONSchema(kind string) (JSONSchema, error) {
+	if !isDeclarableKind(kind) {
+		return nil, errors.Errorf("unsupported kind %s", kind)
+	}
+
+	object, err := NewObjectCreator().NewFromKind(VersionKind{Kind: kind})
+	if err != nil {
+		return nil, err
+	}
+
+	schema := typeSchema(reflect.TypeOf(object))
+	schema["$schema"] = jsonSchemaDraft
+	schema["title"] = kind
+
+	properties := schema["properties"].(map[string]interface{})
+	properties["apiVersion"] = JSONSchema{"type": "string", "enum": []string{DefaultAPIVersion}}
+	properties["kind"] = JSONSchema{"type": "string", "enum": []string{kind}}
+
+	return schema, nil
+}
+
+// NewAllJSONSchema generates a JSON Schema accepting the document of any kind.
+func NewAllJSONSchema() (JSONSchema, error) {
+	var schemas []JSONSchema
+	for _, kind := range Kinds {
+		schema, err := NewJSONSchema(kind)
+		if err != nil {
+			return nil, err
+		}
+		delete(schema, "$schema")
+		schemas = append(schemas, schema)
+	}
+
+	return JSONSchema{
+		"$schema": jsonSchemaDraft,
+		"title":   "EaseMesh resource",
+		"oneOf":   schemas,
+	}, nil
+}
+
+func typeSchema(t reflect.Type) JSONSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return JSONSchema{"type": "string"}
+	case reflect.Bool:
+		return JSONSchema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return JSONSchema{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return JSONSchema{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return JSONSchema{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return JSONSchema{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return JSONSchema{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		var required []string
+		for name, f := range jsonFields(t) {
+			schema := typeSchema(f.Type)
+			fs, exists := fieldSchemas[t.Name()+"."+name]
+			if exists {
+				applyFieldSchema(schema, fs)
+			}
+			properties[name] = schema
+			if f.required && !fs.defaulted {
+				required = append(required, name)
+			}
+		}
+
+		schema := JSONSchema{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+		if len(required) != 0 {
+			sort.Strings(required)
+			schema["required"] = required
+		}
+		return schema
+	default:
+		return JSONSchema{}
+	}
+}
+
+func applyFieldSchema(schema JSONSchema, fs fieldSchema) {
+	if len(fs.enum) != 0 {
+		// Empty means the default as Validate accepts.
+		schema["enum"] = append([]string{""}, fs.enum...)
+	}
+	if fs.duration {
+		schema["pattern"] = durationPattern
+	}
+	if fs.port {
+		schema["minimum"] = 0
+		schema["maximum"] = 65535
+	}
+	if fs.percent {
+		schema["minimum"] = 0
+		schema["maximum"] = 100
+	}
+}
+
+func isDeclarableKind(kind string) bool {
+	for _, k := range Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
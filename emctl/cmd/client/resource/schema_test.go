@@ -0,0 +1,95 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+)
+
+func TestNewJSONSchema(t *testing.T) {
+	for _, kind := range Kinds {
+		t.Run(kind, func(t *testing.T) {
+			schema, err := NewJSONSchema(kind)
+			if err != nil {
+				t.Fatalf("generate schema failed: %v", err)
+			}
+			if _, err := json.Marshal(schema); err != nil {
+				t.Fatalf("marshal schema failed: %v", err)
+			}
+
+			properties := schema["properties"].(map[string]interface{})
+			for _, name := range []string{"apiVersion", "kind", "metadata", "spec"} {
+				if _, exists := properties[name]; !exists {
+					t.Errorf("expect property %s in schema", name)
+				}
+			}
+		})
+	}
+
+	schema, err := NewJSONSchema(KindService)
+	if err != nil {
+		t.Fatalf("generate schema failed: %v", err)
+	}
+	spec := schema["properties"].(map[string]interface{})["spec"].(JSONSchema)
+	// registerTenant defaults to the tenant of the context.
+	required := spec["required"].([]string)
+	if len(required) != 1 || required[0] != "sidecar" {
+		t.Errorf("expect required field sidecar but got %v", required)
+	}
+	sidecar := spec["properties"].(map[string]interface{})["sidecar"].(JSONSchema)
+	discoveryType := sidecar["properties"].(map[string]interface{})["discoveryType"].(JSONSchema)
+	if enum, ok := discoveryType["enum"].([]string); !ok || len(enum) != len(DiscoveryTypes)+1 || enum[0] != "" {
+		t.Errorf("expect empty and %v for discoveryType but got %v", DiscoveryTypes, discoveryType["enum"])
+	}
+
+	for _, kind := range []string{"Unknown", KindServiceInstance} {
+		if _, err := NewJSONSchema(kind); err == nil {
+			t.Errorf("expect error for kind %s", kind)
+		}
+	}
+}
+
+func TestDurationPattern(t *testing.T) {
+	pattern := regexp.MustCompile(durationPattern)
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{"", true},
+		{"0", true},
+		{"500ms", true},
+		{"1m30s", true},
+		{"1.5h", true},
+		{"-1s", false},
+		{"10", false},
+		{"1d", false},
+	}
+
+	for _, tt := range tests {
+		if pattern.MatchString(tt.value) != tt.valid {
+			t.Errorf("expect duration %q matched %v by the pattern", tt.value, tt.valid)
+		}
+		v := &validator{}
+		v.validateDuration("duration", tt.value)
+		if (len(v.errs) == 0) != tt.valid {
+			t.Errorf("expect duration %q valid %v by Validate", tt.value, tt.valid)
+		}
+	}
+}
@@ -18,6 +18,9 @@
 package resource
 
 import (
+	"sort"
+	"strings"
+
 	"github.com/megaease/easemesh-api/v1alpha1"
 )
 
@@ -28,6 +31,9 @@ const (
 	// LoadBalanceRoundRobinPolicy is round robin policy
 	LoadBalanceRoundRobinPolicy = "roundRobin"
 
+	// LoadBalanceHeaderHashPolicy is the policy hashing the header specified by headerHashKey
+	LoadBalanceHeaderHashPolicy = "headerHash"
+
 	// DefaultSideIngressProtocol is default communication protocol for inbound traffic of the sidecar
 	DefaultSideIngressProtocol = "http"
 
@@ -64,8 +70,40 @@ const (
 
 	// KindIngress is ingress kind of the EaseMesh resource
 	KindIngress = "Ingress"
+
+	// KindServiceInstance is service instance kind of the EaseMesh resource
+	KindServiceInstance = "ServiceInstance"
 )
 
+// Kinds lists all kinds of the EaseMesh resource which could be declared in
+// files, service instances are registered by sidecars so they're excluded.
+// The kinds are in the order of dependency, a kind only refers to the kinds
+// before it, e.g. a Service refers to its Tenant, a Canary refers to its
+// Service.
+var Kinds = []string{
+	KindTenant,
+	KindService,
+	KindLoadBalance,
+	KindCanary,
+	KindResilience,
+	KindObservabilityTracings,
+	KindObservabilityMetrics,
+	KindObservabilityOutputServer,
+	KindIngress,
+}
+
+// SortByKind sorts the objects stably in the order of Kinds, so that the
+// objects are sorted after the ones they refer to.
+func SortByKind(objects []MeshObject) {
+	order := map[string]int{}
+	for i, kind := range Kinds {
+		order[kind] = i
+	}
+	sort.SliceStable(objects, func(i, j int) bool {
+		return order[objects[i].Kind()] < order[objects[j].Kind()]
+	})
+}
+
 type (
 	// VersionKind holds version and kind information for APIs
 	VersionKind struct {
@@ -111,7 +149,7 @@ type (
 	// Service describes service resource of the EaseMesh
 	Service struct {
 		MeshResource `json:",inline"`
-		Spec         *ServiceSpec `json:"spec" jsonschema:"omitempty"`
+		Spec         *ServiceSpec `json:"spec" jsonschema:"required"`
 	}
 
 	// ServiceSpec describes details of the service resource
@@ -171,6 +209,13 @@ type (
 	IngressSpec struct {
 		Rules []*v1alpha1.IngressRule `json:"rules" jsonschema:"omitempty"`
 	}
+
+	// ServiceInstance describes an instance of the service registered by the sidecar,
+	// its name is in form of <service name>/<instance id>
+	ServiceInstance struct {
+		MeshResource `json:",inline"`
+		Spec         *v1alpha1.ServiceInstance `json:"spec" jsonschema:"omitempty"`
+	}
 )
 
 var _ MeshObject = &Service{}
@@ -180,6 +225,7 @@ var _ MeshObject = &ObservabilityTracings{}
 var _ MeshObject = &LoadBalance{}
 var _ MeshObject = &Resilience{}
 var _ MeshObject = &Ingress{}
+var _ MeshObject = &ServiceInstance{}
 
 // Default set default value for ServiceSpec
 func (s *ServiceSpec) Default() {
@@ -230,6 +276,13 @@ func (m *MeshResource) Labels() map[string]string {
 	return m.MetaData.Labels
 }
 
+// KindHasLabels returns whether objects of the kind read from the control
+// plane carry labels, the control plane keeps labels of service instances
+// only, objects of other kinds are converted without labels.
+func KindHasLabels(kind string) bool {
+	return kind == KindServiceInstance
+}
+
 // ToV1Alpha1 converts an Ingress resource to v1alpha1.Ingress
 func (ing *Ingress) ToV1Alpha1() *v1alpha1.Ingress {
 	result := &v1alpha1.Ingress{}
@@ -296,6 +349,11 @@ func (r *ObservabilityMetrics) ToV1Alpha1() (result *v1alpha1.ObservabilityMetri
 	return r.Spec
 }
 
+// ToV1Alpha1 converts a ServiceInstance resource to v1alpha1.ServiceInstance
+func (s *ServiceInstance) ToV1Alpha1() *v1alpha1.ServiceInstance {
+	return s.Spec
+}
+
 // ToIngress converts a v1alpha1.Ingress resource to an Ingress resource
 func ToIngress(ingress *v1alpha1.Ingress) *Ingress {
 	result := &Ingress{
@@ -394,3 +452,28 @@ func ToResilience(name string, resilience *v1alpha1.Resilience) *Resilience {
 	result.Spec.TimeLimiter = resilience.TimeLimiter
 	return result
 }
+
+// ToServiceInstance converts a v1alpha1.ServiceInstance resource to a ServiceInstance resource
+func ToServiceInstance(instance *v1alpha1.ServiceInstance) *ServiceInstance {
+	result := &ServiceInstance{}
+	result.MeshResource = NewServiceInstanceResource(DefaultAPIVersion,
+		ServiceInstanceName(instance.ServiceName, instance.InstanceID))
+	result.MetaData.Labels = instance.Labels
+	result.Spec = instance
+	return result
+}
+
+// ServiceInstanceName returns the name of the ServiceInstance resource
+func ServiceInstanceName(serviceName, instanceID string) string {
+	return serviceName + "/" + instanceID
+}
+
+// ParseServiceInstanceName splits the name of the ServiceInstance resource,
+// the instance id is empty if the name is only a service name.
+func ParseServiceInstanceName(name string) (serviceName, instanceID string) {
+	parts := strings.SplitN(name, "/", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
@@ -0,0 +1,485 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+	"github.com/pkg/errors"
+)
+
+var (
+	// DiscoveryTypes are supported service registry types of the sidecar
+	DiscoveryTypes = []string{"eureka", "consul", "nacos"}
+
+	// SidecarProtocols are supported protocols of the sidecar traffic
+	SidecarProtocols = []string{"http"}
+
+	// LoadBalancePolicies are supported load balance policies
+	LoadBalancePolicies = []string{LoadBalanceRoundRobinPolicy, "random", "weightedRandom", "ipHash", LoadBalanceHeaderHashPolicy}
+
+	// SlidingWindowTypes are supported sliding window types of the circuit breaker
+	SlidingWindowTypes = []string{"COUNT_BASED", "TIME_BASED"}
+
+	// BackOffPolicies are supported back off policies of the retryer
+	BackOffPolicies = []string{"random", "exponential"}
+)
+
+type (
+	// ValidationError describes an invalid field of a resource
+	ValidationError struct {
+		Field  string
+		Detail string
+	}
+
+	// ValidationErrors holds all invalid fields of a resource
+	ValidationErrors []*ValidationError
+
+	validator struct {
+		errs ValidationErrors
+	}
+)
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Detail)
+}
+
+func (es ValidationErrors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return "invalid resource:\n  - " + strings.Join(msgs, "\n  - ")
+}
+
+// Validate checks the object decoded from the JSON document, it reports
+// unknown fields, missing required fields, unsupported enum values,
+// malformed durations and out of range ports all at once.
+func Validate(object MeshObject, jsonBuff []byte) error {
+	raw := map[string]interface{}{}
+	err := json.Unmarshal(jsonBuff, &raw)
+	if err != nil {
+		return errors.Wrap(err, "unmarshal data to map")
+	}
+
+	v := &validator{}
+	v.validateFields(raw, reflect.TypeOf(object), "")
+	v.validateObject(object)
+
+	if len(v.errs) == 0 {
+		return nil
+	}
+
+	return v.errs
+}
+
+func (v *validator) addf(field, format string, a ...interface{}) {
+	v.errs = append(v.errs, &ValidationError{Field: field, Detail: fmt.Sprintf(format, a...)})
+}
+
+func joinField(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+type fieldInfo struct {
+	reflect.StructField
+	required bool
+}
+
+// jsonFields returns fields of the struct keyed by their JSON names, the
+// fields of embedded structs without JSON names are flattened.
+func jsonFields(t reflect.Type) map[string]*fieldInfo {
+	fields := map[string]*fieldInfo{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for k, v := range jsonFields(ft) {
+					fields[k] = v
+				}
+				continue
+			}
+		}
+
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		required := false
+		for _, option := range strings.Split(f.Tag.Get("jsonschema"), ",") {
+			if option == "required" {
+				required = true
+			}
+		}
+
+		fields[name] = &fieldInfo{StructField: f, required: required}
+	}
+
+	return fields
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validateFields walks the raw document along the type, type mismatches
+// are left to json.Unmarshal.
+func (v *validator) validateFields(value interface{}, t reflect.Type, path string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+
+		fields := jsonFields(t)
+		for _, key := range sortedKeys(m) {
+			f, exists := fields[key]
+			if !exists {
+				v.addf(joinField(path, key), "unknown field%s", suggestField(key, fields))
+				continue
+			}
+			if m[key] != nil {
+				v.validateFields(m[key], f.Type, joinField(path, key))
+			}
+		}
+
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if !fields[name].required {
+				continue
+			}
+			if value, exists := m[name]; !exists || value == nil || value == "" {
+				v.addf(joinField(path, name), "required field is missing")
+			}
+		}
+	case reflect.Slice:
+		items, ok := value.([]interface{})
+		if !ok {
+			return
+		}
+		for i, item := range items {
+			if item != nil {
+				v.validateFields(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	case reflect.Map:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for _, key := range sortedKeys(m) {
+			if m[key] != nil {
+				v.validateFields(m[key], t.Elem(), joinField(path, key))
+			}
+		}
+	}
+}
+
+func suggestField(key string, fields map[string]*fieldInfo) string {
+	for name := range fields {
+		if strings.EqualFold(name, key) {
+			return fmt.Sprintf(", did you mean %q", name)
+		}
+	}
+	return ""
+}
+
+func (v *validator) validateObject(object MeshObject) {
+	if object.APIVersion() != DefaultAPIVersion {
+		v.addf("apiVersion", "unsupported apiVersion %q, expecting %q", object.APIVersion(), DefaultAPIVersion)
+	}
+
+	switch o := object.(type) {
+	case *Service:
+		if o.Spec == nil {
+			return
+		}
+		v.validateSidecar("spec.sidecar", o.Spec.Sidecar)
+		v.validateLoadBalance("spec.loadBalance", o.Spec.LoadBalance)
+		v.validateResilience("spec.resilience", o.Spec.Resilience)
+		v.validateCanary("spec.canary", o.Spec.Canary)
+		if o.Spec.Observability != nil {
+			v.validateOutputServer("spec.observability.outputServer", o.Spec.Observability.OutputServer)
+		}
+	case *LoadBalance:
+		v.validateLoadBalance("spec", o.Spec)
+	case *Resilience:
+		v.validateResilience("spec", o.Spec)
+	case *Canary:
+		v.validateCanary("spec", o.Spec)
+	case *ObservabilityOutputServer:
+		v.validateOutputServer("spec", o.Spec)
+	case *Ingress:
+		v.validateIngress("spec", o.Spec)
+	case *ServiceInstance:
+		v.addf("kind", "%s is registered by the sidecar, it can't be declared in files", KindServiceInstance)
+	}
+}
+
+func (v *validator) validateEnum(field, value string, values []string) {
+	if value == "" {
+		return
+	}
+	for _, supported := range values {
+		if value == supported {
+			return
+		}
+	}
+	v.addf(field, "unsupported value %q (support %s)", value, strings.Join(values, ", "))
+}
+
+func (v *validator) validateDuration(field, value string) {
+	if value == "" {
+		return
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		v.addf(field, "invalid duration %q, expecting the format such as 500ms, 10s, 1m", value)
+		return
+	}
+	if d < 0 {
+		v.addf(field, "duration %q must not be negative", value)
+	}
+}
+
+func (v *validator) validatePort(field string, port int32) {
+	// Zero means the default port.
+	if port < 0 || port > 65535 {
+		v.addf(field, "port %d is out of range [0, 65535], 0 for the default port", port)
+	}
+}
+
+func (v *validator) validatePercentage(field string, value uint32) {
+	if value > 100 {
+		v.addf(field, "percentage %d is out of range [0, 100]", value)
+	}
+}
+
+func (v *validator) validateSidecar(field string, sidecar *v1alpha1.Sidecar) {
+	if sidecar == nil {
+		return
+	}
+	v.validateEnum(field+".discoveryType", sidecar.DiscoveryType, DiscoveryTypes)
+	v.validateEnum(field+".ingressProtocol", sidecar.IngressProtocol, SidecarProtocols)
+	v.validateEnum(field+".egressProtocol", sidecar.EgressProtocol, SidecarProtocols)
+	v.validatePort(field+".ingressPort", sidecar.IngressPort)
+	v.validatePort(field+".egressPort", sidecar.EgressPort)
+	if sidecar.IngressPort != 0 && sidecar.IngressPort == sidecar.EgressPort {
+		v.addf(field+".egressPort", "port %d conflicts with ingressPort", sidecar.EgressPort)
+	}
+}
+
+func (v *validator) validateLoadBalance(field string, lb *v1alpha1.LoadBalance) {
+	if lb == nil {
+		return
+	}
+	v.validateEnum(field+".policy", lb.Policy, LoadBalancePolicies)
+	if lb.Policy == LoadBalanceHeaderHashPolicy && lb.HeaderHashKey == "" {
+		v.addf(field+".headerHashKey", "required field is missing for policy %s", LoadBalanceHeaderHashPolicy)
+	}
+}
+
+func (v *validator) validateURLRules(field string, rules []*v1alpha1.URLRule, policies map[string]bool) {
+	for i, rule := range rules {
+		if rule == nil {
+			continue
+		}
+		ruleField := fmt.Sprintf("%s[%d]", field, i)
+		v.validateStringMatch(ruleField+".url", rule.Url)
+		if policies != nil && rule.PolicyRef != "" && !policies[rule.PolicyRef] {
+			v.addf(ruleField+".policyRef", "policy %q is not defined", rule.PolicyRef)
+		}
+	}
+}
+
+func (v *validator) validateStringMatch(field string, match *v1alpha1.StringMatch) {
+	if match == nil || match.Regex == "" {
+		return
+	}
+	_, err := regexp.Compile(match.Regex)
+	if err != nil {
+		v.addf(field+".regex", "invalid regular expression: %v", err)
+	}
+}
+
+func (v *validator) validateResilience(field string, resilience *v1alpha1.Resilience) {
+	if resilience == nil {
+		return
+	}
+
+	if rl := resilience.RateLimiter; rl != nil {
+		policies := map[string]bool{}
+		for i, p := range rl.Policies {
+			if p == nil {
+				continue
+			}
+			policyField := fmt.Sprintf("%s.rateLimiter.policies[%d]", field, i)
+			policies[p.Name] = true
+			v.validateDuration(policyField+".timeoutDuration", p.TimeoutDuration)
+			v.validateDuration(policyField+".limitRefreshPeriod", p.LimitRefreshPeriod)
+		}
+		v.validatePolicyRef(field+".rateLimiter.defaultPolicyRef", rl.DefaultPolicyRef, policies)
+		v.validateURLRules(field+".rateLimiter.urls", rl.Urls, policies)
+	}
+
+	if cb := resilience.CircuitBreaker; cb != nil {
+		policies := map[string]bool{}
+		for i, p := range cb.Policies {
+			if p == nil {
+				continue
+			}
+			policyField := fmt.Sprintf("%s.circuitBreaker.policies[%d]", field, i)
+			policies[p.Name] = true
+			v.validateEnum(policyField+".slidingWindowType", p.SlidingWindowType, SlidingWindowTypes)
+			v.validatePercentage(policyField+".failureRateThreshold", p.FailureRateThreshold)
+			v.validatePercentage(policyField+".slowCallRateThreshold", p.SlowCallRateThreshold)
+			v.validateDuration(policyField+".slowCallDurationThreshold", p.SlowCallDurationThreshold)
+			v.validateDuration(policyField+".maxWaitDurationInHalfOpenState", p.MaxWaitDurationInHalfOpenState)
+			v.validateDuration(policyField+".waitDurationInOpenState", p.WaitDurationInOpenState)
+		}
+		v.validatePolicyRef(field+".circuitBreaker.defaultPolicyRef", cb.DefaultPolicyRef, policies)
+		v.validateURLRules(field+".circuitBreaker.urls", cb.Urls, policies)
+	}
+
+	if r := resilience.Retryer; r != nil {
+		policies := map[string]bool{}
+		for i, p := range r.Policies {
+			if p == nil {
+				continue
+			}
+			policyField := fmt.Sprintf("%s.retryer.policies[%d]", field, i)
+			policies[p.Name] = true
+			v.validateDuration(policyField+".waitDuration", p.WaitDuration)
+			v.validateEnum(policyField+".backOffPolicy", p.BackOffPolicy, BackOffPolicies)
+			if p.RandomizationFactor < 0 || p.RandomizationFactor > 1 {
+				v.addf(policyField+".randomizationFactor", "factor %v is out of range [0, 1]", p.RandomizationFactor)
+			}
+		}
+		v.validatePolicyRef(field+".retryer.defaultPolicyRef", r.DefaultPolicyRef, policies)
+		v.validateURLRules(field+".retryer.urls", r.Urls, policies)
+	}
+
+	if tl := resilience.TimeLimiter; tl != nil {
+		v.validateDuration(field+".timeLimiter.defaultTimeoutDuration", tl.DefaultTimeoutDuration)
+		v.validateURLRules(field+".timeLimiter.urls", tl.Urls, nil)
+	}
+}
+
+func (v *validator) validatePolicyRef(field, ref string, policies map[string]bool) {
+	if ref != "" && !policies[ref] {
+		v.addf(field, "policy %q is not defined", ref)
+	}
+}
+
+func (v *validator) validateCanary(field string, canary *v1alpha1.Canary) {
+	if canary == nil {
+		return
+	}
+	for i, rule := range canary.CanaryRules {
+		if rule == nil {
+			continue
+		}
+		ruleField := fmt.Sprintf("%s.canaryRules[%d]", field, i)
+		names := make([]string, 0, len(rule.Headers))
+		for name := range rule.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			v.validateStringMatch(ruleField+".headers."+name, rule.Headers[name])
+		}
+		v.validateURLRules(ruleField+".urls", rule.Urls, nil)
+	}
+}
+
+func (v *validator) validateOutputServer(field string, output *v1alpha1.ObservabilityOutputServer) {
+	if output == nil {
+		return
+	}
+	if output.Timeout < 0 {
+		v.addf(field+".timeout", "timeout %d must not be negative", output.Timeout)
+	}
+	if output.Enabled && output.BootstrapServer == "" {
+		v.addf(field+".bootstrapServer", "required field is missing when the output server is enabled")
+	}
+}
+
+func (v *validator) validateIngress(field string, spec *IngressSpec) {
+	if spec == nil {
+		return
+	}
+	for i, rule := range spec.Rules {
+		if rule == nil {
+			continue
+		}
+		for j, path := range rule.Paths {
+			if path == nil {
+				continue
+			}
+			pathField := fmt.Sprintf("%s.rules[%d].paths[%d]", field, i, j)
+			if path.Backend == "" {
+				v.addf(pathField+".backend", "required field is missing")
+			}
+			if path.Path == "" {
+				v.addf(pathField+".path", "required field is missing")
+				continue
+			}
+			_, err := regexp.Compile(path.Path)
+			if err != nil {
+				v.addf(pathField+".path", "invalid regular expression: %v", err)
+			}
+		}
+	}
+}
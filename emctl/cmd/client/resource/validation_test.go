@@ -0,0 +1,196 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"encoding/json"
+	"testing"
+
+	yamljsontool "github.com/ghodss/yaml"
+)
+
+const validService = `kind: Service
+apiVersion: mesh.megaease.com/v1alpha1
+metadata:
+  name: vets-service
+spec:
+  registerTenant: pet
+  sidecar:
+    discoveryType: eureka
+    ingressPort: 13001
+    ingressProtocol: http
+    egressPort: 13002
+    egressProtocol: http
+  loadBalance:
+    policy: headerHash
+    headerHashKey: X-User
+  resilience:
+    circuitBreaker:
+      defaultPolicyRef: default
+      policies:
+      - name: default
+        slidingWindowType: COUNT_BASED
+        failureRateThreshold: 50
+        waitDurationInOpenState: 60s
+      urls:
+      - methods: [GET]
+        url:
+          prefix: /pet
+        policyRef: default
+`
+
+func validate(t *testing.T, yamlBuff string) error {
+	jsonBuff, err := yamljsontool.YAMLToJSON([]byte(yamlBuff))
+	if err != nil {
+		t.Fatalf("transform yaml to json failed: %v", err)
+	}
+
+	vk := VersionKind{}
+	if err := json.Unmarshal(jsonBuff, &vk); err != nil {
+		t.Fatalf("unmarshal version kind failed: %v", err)
+	}
+	object, err := NewObjectCreator().NewFromKind(vk)
+	if err != nil {
+		t.Fatalf("new object failed: %v", err)
+	}
+	if err := json.Unmarshal(jsonBuff, object); err != nil {
+		t.Fatalf("unmarshal object failed: %v", err)
+	}
+
+	return Validate(object, jsonBuff)
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name           string
+		yaml           string
+		expectedFields []string
+	}{
+		{"valid-service", validService, nil},
+		{
+			"typo-and-api-version",
+			`kind: LoadBalance
+apiVersion: mesh.megaease.com/v1alpla1
+metadata:
+  name: vets-service
+spec:
+  policy: roundRobin
+  HeaderHashKey: X-User
+`,
+			[]string{"spec.HeaderHashKey", "apiVersion"},
+		},
+		{
+			"missing-required",
+			`kind: Service
+apiVersion: mesh.megaease.com/v1alpha1
+metadata:
+  labels:
+    team: pet
+spec:
+  sidecar: {}
+`,
+			[]string{"metadata.name", "spec.registerTenant"},
+		},
+		{
+			"enum-duration-port",
+			`kind: Service
+apiVersion: mesh.megaease.com/v1alpha1
+metadata:
+  name: vets-service
+spec:
+  registerTenant: pet
+  sidecar:
+    discoveryType: zookeeper
+    ingressPort: 70000
+    egressProtocol: grpc
+  loadBalance:
+    policy: leastConn
+  resilience:
+    retryer:
+      policies:
+      - name: default
+        waitDuration: 5 seconds
+      urls:
+      - policyRef: missing
+`,
+			[]string{
+				"spec.sidecar.discoveryType",
+				"spec.sidecar.egressProtocol",
+				"spec.sidecar.ingressPort",
+				"spec.loadBalance.policy",
+				"spec.resilience.retryer.policies[0].waitDuration",
+				"spec.resilience.retryer.urls[0].policyRef",
+			},
+		},
+		{
+			"default-port",
+			`kind: Service
+apiVersion: mesh.megaease.com/v1alpha1
+metadata:
+  name: vets-service
+spec:
+  registerTenant: pet
+  sidecar:
+    ingressPort: 0
+    egressPort: -1
+`,
+			[]string{"spec.sidecar.egressPort"},
+		},
+		{
+			"misplaced-policy-ref",
+			`kind: Resilience
+apiVersion: mesh.megaease.com/v1alpha1
+metadata:
+  name: vets-service
+spec:
+  timeLimiter:
+    defaultTimeoutDuration: 500ms
+    urls:
+    - url:
+        prefix: /pet
+        policyRef: default
+`,
+			[]string{"spec.timeLimiter.urls[0].url.policyRef"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate(t, tt.yaml)
+			if len(tt.expectedFields) == 0 {
+				if err != nil {
+					t.Fatalf("expect no error but got: %v", err)
+				}
+				return
+			}
+
+			errs, ok := err.(ValidationErrors)
+			if !ok {
+				t.Fatalf("expect ValidationErrors but got: %v", err)
+			}
+			if len(errs) != len(tt.expectedFields) {
+				t.Fatalf("expect %d errors but got: %v", len(tt.expectedFields), errs)
+			}
+			for i, e := range errs {
+				if e.Field != tt.expectedFields[i] {
+					t.Errorf("expect error of field %s but got: %v", tt.expectedFields[i], e)
+				}
+			}
+		})
+	}
+}
@@ -23,6 +23,8 @@ import (
 	"os"
 	"strings"
 
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+
 	"github.com/pkg/errors"
 )
 
@@ -40,6 +42,7 @@ type (
 		File() VisitorBuilder
 		URL(httpAttemptCount int, urls ...*url.URL) VisitorBuilder
 		Stdin() VisitorBuilder
+		DefaultTenant(tenant string) VisitorBuilder
 	}
 	visitorBuilder struct {
 		visitors          []Visitor
@@ -87,6 +90,13 @@ func (b *visitorBuilder) CommandParam(commandOptions *CommandOptions) VisitorBui
 	return b
 }
 
+// DefaultTenant fills the tenant into the Services registering to no tenant
+// in the files, it must be called before Do.
+func (b *visitorBuilder) DefaultTenant(tenant string) VisitorBuilder {
+	b.decoder = &decoder{oc: resource.NewObjectCreator(), defaultTenant: tenant}
+	return b
+}
+
 func (b *visitorBuilder) Command() VisitorBuilder {
 	if b.commandOptions == nil {
 		return b
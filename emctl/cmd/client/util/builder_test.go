@@ -48,7 +48,7 @@ apiVersion: mesh.megaease.com/v1alpha1
 metadata:
   name: tenant_{id}
 spec:
-  service: []
+  services: []
 `
 
 	aService = `kind: Service
@@ -57,6 +57,8 @@ metadata:
   name: service_{id}
 spec:
    registerTenant: tenant_{id}
+   sidecar:
+     discoveryType: eureka
 `
 )
 
@@ -22,6 +22,7 @@ import (
 
 	"github.com/megaease/easemeshctl/cmd/client/resource"
 
+	yamljsontool "github.com/ghodss/yaml"
 	"github.com/pkg/errors"
 )
 
@@ -32,6 +33,9 @@ type Decoder interface {
 
 type decoder struct {
 	oc resource.ObjectCreator
+
+	// defaultTenant is filled into the Services registering to no tenant
+	defaultTenant string
 }
 
 func (d *decoder) Decode(jsonBuff []byte) (resource.MeshObject, *resource.VersionKind, error) {
@@ -41,6 +45,13 @@ func (d *decoder) Decode(jsonBuff []byte) (resource.MeshObject, *resource.Versio
 		return nil, nil, errors.Wrap(err, "unmarshal data to resource.VersionKind failed")
 	}
 
+	if vk.Kind == resource.KindService && d.defaultTenant != "" {
+		jsonBuff, err = defaultRegisterTenant(jsonBuff, d.defaultTenant)
+		if err != nil {
+			return nil, vk, err
+		}
+	}
+
 	meshObject, err := d.oc.NewFromKind(*vk)
 	if err != nil {
 		return nil, vk, err
@@ -50,9 +61,48 @@ func (d *decoder) Decode(jsonBuff []byte) (resource.MeshObject, *resource.Versio
 	if err != nil {
 		return nil, vk, errors.Wrap(err, "unmarshal data to MeshObject")
 	}
+
+	err = resource.Validate(meshObject, jsonBuff)
+	if err != nil {
+		return nil, vk, err
+	}
+
 	return meshObject, vk, nil
 }
 
+// defaultRegisterTenant sets spec.registerTenant of the Service document if
+// it's absent or empty, before the document is validated.
+func defaultRegisterTenant(jsonBuff []byte, tenant string) ([]byte, error) {
+	raw := map[string]interface{}{}
+	err := json.Unmarshal(jsonBuff, &raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal data to map")
+	}
+
+	spec, ok := raw["spec"].(map[string]interface{})
+	if !ok {
+		spec = map[string]interface{}{}
+		raw["spec"] = spec
+	}
+	if registerTenant, _ := spec["registerTenant"].(string); registerTenant != "" {
+		return jsonBuff, nil
+	}
+	spec["registerTenant"] = tenant
+
+	return json.Marshal(raw)
+}
+
 func newDefaultDecoder() Decoder {
 	return &decoder{oc: resource.NewObjectCreator()}
 }
+
+// DecodeYAML decodes a MeshObject from a YAML or JSON document and validates it
+func DecodeYAML(data []byte) (resource.MeshObject, error) {
+	jsonBuff, err := yamljsontool.YAMLToJSON(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "transform yaml to json")
+	}
+
+	meshObject, _, err := newDefaultDecoder().Decode(jsonBuff)
+	return meshObject, err
+}
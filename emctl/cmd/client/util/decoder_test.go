@@ -0,0 +1,83 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+
+	yamljsontool "github.com/ghodss/yaml"
+)
+
+func TestDecodeDefaultTenant(t *testing.T) {
+	tests := []struct {
+		name          string
+		document      string
+		defaultTenant string
+		expected      string
+		valid         bool
+	}{
+		{"filled", `kind: Service
+apiVersion: mesh.megaease.com/v1alpha1
+metadata:
+  name: vets
+spec:
+  sidecar: {}
+`, "pet", "pet", true},
+		{"kept", `kind: Service
+apiVersion: mesh.megaease.com/v1alpha1
+metadata:
+  name: vets
+spec:
+  registerTenant: shop
+  sidecar: {}
+`, "pet", "shop", true},
+		{"no-default", `kind: Service
+apiVersion: mesh.megaease.com/v1alpha1
+metadata:
+  name: vets
+spec:
+  sidecar: {}
+`, "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonBuff, err := yamljsontool.YAMLToJSON([]byte(tt.document))
+			if err != nil {
+				t.Fatalf("transform yaml to json failed: %v", err)
+			}
+
+			d := &decoder{oc: resource.NewObjectCreator(), defaultTenant: tt.defaultTenant}
+			object, _, err := d.Decode(jsonBuff)
+			if !tt.valid {
+				if err == nil {
+					t.Errorf("expect error for the service registering to no tenant")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decode failed: %v", err)
+			}
+			if tenant := object.(*resource.Service).Spec.RegisterTenant; tenant != tt.expected {
+				t.Errorf("expect tenant %s but got %s", tt.expected, tenant)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+
+	yamljsontool "github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+const diffContextLines = 3
+
+// NormalizedYAML renders a MeshObject as YAML in a form that is comparable
+// between the local manifest and the object stored in the control plane.
+// The apiVersion and labels are dropped, because the control plane doesn't
+// persist them, so are the null values.
+func NormalizedYAML(object resource.MeshObject) (string, error) {
+	jsonBuff, err := json.Marshal(object)
+	if err != nil {
+		return "", errors.Wrapf(err, "marshal %s/%s to json", object.Kind(), object.Name())
+	}
+
+	m := map[string]interface{}{}
+	err = json.Unmarshal(jsonBuff, &m)
+	if err != nil {
+		return "", errors.Wrapf(err, "unmarshal %s/%s to map", object.Kind(), object.Name())
+	}
+
+	delete(m, "apiVersion")
+	if metadata, ok := m["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "labels")
+	}
+	pruneNull(m)
+
+	jsonBuff, err = json.Marshal(m)
+	if err != nil {
+		return "", errors.Wrapf(err, "marshal %s/%s to json", object.Kind(), object.Name())
+	}
+
+	yamlBuff, err := yamljsontool.JSONToYAML(jsonBuff)
+	if err != nil {
+		return "", errors.Wrapf(err, "transform %s/%s to yaml", object.Kind(), object.Name())
+	}
+
+	return string(yamlBuff), nil
+}
+
+func pruneNull(v interface{}) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, item := range value {
+			if item == nil {
+				delete(value, k)
+				continue
+			}
+			pruneNull(item)
+		}
+	case []interface{}:
+		for _, item := range value {
+			pruneNull(item)
+		}
+	}
+}
+
+// UnifiedDiff returns the difference between from and to in the unified
+// format, it returns an empty string if the two texts are equal.
+func UnifiedDiff(fromName, toName, from, to string) string {
+	a, b := splitLines(from), splitLines(to)
+	ops := diffLines(a, b)
+
+	changed := false
+	for _, op := range ops {
+		if op.kind != ' ' {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return ""
+	}
+
+	builder := &strings.Builder{}
+	fmt.Fprintf(builder, "--- %s\n+++ %s\n", fromName, toName)
+
+	// Every changed line takes diffContextLines unchanged lines around it,
+	// overlapped or adjacent ranges are merged into one hunk.
+	begin, end := -1, -1
+	for i, op := range ops {
+		if op.kind == ' ' {
+			continue
+		}
+
+		lo, hi := i-diffContextLines, i+diffContextLines+1
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > len(ops) {
+			hi = len(ops)
+		}
+
+		if begin != -1 && lo > end {
+			writeHunk(builder, ops[begin:end])
+			begin = -1
+		}
+		if begin == -1 {
+			begin = lo
+		}
+		end = hi
+	}
+	writeHunk(builder, ops[begin:end])
+
+	return builder.String()
+}
+
+type diffOp struct {
+	kind rune
+	line string
+	// aLine and bLine are 1-based line numbers of the line in each text.
+	aLine int
+	bLine int
+}
+
+func writeHunk(builder *strings.Builder, ops []diffOp) {
+	aStart, bStart, aCount, bCount := 0, 0, 0, 0
+	for _, op := range ops {
+		if op.kind != '+' {
+			if aCount == 0 {
+				aStart = op.aLine
+			}
+			aCount++
+		}
+		if op.kind != '-' {
+			if bCount == 0 {
+				bStart = op.bLine
+			}
+			bCount++
+		}
+	}
+
+	// An empty range points to the line right before it.
+	if aCount == 0 {
+		aStart = ops[0].aLine - 1
+	}
+	if bCount == 0 {
+		bStart = ops[0].bLine - 1
+	}
+
+	fmt.Fprintf(builder, "@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount))
+	for _, op := range ops {
+		fmt.Fprintf(builder, "%c%s\n", op.kind, op.line)
+	}
+}
+
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines computes line operations transforming a into b based on the
+// longest common subsequence of them.
+func diffLines(a, b []string) []diffOp {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{kind: ' ', line: a[i], aLine: i + 1, bLine: j + 1})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{kind: '-', line: a[i], aLine: i + 1, bLine: j + 1})
+			i++
+		default:
+			ops = append(ops, diffOp{kind: '+', line: b[j], aLine: i + 1, bLine: j + 1})
+			j++
+		}
+	}
+
+	return ops
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+
+	"github.com/megaease/easemesh-api/v1alpha1"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		expected string
+	}{
+		{"equal", "a\nb\n", "a\nb\n", ""},
+		{"create", "", "a\nb\n", "--- live\n+++ local\n@@ -0,0 +1,2 @@\n+a\n+b\n"},
+		{"remove", "a\nb\n", "", "--- live\n+++ local\n@@ -1,2 +0,0 @@\n-a\n-b\n"},
+		{
+			"change-with-context",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			"1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			"--- live\n+++ local\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			"two-hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			"one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+			"--- live\n+++ local\n@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UnifiedDiff("live", "local", tt.from, tt.to)
+			if got != tt.expected {
+				t.Errorf("expected diff:\n%s\nbut got:\n%s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNormalizedYAML(t *testing.T) {
+	local := &resource.LoadBalance{
+		MeshResource: resource.NewLoadBalanceResource("mesh.megaease.com/v1alpla1", "service-001"),
+		Spec:         &v1alpha1.LoadBalance{Policy: "roundRobin"},
+	}
+	local.MetaData.Labels = map[string]string{"team": "order"}
+	live := resource.ToLoadBalance("service-001", &v1alpha1.LoadBalance{Policy: "roundRobin"})
+
+	localYAML, err := NormalizedYAML(local)
+	if err != nil {
+		t.Fatalf("normalize local object failed: %v", err)
+	}
+	liveYAML, err := NormalizedYAML(live)
+	if err != nil {
+		t.Fatalf("normalize live object failed: %v", err)
+	}
+
+	if localYAML != liveYAML {
+		t.Errorf("expect equal normalized yaml, but got diff:\n%s", UnifiedDiff("live", "local", liveYAML, localYAML))
+	}
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Selector selects MeshObjects by their labels, the syntax keeps the same
+// with the label selector of the kubernetes, such as:
+// key1=value1,key2!=value2,key3 in (a,b),key4 notin (c),key5,!key6
+type Selector interface {
+	Matches(object resource.MeshObject) bool
+	Empty() bool
+	String() string
+}
+
+type selector struct {
+	labels.Selector
+}
+
+// ParseSelector parses the label selector, an empty selector matches all objects
+func ParseSelector(s string) (Selector, error) {
+	sel, err := labels.Parse(s)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse selector %q", s)
+	}
+	return &selector{Selector: sel}, nil
+}
+
+func (s *selector) Matches(object resource.MeshObject) bool {
+	return s.Selector.Matches(labels.Set(object.Labels()))
+}
+
+// FilterObjects returns objects matching the selector
+func FilterObjects(objects []resource.MeshObject, sel Selector) []resource.MeshObject {
+	if sel.Empty() {
+		return objects
+	}
+
+	var result []resource.MeshObject
+	for _, object := range objects {
+		if sel.Matches(object) {
+			result = append(result, object)
+		}
+	}
+	return result
+}
+
+// ValidateSelectorKind checks whether the selector is able to select objects
+// of the kind listed from the control plane. A non-empty selector is rejected
+// for kinds without labels, otherwise negative requirements such as key!=value
+// and !key would match all objects of the kind.
+func ValidateSelectorKind(sel Selector, kind string) error {
+	if sel.Empty() || resource.KindHasLabels(kind) {
+		return nil
+	}
+	return errors.Errorf("%s carries no labels in the control plane, selector %q is not supported", kind, sel.String())
+}
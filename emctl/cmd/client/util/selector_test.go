@@ -0,0 +1,102 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"testing"
+
+	"github.com/megaease/easemeshctl/cmd/client/resource"
+)
+
+func TestFilterObjects(t *testing.T) {
+	newTenant := func(name string, labels map[string]string) resource.MeshObject {
+		tenant := &resource.Tenant{MeshResource: resource.NewTenantResource(resource.DefaultAPIVersion, name)}
+		tenant.MetaData.Labels = labels
+		return tenant
+	}
+
+	objects := []resource.MeshObject{
+		newTenant("order", map[string]string{"team": "order", "env": "prod"}),
+		newTenant("pay", map[string]string{"team": "pay", "env": "staging"}),
+		newTenant("bare", nil),
+	}
+
+	tests := []struct {
+		selector      string
+		expectedNames []string
+	}{
+		{"", []string{"order", "pay", "bare"}},
+		{"team=order", []string{"order"}},
+		{"team!=order", []string{"pay", "bare"}},
+		{"env in (prod,staging),team notin (pay)", []string{"order"}},
+		{"team", []string{"order", "pay"}},
+		{"!team", []string{"bare"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			sel, err := ParseSelector(tt.selector)
+			if err != nil {
+				t.Fatalf("parse selector failed: %v", err)
+			}
+
+			result := FilterObjects(objects, sel)
+			if len(result) != len(tt.expectedNames) {
+				t.Fatalf("expect %d objects but got %d", len(tt.expectedNames), len(result))
+			}
+			for i, object := range result {
+				if object.Name() != tt.expectedNames[i] {
+					t.Errorf("expect object %s but got %s", tt.expectedNames[i], object.Name())
+				}
+			}
+		})
+	}
+
+	if _, err := ParseSelector("team in (order"); err == nil {
+		t.Errorf("expect error for invalid selector")
+	}
+}
+
+func TestValidateSelectorKind(t *testing.T) {
+	tests := []struct {
+		selector string
+		kind     string
+		valid    bool
+	}{
+		{"", resource.KindTenant, true},
+		{"team=order", resource.KindServiceInstance, true},
+		{"team!=order", resource.KindServiceInstance, true},
+		{"team=order", resource.KindTenant, false},
+		{"team!=order", resource.KindService, false},
+		{"!team", resource.KindIngress, false},
+	}
+
+	for _, tt := range tests {
+		sel, err := ParseSelector(tt.selector)
+		if err != nil {
+			t.Fatalf("parse selector failed: %v", err)
+		}
+		err = ValidateSelectorKind(sel, tt.kind)
+		if tt.valid && err != nil {
+			t.Errorf("expect selector %q valid for %s but got %v", tt.selector, tt.kind, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("expect selector %q invalid for %s", tt.selector, tt.kind)
+		}
+	}
+}
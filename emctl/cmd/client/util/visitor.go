@@ -178,11 +178,11 @@ func newStreamVisitor(r io.Reader, decoder Decoder, source string) *streamVisito
 func (v *streamVisitor) Visit(fn VisitorFunc) error {
 	var errs []error
 	d := yaml.NewYAMLOrJSONDecoder(v.Reader, 4096)
-	for {
+	for document := 1; ; document++ {
 		ext := RawExtension{}
 		if err := d.Decode(&ext); err != nil {
 			if err != io.EOF {
-				errs = append(errs, errors.Errorf("error parsing %s: %v", v.Source, err))
+				errs = append(errs, errors.Errorf("error parsing %s (document %d): %v", v.Source, document, err))
 			}
 			break
 		}
@@ -196,6 +196,9 @@ func (v *streamVisitor) Visit(fn VisitorFunc) error {
 			continue
 		}
 		info, err := v.decodeMeshObject(jsonBuff, v.Source)
+		if err != nil {
+			err = errors.Wrapf(err, "%s (document %d)", v.Source, document)
+		}
 
 		err1 := fn(info, err)
 		if err1 != nil {
@@ -315,6 +318,8 @@ func adaptCommandKind(kind string) string {
 		return resource.KindResilience
 	case low(resource.KindIngress):
 		return resource.KindIngress
+	case low(resource.KindServiceInstance):
+		return resource.KindServiceInstance
 	default:
 		return kind
 	}
@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+)
+
+// NewTLSConfig creates a TLS config with the certificate authority to verify
+// the server, and the client certificate for the mutual TLS, all of them are
+// optional.
+func NewTLSConfig(caFile, certFile, keyFile string, insecureSkipVerify bool) (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: insecureSkipVerify}
+
+	if caFile != "" {
+		caPEM, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read certificate authority %s", caFile)
+		}
+		config.RootCAs, err = NewCertPool(caPEM)
+		if err != nil {
+			return nil, errors.Wrapf(err, "load certificate authority %s", caFile)
+		}
+	}
+
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, errors.Errorf("client certificate and client key must be specified together")
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "load client certificate %s and key %s", certFile, keyFile)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// NewCertPool creates a certificate pool from PEM encoded certificates
+func NewCertPool(caPEM []byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.Errorf("no valid PEM certificate found")
+	}
+	return pool, nil
+}
+
+// WrapTLSOption wraps option to access the server with TLS
+func WrapTLSOption(config *tls.Config) Option {
+	return func(client *resty.Client) {
+		client.SetTLSClientConfig(config)
+	}
+}
+
+// WrapBearerTokenOption wraps option to authenticate with the bearer token
+func WrapBearerTokenOption(token string) Option {
+	return func(client *resty.Client) {
+		client.SetAuthToken(token)
+	}
+}
+
+// WrapBasicAuthOption wraps option to authenticate with the username and password
+func WrapBasicAuthOption(username, password string) Option {
+	return func(client *resty.Client) {
+		client.SetBasicAuth(username, password)
+	}
+}
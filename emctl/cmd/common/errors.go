@@ -52,3 +52,9 @@ func OutputError(err error) {
 		fmt.Fprintf(os.Stderr, "%s\n", err)
 	}
 }
+
+// OutputWarningf outputs a warning information
+func OutputWarningf(format string, a ...interface{}) {
+	color.New(color.FgYellow).Fprintf(os.Stderr, "Warning: ")
+	fmt.Fprintf(os.Stderr, format+"\n", a...)
+}
@@ -24,6 +24,9 @@ import (
 
 	meshv1beta1 "github.com/megaease/easemesh/mesh-operator/pkg/api/v1beta1"
 	"github.com/megaease/easemesh/mesh-operator/pkg/controllers"
+	"github.com/megaease/easemesh/mesh-operator/pkg/meshclient"
+	"github.com/megaease/easemesh/mesh-operator/pkg/sidecar"
+	meshwebhook "github.com/megaease/easemesh/mesh-operator/pkg/webhook"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -35,6 +38,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	// +kubebuilder:scaffold:imports
 	"gopkg.in/yaml.v2"
@@ -63,6 +67,17 @@ type ConfigSpec struct {
 	MetricsAddr          string `yaml:"metrics-bind-address" jsonschema:"required"`
 	EnableLeaderElection bool   `yaml:"leader-elect" jsonschema:"required"`
 	ProbeAddr            string `yaml:"health-probe-bind-address" jsonschema:"required"`
+	EnableWebhook        bool   `yaml:"enable-webhook"`
+	ServiceNameKey       string `yaml:"service-name-key"`
+	MeshServer           string `yaml:"mesh-server"`
+
+	MeshServerCA                    string `yaml:"mesh-server-ca"`
+	MeshServerClientCert            string `yaml:"mesh-server-client-cert"`
+	MeshServerClientKey             string `yaml:"mesh-server-client-key"`
+	MeshServerInsecureSkipTLSVerify bool   `yaml:"mesh-server-insecure-skip-tls-verify"`
+	MeshServerToken                 string `yaml:"mesh-server-token"`
+	MeshServerUsername              string `yaml:"mesh-server-username"`
+	MeshServerPassword              string `yaml:"mesh-server-password"`
 }
 
 func main() {
@@ -72,6 +87,10 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var enableWebhook bool
+	var serviceNameKey string
+	var meshServer string
+	meshServerOptions := &meshclient.Options{}
 	var configFile string
 
 	flag.StringVar(&imageRegistryURL, "image-registry-url", DefaultImageRegistryURL, "The Registry URL of the Image.")
@@ -81,6 +100,25 @@ func main() {
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false, "Enable leader election for controller manager. "+
 		"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&enableWebhook, "enable-webhook", false, "Enable the mutating webhook injecting the sidecar into "+
+		"Deployments and Pods annotated with "+sidecar.ServiceNameAnnotation+" or in namespaces labeled with "+
+		sidecar.InjectKey+"="+sidecar.InjectEnabled+".")
+	flag.StringVar(&serviceNameKey, "service-name-key", sidecar.DefaultServiceNameKey, "The label or annotation "+
+		"whose value is the mesh service name of the workloads in namespaces labeled with "+sidecar.InjectKey+"="+sidecar.InjectEnabled+".")
+	flag.StringVar(&meshServer, "mesh-server", "", "The comma separated endpoints of the API of the EaseMesh "+
+		"control plane, e.g. easemesh-controlplane-svc.easemesh:2381, the mesh services of the MeshDeployments "+
+		"with the register tenant are registered in it.")
+	flag.StringVar(&meshServerOptions.CertificateAuthority, "mesh-server-ca", "", "Path to a cert file for the "+
+		"certificate authority of the control plane, the endpoints without scheme are accessed by https with any TLS flag.")
+	flag.StringVar(&meshServerOptions.ClientCertificate, "mesh-server-client-cert", "", "Path to a client certificate "+
+		"file for TLS to the control plane.")
+	flag.StringVar(&meshServerOptions.ClientKey, "mesh-server-client-key", "", "Path to a client key file for TLS "+
+		"to the control plane.")
+	flag.BoolVar(&meshServerOptions.InsecureSkipTLSVerify, "mesh-server-insecure-skip-tls-verify", false, "Whether "+
+		"to skip verifying the certificate of the control plane.")
+	flag.StringVar(&meshServerOptions.Token, "mesh-server-token", "", "Bearer token for authentication to the control plane.")
+	flag.StringVar(&meshServerOptions.Username, "mesh-server-username", "", "Username for basic authentication to the control plane.")
+	flag.StringVar(&meshServerOptions.Password, "mesh-server-password", "", "Password for basic authentication to the control plane.")
 	flag.StringVar(&configFile, "config", " ", "A yaml file config the operator. ")
 	opts := zap.Options{
 		Development: true,
@@ -107,6 +145,34 @@ func main() {
 		metricsAddr = spec.MetricsAddr
 		probeAddr = spec.ProbeAddr
 		enableLeaderElection = spec.EnableLeaderElection
+		enableWebhook = spec.EnableWebhook
+		if spec.ServiceNameKey != "" {
+			serviceNameKey = spec.ServiceNameKey
+		}
+		if spec.MeshServer != "" {
+			meshServer = spec.MeshServer
+		}
+		if spec.MeshServerCA != "" {
+			meshServerOptions.CertificateAuthority = spec.MeshServerCA
+		}
+		if spec.MeshServerClientCert != "" {
+			meshServerOptions.ClientCertificate = spec.MeshServerClientCert
+		}
+		if spec.MeshServerClientKey != "" {
+			meshServerOptions.ClientKey = spec.MeshServerClientKey
+		}
+		if spec.MeshServerInsecureSkipTLSVerify {
+			meshServerOptions.InsecureSkipTLSVerify = true
+		}
+		if spec.MeshServerToken != "" {
+			meshServerOptions.Token = spec.MeshServerToken
+		}
+		if spec.MeshServerUsername != "" {
+			meshServerOptions.Username = spec.MeshServerUsername
+		}
+		if spec.MeshServerPassword != "" {
+			meshServerOptions.Password = spec.MeshServerPassword
+		}
 
 	}
 
@@ -125,6 +191,15 @@ func main() {
 		os.Exit(1)
 	}
 
+	var meshClient meshclient.Client
+	if meshServer != "" {
+		meshClient, err = meshclient.NewWithOptions(meshServer, meshServerOptions)
+		if err != nil {
+			setupLog.Error(err, "unable to create client of the mesh control plane")
+			os.Exit(1)
+		}
+	}
+
 	if err = (&controllers.MeshDeploymentReconciler{
 		Client:           mgr.GetClient(),
 		Log:              ctrl.Log.WithName("controllers").WithName("MeshDeployment"),
@@ -133,10 +208,46 @@ func main() {
 		ClusterName:      clusterName,
 		ImageRegistryURL: imageRegistryURL,
 		Recorder:         mgr.GetEventRecorderFor("controller.MeshDeployment"),
+		MeshClient:       meshClient,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MeshDeployment")
 		os.Exit(1)
 	}
+
+	injector := &sidecar.Injector{
+		ImageRegistryURL: imageRegistryURL,
+		ClusterJoinURL:   clusterJoinURL,
+		ClusterName:      clusterName,
+	}
+	policy := &sidecar.Policy{ServiceNameKey: serviceNameKey}
+
+	if err = (&controllers.NamespaceReconciler{
+		Client:   mgr.GetClient(),
+		Log:      ctrl.Log.WithName("controllers").WithName("Namespace"),
+		Recorder: mgr.GetEventRecorderFor("controller.Namespace"),
+		Injector: injector,
+		Policy:   policy,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Namespace")
+		os.Exit(1)
+	}
+
+	if enableWebhook {
+		webhookLog := ctrl.Log.WithName("webhooks")
+		server := mgr.GetWebhookServer()
+		server.Register(meshwebhook.DeploymentPath, &webhook.Admission{Handler: &meshwebhook.DeploymentInjector{
+			Client:   mgr.GetClient(),
+			Injector: injector,
+			Policy:   policy,
+			Log:      webhookLog.WithName("Deployment"),
+		}})
+		server.Register(meshwebhook.PodPath, &webhook.Admission{Handler: &meshwebhook.PodInjector{
+			Client:   mgr.GetClient(),
+			Injector: injector,
+			Policy:   policy,
+			Log:      webhookLog.WithName("Pod"),
+		}})
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("health", healthz.Ping); err != nil {
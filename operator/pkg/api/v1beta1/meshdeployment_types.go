@@ -20,6 +20,7 @@ package v1beta1
 import (
 	v1 "k8s.io/api/apps/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -35,6 +36,46 @@ type ServiceSpec struct {
 	//Labels is dedicated to labeling instance of deployment for traffic control
 	// +kubebuilder:validation:Optional
 	Labels map[string]string `json:"labels"`
+
+	// RegisterTenant is the tenant which the mesh service registers to, the
+	// operator registers the mesh service in the control plane if it's set,
+	// and deregisters it when the MeshDeployment is deleted.
+	// +optional
+	RegisterTenant string `json:"registerTenant,omitempty"`
+	// Sidecar is the sidecar of the mesh service, the one listening on the
+	// injected ports with eureka discovery is used if it's absent.
+	// +optional
+	Sidecar *SidecarSpec `json:"sidecar,omitempty"`
+	// LoadBalance is the load balance of the mesh service.
+	// +optional
+	LoadBalance *LoadBalanceSpec `json:"loadBalance,omitempty"`
+	// Resilience is the resilience of the mesh service, it's the same as
+	// the resilience of the Service of the EaseMesh API.
+	// +optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Resilience *runtime.RawExtension `json:"resilience,omitempty"`
+}
+
+// SidecarSpec describes the sidecar of the mesh service
+type SidecarSpec struct {
+	// +kubebuilder:validation:Enum=eureka;consul;nacos
+	DiscoveryType string `json:"discoveryType"`
+	Address       string `json:"address"`
+	IngressPort   int32  `json:"ingressPort"`
+	// +kubebuilder:validation:Enum=http
+	IngressProtocol string `json:"ingressProtocol"`
+	EgressPort      int32  `json:"egressPort"`
+	// +kubebuilder:validation:Enum=http
+	EgressProtocol string `json:"egressProtocol"`
+}
+
+// LoadBalanceSpec describes the load balance of the mesh service
+type LoadBalanceSpec struct {
+	// +kubebuilder:validation:Enum=roundRobin;random;weightedRandom;ipHash;headerHash
+	Policy string `json:"policy"`
+	// HeaderHashKey is the header whose value is hashed for the headerHash policy.
+	// +optional
+	HeaderHashKey string `json:"headerHashKey,omitempty"`
 }
 
 // DeploySpec is the specification of the desired behavior of the Deployment.
@@ -55,15 +96,94 @@ type MeshDeploymentSpec struct {
 	Deploy DeploySpec `json:"deploy,omitempty"`
 }
 
+// The types of the conditions of MeshDeployment
+const (
+	// ConditionSidecarInjected is true if the sidecar is injected into
+	// the Deployment.
+	ConditionSidecarInjected = "SidecarInjected"
+	// ConditionDeploymentAvailable is the Available condition of the
+	// Deployment, it's false if the Deployment doesn't exist.
+	ConditionDeploymentAvailable = "DeploymentAvailable"
+	// ConditionRegisteredInMesh is true if any pod of the Deployment is
+	// registered as an instance of the mesh service in the control plane,
+	// it's unknown if the control plane isn't configured or fails.
+	ConditionRegisteredInMesh = "RegisteredInMesh"
+	// ConditionDegraded is true if the Deployment failed to sync, or not all
+	// the desired replicas are available.
+	ConditionDegraded = "Degraded"
+	// ConditionServiceRegistered is true if the mesh service is registered
+	// in the control plane by the operator, it's absent if the spec has no
+	// register tenant.
+	ConditionServiceRegistered = "ServiceRegistered"
+	// ConditionCleanupBlocked is true if the MeshDeployment is being deleted
+	// and the cleanup in the control plane failed, the deletion is retried
+	// until the attempts run out.
+	ConditionCleanupBlocked = "CleanupBlocked"
+)
+
 // MeshDeploymentStatus defines the observed state of MeshDeployment
 type MeshDeploymentStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// ObservedGeneration is the generation of the MeshDeployment observed
+	// by the operator.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// ServiceName is the name of the mesh service resolved from the spec.
+	// +optional
+	ServiceName string `json:"serviceName,omitempty"`
+	// SidecarImage is the image of the sidecar injected into the Deployment.
+	// +optional
+	SidecarImage string `json:"sidecarImage,omitempty"`
+	// SidecarVersion is the tag of SidecarImage.
+	// +optional
+	SidecarVersion string `json:"sidecarVersion,omitempty"`
+
+	// Replicas, ReadyReplicas, UpdatedReplicas and AvailableReplicas are
+	// copied from the status of the Deployment.
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+	// +optional
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+	// +optional
+	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+	// +optional
+	AvailableReplicas int32 `json:"availableReplicas,omitempty"`
+
+	// CleanupAttempts is the number of the failed attempts to clean up the
+	// MeshDeployment in the control plane on deletion.
+	// +optional
+	CleanupAttempts int32 `json:"cleanupAttempts,omitempty"`
+
+	// LastCleanupAttemptTime is the time of the last failed attempt to clean
+	// up the MeshDeployment, the next attempt is made cleanupRetryInterval
+	// after it.
+	// +optional
+	LastCleanupAttemptTime *metav1.Time `json:"lastCleanupAttemptTime,omitempty"`
+
+	// PendingInstances are the names of the pods seen on deletion, which
+	// are deregistered from the control plane as the instance IDs after
+	// the pods are gone.
+	// +optional
+	PendingInstances []string `json:"pendingInstances,omitempty"`
+
+	// Conditions are SidecarInjected, DeploymentAvailable, RegisteredInMesh,
+	// Degraded, ServiceRegistered and CleanupBlocked.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:path=meshdeployments,scope=Namespaced
+// +kubebuilder:printcolumn:name="Service",type=string,JSONPath=`.status.serviceName`
+// +kubebuilder:printcolumn:name="Desired",type=integer,JSONPath=`.spec.deploy.replicas`
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.readyReplicas`
+// +kubebuilder:printcolumn:name="Available",type=integer,JSONPath=`.status.availableReplicas`
+// +kubebuilder:printcolumn:name="Sidecar",type=string,JSONPath=`.status.sidecarVersion`
+// +kubebuilder:printcolumn:name="Registered",type=string,JSONPath=`.status.conditions[?(@.type=="RegisteredInMesh")].status`
+// +kubebuilder:printcolumn:name="Degraded",type=string,JSONPath=`.status.conditions[?(@.type=="Degraded")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // MeshDeployment is the Schema for the meshdeployments API
 type MeshDeployment struct {
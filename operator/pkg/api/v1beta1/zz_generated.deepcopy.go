@@ -5,6 +5,7 @@
 package v1beta1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -24,13 +25,28 @@ func (in *DeploySpec) DeepCopy() *DeploySpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LoadBalanceSpec) DeepCopyInto(out *LoadBalanceSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalanceSpec.
+func (in *LoadBalanceSpec) DeepCopy() *LoadBalanceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LoadBalanceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MeshDeployment) DeepCopyInto(out *MeshDeployment) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshDeployment.
@@ -103,6 +119,22 @@ func (in *MeshDeploymentSpec) DeepCopy() *MeshDeploymentSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MeshDeploymentStatus) DeepCopyInto(out *MeshDeploymentStatus) {
 	*out = *in
+	if in.LastCleanupAttemptTime != nil {
+		in, out := &in.LastCleanupAttemptTime, &out.LastCleanupAttemptTime
+		*out = (*in).DeepCopy()
+	}
+	if in.PendingInstances != nil {
+		in, out := &in.PendingInstances, &out.PendingInstances
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MeshDeploymentStatus.
@@ -125,6 +157,21 @@ func (in *ServiceSpec) DeepCopyInto(out *ServiceSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.Sidecar != nil {
+		in, out := &in.Sidecar, &out.Sidecar
+		*out = new(SidecarSpec)
+		**out = **in
+	}
+	if in.LoadBalance != nil {
+		in, out := &in.LoadBalance, &out.LoadBalance
+		*out = new(LoadBalanceSpec)
+		**out = **in
+	}
+	if in.Resilience != nil {
+		in, out := &in.Resilience, &out.Resilience
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceSpec.
@@ -136,3 +183,18 @@ func (in *ServiceSpec) DeepCopy() *ServiceSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SidecarSpec) DeepCopyInto(out *SidecarSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SidecarSpec.
+func (in *SidecarSpec) DeepCopy() *SidecarSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SidecarSpec)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,276 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	meshv1beta1 "github.com/megaease/easemesh/mesh-operator/pkg/api/v1beta1"
+
+	"github.com/juju/errors"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// cleanupFinalizer holds the MeshDeployment on deletion until it's
+	// cleaned up in the control plane and the cluster.
+	cleanupFinalizer = "mesh.megaease.com/cleanup"
+
+	// meshDeploymentLabel labels the ConfigMaps and the Secrets created for
+	// a MeshDeployment with its name.
+	meshDeploymentLabel = "mesh.megaease.com/meshdeployment"
+
+	maxCleanupAttempts   = 5
+	cleanupRetryInterval = 30 * time.Second
+
+	// The pods are waited for podTerminationTimeout at most after the
+	// deletion, their instances are deregistered anyway after it.
+	podTerminationTimeout      = 2 * time.Minute
+	podTerminationPollInterval = 5 * time.Second
+
+	reasonCleanupFailed = "CleanupFailed"
+)
+
+// ensureFinalizer adds the finalizer to the MeshDeployment before anything
+// is created for it, so that nothing outlives it unnoticed.
+func (r *MeshDeploymentReconciler) ensureFinalizer(ctx context.Context, meshDeploy *meshv1beta1.MeshDeployment) error {
+	if controllerutil.ContainsFinalizer(meshDeploy, cleanupFinalizer) {
+		return nil
+	}
+	controllerutil.AddFinalizer(meshDeploy, cleanupFinalizer)
+	err := r.Client.Update(ctx, meshDeploy)
+	if err != nil {
+		return errors.Annotatef(err, "add finalizer %s", cleanupFinalizer)
+	}
+	return nil
+}
+
+// finalize cleans up the deleted MeshDeployment and releases it. Its
+// Deployment is scaled down first, then the instances of the pods and the
+// mesh service are deregistered from the control plane, the failures are
+// retried every cleanupRetryInterval, the MeshDeployment is released with
+// the leftovers in the control plane after maxCleanupAttempts attempts. The
+// ConfigMaps and the Secrets it controls are deleted at last, the errors of
+// the cluster are always retried.
+func (r *MeshDeploymentReconciler) finalize(ctx context.Context, meshDeploy *meshv1beta1.MeshDeployment) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(meshDeploy, cleanupFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	// The status update of a failed attempt triggers another reconciliation
+	// at once, the attempt is made only when the retry is due.
+	if last := meshDeploy.Status.LastCleanupAttemptTime; last != nil {
+		if wait := time.Until(last.Add(cleanupRetryInterval)); wait > 0 {
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+	}
+
+	// The sidecars keep their instances registered while the pods run.
+	result, stopped, err := r.stopPods(ctx, meshDeploy)
+	if err != nil || !stopped {
+		return result, err
+	}
+
+	sharedWith, err := r.serviceSharedWith(ctx, meshDeploy)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.cleanUpControlPlane(ctx, meshDeploy, meshDeploy.Status.PendingInstances, sharedWith)
+	if err != nil {
+		attempts := meshDeploy.Status.CleanupAttempts + 1
+		if attempts < maxCleanupAttempts {
+			message := fmt.Sprintf("attempt %d of %d failed, retry in %s: %v", attempts, maxCleanupAttempts, cleanupRetryInterval, err)
+			r.Recorder.Event(meshDeploy, corev1.EventTypeWarning, reasonCleanupFailed, message)
+
+			now := metav1.Now()
+			meshDeploy.Status.CleanupAttempts = attempts
+			meshDeploy.Status.LastCleanupAttemptTime = &now
+			meta.SetStatusCondition(&meshDeploy.Status.Conditions, metav1.Condition{
+				Type:               meshv1beta1.ConditionCleanupBlocked,
+				Status:             metav1.ConditionTrue,
+				ObservedGeneration: meshDeploy.Generation,
+				Reason:             reasonCleanupFailed,
+				Message:            message,
+			})
+			err = r.Client.Status().Update(ctx, meshDeploy)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: cleanupRetryInterval}, nil
+		}
+
+		r.Recorder.Eventf(meshDeploy, corev1.EventTypeWarning, "CleanupAbandoned",
+			"give up cleaning up in the control plane after %d attempts, the leftovers of mesh service %s are left to emctl: %v",
+			attempts, meshDeploy.Spec.Service.Name, err)
+	}
+
+	err = r.deleteOwnedObjects(ctx, meshDeploy)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	r.Recorder.Event(meshDeploy, corev1.EventTypeNormal, "CleanedUp", "MeshDeployment is cleaned up and released")
+	controllerutil.RemoveFinalizer(meshDeploy, cleanupFinalizer)
+	return ctrl.Result{}, r.Client.Update(ctx, meshDeploy)
+}
+
+// cleanUpControlPlane deregisters the instances of the pods, and the mesh
+// service if the MeshDeployment registered it and no other one shares it.
+func (r *MeshDeploymentReconciler) cleanUpControlPlane(ctx context.Context, meshDeploy *meshv1beta1.MeshDeployment,
+	pods []string, sharedWith *meshv1beta1.MeshDeployment) error {
+	if r.MeshClient == nil {
+		// The deletion must not be blocked forever by the operator
+		// configured without the control plane.
+		r.Recorder.Eventf(meshDeploy, corev1.EventTypeWarning, "CleanupSkipped",
+			"mesh service %s is not cleaned up in the control plane: %v", meshDeploy.Spec.Service.Name, errNoControlPlane)
+		return nil
+	}
+
+	err := r.deregisterInstances(ctx, meshDeploy, pods)
+	if err != nil {
+		return err
+	}
+
+	if meshDeploy.Spec.Service.RegisterTenant == "" {
+		return nil
+	}
+	if sharedWith != nil {
+		r.Recorder.Eventf(meshDeploy, corev1.EventTypeNormal, "ServiceKept",
+			"mesh service %s is kept for MeshDeployment %s/%s", meshDeploy.Spec.Service.Name, sharedWith.Namespace, sharedWith.Name)
+		return nil
+	}
+	return r.deregisterService(ctx, meshDeploy)
+}
+
+// stopPods scales down the Deployment of the MeshDeployment and reports
+// whether its pods are gone. The names of the pods are kept in the status
+// as the instances to deregister, as they're unknown after the pods are
+// gone. The pods not terminated in podTerminationTimeout are left behind.
+func (r *MeshDeploymentReconciler) stopPods(ctx context.Context, meshDeploy *meshv1beta1.MeshDeployment) (ctrl.Result, bool, error) {
+	deploy := &v1.Deployment{}
+	err := r.Client.Get(ctx, types.NamespacedName{Namespace: meshDeploy.Namespace, Name: meshDeploy.Name}, deploy)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, true, nil
+		}
+		return ctrl.Result{}, false, err
+	}
+	if !metav1.IsControlledBy(deploy, meshDeploy) {
+		return ctrl.Result{}, true, nil
+	}
+
+	pods, err := r.listPods(ctx, deploy)
+	if err != nil {
+		return ctrl.Result{}, false, err
+	}
+
+	pending := map[string]bool{}
+	for _, name := range meshDeploy.Status.PendingInstances {
+		pending[name] = true
+	}
+	updated := false
+	for _, pod := range pods {
+		if !pending[pod.Name] {
+			meshDeploy.Status.PendingInstances = append(meshDeploy.Status.PendingInstances, pod.Name)
+			updated = true
+		}
+	}
+	if updated {
+		err = r.Client.Status().Update(ctx, meshDeploy)
+		if err != nil {
+			return ctrl.Result{}, false, err
+		}
+	}
+
+	if deploy.Spec.Replicas == nil || *deploy.Spec.Replicas != 0 {
+		replicas := int32(0)
+		deploy.Spec.Replicas = &replicas
+		err = r.Client.Update(ctx, deploy)
+		if err != nil {
+			return ctrl.Result{}, false, errors.Annotatef(err, "scale down Deployment %s", deploy.Name)
+		}
+		r.Recorder.Eventf(meshDeploy, corev1.EventTypeNormal, "DeploymentScaledDown",
+			"Deployment %s is scaled down to deregister its instances", deploy.Name)
+	}
+
+	if len(pods) == 0 {
+		return ctrl.Result{}, true, nil
+	}
+	if time.Since(meshDeploy.DeletionTimestamp.Time) >= podTerminationTimeout {
+		r.Recorder.Eventf(meshDeploy, corev1.EventTypeWarning, "PodsNotTerminated",
+			"%d pods are not terminated in %s, their instances are deregistered anyway", len(pods), podTerminationTimeout)
+		return ctrl.Result{}, true, nil
+	}
+	return ctrl.Result{RequeueAfter: podTerminationPollInterval}, false, nil
+}
+
+// deleteOwnedObjects deletes the ConfigMaps and the Secrets created for the
+// MeshDeployment, instead of waiting for the garbage collector, which
+// leaves them if the MeshDeployment is deleted with the orphan policy.
+func (r *MeshDeploymentReconciler) deleteOwnedObjects(ctx context.Context, meshDeploy *meshv1beta1.MeshDeployment) error {
+	listOptions := []client.ListOption{
+		client.InNamespace(meshDeploy.Namespace),
+		client.MatchingLabels{meshDeploymentLabel: meshDeploy.Name},
+	}
+
+	configMaps := &corev1.ConfigMapList{}
+	err := r.Client.List(ctx, configMaps, listOptions...)
+	if err != nil {
+		return errors.Annotate(err, "list ConfigMaps")
+	}
+	secrets := &corev1.SecretList{}
+	err = r.Client.List(ctx, secrets, listOptions...)
+	if err != nil {
+		return errors.Annotate(err, "list Secrets")
+	}
+
+	type object struct {
+		client.Object
+		kind string
+	}
+	objects := []object{}
+	for i := range configMaps.Items {
+		objects = append(objects, object{Object: &configMaps.Items[i], kind: "ConfigMap"})
+	}
+	for i := range secrets.Items {
+		objects = append(objects, object{Object: &secrets.Items[i], kind: "Secret"})
+	}
+
+	for _, obj := range objects {
+		if !metav1.IsControlledBy(obj.Object, meshDeploy) {
+			continue
+		}
+		err := r.Client.Delete(ctx, obj.Object)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return errors.Annotatef(err, "delete %s %s", obj.kind, obj.GetName())
+		}
+		r.Recorder.Eventf(meshDeploy, corev1.EventTypeNormal, "ObjectDeleted", "%s %s is deleted", obj.kind, obj.GetName())
+	}
+	return nil
+}
@@ -22,11 +22,12 @@ import (
 
 	meshv1beta1 "github.com/megaease/easemesh/mesh-operator/pkg/api/v1beta1"
 	"github.com/megaease/easemesh/mesh-operator/pkg/controllers/resourcesyncer"
+	"github.com/megaease/easemesh/mesh-operator/pkg/meshclient"
 	"github.com/megaease/easemesh/mesh-operator/pkg/syncer"
 
 	"github.com/go-logr/logr"
-	"github.com/juju/errors"
 	v1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -43,13 +44,18 @@ type MeshDeploymentReconciler struct {
 	ClusterJoinURL   string
 	ImageRegistryURL string
 	ClusterName      string
+	// MeshClient registers the mesh services in the control plane, the
+	// MeshDeployments with the register tenant fail to register without it.
+	MeshClient meshclient.Client
 }
 
 // +kubebuilder:rbac:groups=mesh.megaease.com,resources=meshdeployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=mesh.megaease.com,resources=meshdeployments/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=mesh.megaease.com,resources=meshdeployments/finalizers,verbs=update
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;delete
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -66,8 +72,8 @@ func (r *MeshDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	meshDeploy := &meshv1beta1.MeshDeployment{}
 	err := r.Client.Get(context.TODO(), req.NamespacedName, meshDeploy)
 	if err != nil {
-		if errors.IsNotFound(err) {
-			// Object not found, return. Created objects are automatically garbage collected
+		if apierrors.IsNotFound(err) {
+			// Object not found, return. It's cleaned up by the finalizer before it's gone
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request
@@ -77,11 +83,41 @@ func (r *MeshDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	log := r.Log.WithValues("key", req.NamespacedName)
 	log.V(1).Info("deploy is", "meshdeployment", meshDeploy)
 
-	deploySyncer := resourcesyncer.NewDeploymentSyncer(r.Client, meshDeploy, r.Scheme, r.ClusterJoinURL, r.ClusterName, r.Log, r.ImageRegistryURL)
-	err = syncer.Sync(context.TODO(), deploySyncer, r.Recorder)
+	if !meshDeploy.DeletionTimestamp.IsZero() {
+		return r.finalize(context.TODO(), meshDeploy)
+	}
+
+	err = r.ensureFinalizer(context.TODO(), meshDeploy)
 	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	deploySyncer := resourcesyncer.NewDeploymentSyncer(r.Client, meshDeploy, r.Scheme, r.ClusterJoinURL, r.ClusterName, r.Log, r.ImageRegistryURL)
+	syncErr := syncer.Sync(context.TODO(), deploySyncer, r.Recorder)
+	if syncErr != nil {
 		log.V(1).Info("sync deployment resource error")
 	}
+	err = syncErr
+
+	var registerErr error
+	if meshDeploy.Spec.Service.RegisterTenant != "" {
+		registerErr = r.registerService(context.TODO(), meshDeploy)
+	}
+	if registerErr != nil {
+		log.Error(registerErr, "register mesh service error")
+		// Retrying doesn't help until the operator is configured with the control plane.
+		if err == nil && registerErr != errNoControlPlane {
+			err = registerErr
+		}
+	}
+
+	statusErr := r.updateStatus(context.TODO(), meshDeploy, syncErr, registerErr)
+	if statusErr != nil {
+		log.Error(statusErr, "update status error")
+		if err == nil {
+			err = statusErr
+		}
+	}
 
 	return ctrl.Result{}, err
 }
@@ -22,11 +22,15 @@ import (
 
 	"github.com/megaease/easemesh/mesh-operator/pkg/api/v1beta1"
 	"github.com/megaease/easemesh/mesh-operator/pkg/controllers/resourcesyncer"
+	"github.com/megaease/easemesh/mesh-operator/pkg/meshclient"
+	"github.com/megaease/easemesh/mesh-operator/pkg/meshclient/fake"
 	"github.com/megaease/easemesh/mesh-operator/pkg/syncer"
 
 	"github.com/go-logr/logr"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -96,6 +100,11 @@ var _ = Describe("meshdeployment controller", func() {
 	AfterEach(func() {
 		// remove created cluster
 		k8sClient.Delete(context.TODO(), &meshDeployment)
+		// Release the MeshDeployment the specs left with the finalizer.
+		if k8sClient.Get(context.TODO(), key, &meshDeployment) == nil {
+			meshDeployment.Finalizers = nil
+			k8sClient.Update(context.TODO(), &meshDeployment)
+		}
 	})
 	Context("normal deploy meshdeployment", func() {
 		deploy := v1.Deployment{}
@@ -112,6 +121,289 @@ var _ = Describe("meshdeployment controller", func() {
 		})
 	})
 
+	Context("reconcile meshdeployment", func() {
+		It("should report the observed state in status", func() {
+			reconciler := &MeshDeploymentReconciler{
+				Client:   k8sClient,
+				Log:      log,
+				Scheme:   scheme.Scheme,
+				Recorder: &mockRecorder{},
+			}
+			_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(context.TODO(), key, &meshDeployment)).To(Succeed())
+			status := meshDeployment.Status
+			Expect(status.ObservedGeneration).To(Equal(meshDeployment.Generation))
+			Expect(status.ServiceName).To(Equal("test-server"))
+			Expect(status.SidecarImage).To(Equal("/megaease/easegress:server-sidecar"))
+			Expect(status.SidecarVersion).To(Equal("server-sidecar"))
+
+			// There is no controller of Deployments in the test environment,
+			// so the Deployment never becomes available.
+			Expect(meta.IsStatusConditionTrue(status.Conditions, v1beta1.ConditionSidecarInjected)).To(BeTrue())
+			Expect(meta.FindStatusCondition(status.Conditions, v1beta1.ConditionDeploymentAvailable).Status).
+				To(Equal(metav1.ConditionUnknown))
+			Expect(meta.IsStatusConditionFalse(status.Conditions, v1beta1.ConditionRegisteredInMesh)).To(BeTrue())
+			degraded := meta.FindStatusCondition(status.Conditions, v1beta1.ConditionDegraded)
+			Expect(degraded.Status).To(Equal(metav1.ConditionTrue))
+			Expect(degraded.Reason).To(Equal(reasonReplicasUnavailable))
+		})
+
+		It("should report the pods registered in the control plane", func() {
+			server := fake.NewServer()
+			defer server.Close()
+			reconciler := &MeshDeploymentReconciler{
+				Client:   k8sClient,
+				Log:      log,
+				Scheme:   scheme.Scheme,
+				Recorder: &mockRecorder{},
+			}
+			_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-server-v1-5d9c7b-x2z9q",
+					Namespace: namespace,
+					Labels:    map[string]string{"app": "test-server"},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "test-server", Image: "zhaokundev/easestack-test-server:1.0-alpine"}},
+				},
+			}
+			Expect(k8sClient.Create(context.TODO(), pod)).To(Succeed())
+			defer k8sClient.Delete(context.TODO(), pod)
+
+			// Whether the pod is registered is unknown without the control
+			// plane.
+			_, err = reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(context.TODO(), key, &meshDeployment)).To(Succeed())
+			registered := meta.FindStatusCondition(meshDeployment.Status.Conditions, v1beta1.ConditionRegisteredInMesh)
+			Expect(registered.Status).To(Equal(metav1.ConditionUnknown))
+			Expect(registered.Reason).To(Equal(reasonNoControlPlane))
+
+			reconciler.MeshClient = meshclient.New(server.URL(), nil)
+			_, err = reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(context.TODO(), key, &meshDeployment)).To(Succeed())
+			Expect(meta.IsStatusConditionFalse(meshDeployment.Status.Conditions, v1beta1.ConditionRegisteredInMesh)).To(BeTrue())
+
+			server.AddServiceInstance(meshclient.ServiceInstance{ServiceName: "test-server", InstanceID: pod.Name})
+			_, err = reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(context.TODO(), key, &meshDeployment)).To(Succeed())
+			registered = meta.FindStatusCondition(meshDeployment.Status.Conditions, v1beta1.ConditionRegisteredInMesh)
+			Expect(registered.Status).To(Equal(metav1.ConditionTrue))
+			Expect(registered.Reason).To(Equal(reasonInstancesRegistered))
+		})
+	})
+
+	Context("register mesh service", func() {
+		It("should register the mesh service and deregister it on deletion", func() {
+			server := fake.NewServer()
+			defer server.Close()
+			// The canary was created by emctl, it must survive the registration.
+			server.AddService("test-server", meshclient.Service{
+				"name":   "test-server",
+				"canary": map[string]interface{}{"canaryRules": []interface{}{}},
+			})
+
+			reconciler := &MeshDeploymentReconciler{
+				Client:     k8sClient,
+				Log:        log,
+				Scheme:     scheme.Scheme,
+				Recorder:   &mockRecorder{},
+				MeshClient: meshclient.New(server.URL(), nil),
+			}
+
+			meshDeployment.Spec.Service.RegisterTenant = "pet"
+			meshDeployment.Spec.Service.LoadBalance = &v1beta1.LoadBalanceSpec{Policy: "random"}
+			Expect(k8sClient.Update(context.TODO(), &meshDeployment)).To(Succeed())
+
+			_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			service := server.Service("test-server")
+			Expect(service["registerTenant"]).To(Equal("pet"))
+			Expect(service["loadBalance"]).To(Equal(map[string]interface{}{"policy": "random"}))
+			Expect(service["sidecar"]).To(HaveKeyWithValue("ingressPort", float64(13001)))
+			Expect(service).To(HaveKey("canary"))
+
+			Expect(k8sClient.Get(context.TODO(), key, &meshDeployment)).To(Succeed())
+			Expect(meshDeployment.Finalizers).To(ContainElement(cleanupFinalizer))
+			Expect(meta.IsStatusConditionTrue(meshDeployment.Status.Conditions, v1beta1.ConditionServiceRegistered)).To(BeTrue())
+
+			// Nothing changes, the service isn't updated again.
+			requests := len(server.Requests())
+			_, err = reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(server.Requests()).To(HaveLen(requests + 1))
+
+			Expect(k8sClient.Delete(context.TODO(), &meshDeployment)).To(Succeed())
+			_, err = reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(server.Service("test-server")).To(BeNil())
+			err = k8sClient.Get(context.TODO(), key, &meshDeployment)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should report the failure without the control plane", func() {
+			reconciler := &MeshDeploymentReconciler{
+				Client:   k8sClient,
+				Log:      log,
+				Scheme:   scheme.Scheme,
+				Recorder: &mockRecorder{},
+			}
+
+			meshDeployment.Spec.Service.RegisterTenant = "pet"
+			Expect(k8sClient.Update(context.TODO(), &meshDeployment)).To(Succeed())
+
+			_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(context.TODO(), key, &meshDeployment)).To(Succeed())
+			Expect(meshDeployment.Finalizers).To(ContainElement(cleanupFinalizer))
+			registered := meta.FindStatusCondition(meshDeployment.Status.Conditions, v1beta1.ConditionServiceRegistered)
+			Expect(registered.Status).To(Equal(metav1.ConditionFalse))
+			Expect(registered.Reason).To(Equal(reasonRegistrationFailed))
+		})
+	})
+
+	Context("clean up meshdeployment", func() {
+		It("should deregister the instances after the pods are gone and delete the owned objects before releasing it", func() {
+			server := fake.NewServer()
+			defer server.Close()
+			reconciler := &MeshDeploymentReconciler{
+				Client:     k8sClient,
+				Log:        log,
+				Scheme:     scheme.Scheme,
+				Recorder:   &mockRecorder{},
+				MeshClient: meshclient.New(server.URL(), nil),
+			}
+			_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(context.TODO(), key, &meshDeployment)).To(Succeed())
+			Expect(meshDeployment.Finalizers).To(ContainElement(cleanupFinalizer))
+
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-server-v1-5d9c7b-x2z9q",
+					Namespace: namespace,
+					Labels:    map[string]string{"app": "test-server"},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "test-server", Image: "zhaokundev/easestack-test-server:1.0-alpine"}},
+				},
+			}
+			Expect(k8sClient.Create(context.TODO(), pod)).To(Succeed())
+			defer k8sClient.Delete(context.TODO(), pod)
+
+			owned := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-server-v1-sidecar",
+					Namespace: namespace,
+					Labels:    map[string]string{meshDeploymentLabel: meshDeploymentName},
+				},
+			}
+			Expect(ctrl.SetControllerReference(&meshDeployment, owned, scheme.Scheme)).To(Succeed())
+			Expect(k8sClient.Create(context.TODO(), owned)).To(Succeed())
+
+			instance := meshclient.ServiceInstance{ServiceName: "test-server", InstanceID: pod.Name}
+			server.AddServiceInstance(instance)
+			other := meshclient.ServiceInstance{ServiceName: "test-server", InstanceID: "test-server-v2-7f6d8c-k8s2p"}
+			server.AddServiceInstance(other)
+
+			// The Deployment is scaled down, the instances are kept while
+			// the pods are running.
+			Expect(k8sClient.Delete(context.TODO(), &meshDeployment)).To(Succeed())
+			result, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(podTerminationPollInterval))
+
+			deploy := &v1.Deployment{}
+			Expect(k8sClient.Get(context.TODO(), key, deploy)).To(Succeed())
+			Expect(*deploy.Spec.Replicas).To(Equal(int32(0)))
+			Expect(k8sClient.Get(context.TODO(), key, &meshDeployment)).To(Succeed())
+			Expect(meshDeployment.Status.PendingInstances).To(Equal([]string{pod.Name}))
+			Expect(server.ServiceInstances()).To(ConsistOf(instance, other))
+
+			// The pods are gone, the instances are deregistered.
+			Expect(k8sClient.Delete(context.TODO(), pod)).To(Succeed())
+			_, err = reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(server.ServiceInstances()).To(Equal([]meshclient.ServiceInstance{other}))
+			err = k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: owned.Name}, owned)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			err = k8sClient.Get(context.TODO(), key, &meshDeployment)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should retry the unreachable control plane for bounded attempts", func() {
+			server := fake.NewServer()
+			url := server.URL()
+			server.Close()
+			reconciler := &MeshDeploymentReconciler{
+				Client:     k8sClient,
+				Log:        log,
+				Scheme:     scheme.Scheme,
+				Recorder:   &mockRecorder{},
+				MeshClient: meshclient.New(url, nil),
+			}
+			_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Delete(context.TODO(), &meshDeployment)).To(Succeed())
+
+			// elapse pretends the retry interval has passed since the last attempt.
+			elapse := func() {
+				Expect(k8sClient.Get(context.TODO(), key, &meshDeployment)).To(Succeed())
+				last := metav1.NewTime(meshDeployment.Status.LastCleanupAttemptTime.Add(-cleanupRetryInterval))
+				meshDeployment.Status.LastCleanupAttemptTime = &last
+				Expect(k8sClient.Status().Update(context.TODO(), &meshDeployment)).To(Succeed())
+			}
+
+			for attempts := int32(1); attempts < maxCleanupAttempts; attempts++ {
+				result, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(Equal(cleanupRetryInterval))
+
+				Expect(k8sClient.Get(context.TODO(), key, &meshDeployment)).To(Succeed())
+				Expect(meshDeployment.Status.CleanupAttempts).To(Equal(attempts))
+				Expect(meshDeployment.Status.LastCleanupAttemptTime).NotTo(BeNil())
+				blocked := meta.FindStatusCondition(meshDeployment.Status.Conditions, v1beta1.ConditionCleanupBlocked)
+				Expect(blocked.Status).To(Equal(metav1.ConditionTrue))
+				Expect(blocked.Reason).To(Equal(reasonCleanupFailed))
+
+				// The reconciliation triggered by the status update isn't
+				// counted as an attempt before the retry is due.
+				result, err = reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+				Expect(result.RequeueAfter).To(BeNumerically("<=", cleanupRetryInterval))
+				Expect(k8sClient.Get(context.TODO(), key, &meshDeployment)).To(Succeed())
+				Expect(meshDeployment.Status.CleanupAttempts).To(Equal(attempts))
+
+				elapse()
+			}
+
+			// The attempts run out, the MeshDeployment is released.
+			result, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeZero())
+			err = k8sClient.Get(context.TODO(), key, &meshDeployment)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("image tag", func() {
+		It("should be the tag of the image or latest", func() {
+			Expect(imageTag("docker.io/megaease/easegress:server-sidecar")).To(Equal("server-sidecar"))
+			Expect(imageTag("localhost:5000/megaease/easegress")).To(Equal("latest"))
+			Expect(imageTag("easegress")).To(Equal("latest"))
+		})
+	})
 })
 
 func fromInt32(i int32) *int32 {
@@ -0,0 +1,261 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	meshv1beta1 "github.com/megaease/easemesh/mesh-operator/pkg/api/v1beta1"
+	"github.com/megaease/easemesh/mesh-operator/pkg/sidecar"
+
+	"github.com/juju/errors"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The reasons of the conditions of MeshDeployment
+const (
+	reasonInjected            = "Injected"
+	reasonSyncFailed          = "SyncFailed"
+	reasonDeploymentNotFound  = "DeploymentNotFound"
+	reasonAvailabilityUnknown = "AvailabilityUnknown"
+	reasonInstancesRegistered = "InstancesRegistered"
+	reasonNoInstance          = "NoInstanceRegistered"
+	reasonNoControlPlane      = "NoControlPlane"
+	reasonControlPlaneFailed  = "ControlPlaneFailed"
+	reasonReplicasUnavailable = "ReplicasUnavailable"
+	reasonAsExpected          = "AsExpected"
+	reasonRegistered          = "Registered"
+	reasonRegistrationFailed  = "RegistrationFailed"
+)
+
+const (
+	defaultImageTag           = "latest"
+	defaultDeploymentReplicas = int32(1)
+)
+
+// updateStatus updates the status of the MeshDeployment with the observed
+// state of its Deployment and pods, syncErr is the error of syncing the
+// Deployment, registerErr is the one of registering the mesh service. The
+// status is left as it is if nothing changes, so that the update doesn't
+// trigger another reconciliation.
+func (r *MeshDeploymentReconciler) updateStatus(ctx context.Context, meshDeploy *meshv1beta1.MeshDeployment, syncErr, registerErr error) error {
+	status := meshDeploy.Status.DeepCopy()
+	status.ObservedGeneration = meshDeploy.Generation
+	status.ServiceName = meshDeploy.Spec.Service.Name
+
+	deploy := &v1.Deployment{}
+	err := r.Client.Get(ctx, types.NamespacedName{Namespace: meshDeploy.Namespace, Name: meshDeploy.Name}, deploy)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		deploy = nil
+	}
+
+	setCondition := func(conditionType string, conditionStatus metav1.ConditionStatus, reason, messageFormat string, a ...interface{}) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               conditionType,
+			Status:             conditionStatus,
+			ObservedGeneration: meshDeploy.Generation,
+			Reason:             reason,
+			Message:            fmt.Sprintf(messageFormat, a...),
+		})
+	}
+
+	var sidecarContainer *corev1.Container
+	if deploy != nil {
+		for i, container := range deploy.Spec.Template.Spec.Containers {
+			if container.Name == sidecar.ContainerName {
+				sidecarContainer = &deploy.Spec.Template.Spec.Containers[i]
+			}
+		}
+	}
+
+	switch {
+	case sidecarContainer != nil:
+		status.SidecarImage = sidecarContainer.Image
+		status.SidecarVersion = imageTag(sidecarContainer.Image)
+		setCondition(meshv1beta1.ConditionSidecarInjected, metav1.ConditionTrue, reasonInjected,
+			"sidecar %s is injected", sidecarContainer.Image)
+	case syncErr != nil:
+		status.SidecarImage, status.SidecarVersion = "", ""
+		setCondition(meshv1beta1.ConditionSidecarInjected, metav1.ConditionFalse, reasonSyncFailed, "%v", syncErr)
+	default:
+		status.SidecarImage, status.SidecarVersion = "", ""
+		setCondition(meshv1beta1.ConditionSidecarInjected, metav1.ConditionFalse, reasonDeploymentNotFound,
+			"deployment %s is not found", meshDeploy.Name)
+	}
+
+	if deploy == nil {
+		status.Replicas, status.ReadyReplicas, status.UpdatedReplicas, status.AvailableReplicas = 0, 0, 0, 0
+		setCondition(meshv1beta1.ConditionDeploymentAvailable, metav1.ConditionFalse, reasonDeploymentNotFound,
+			"deployment %s is not found", meshDeploy.Name)
+	} else {
+		status.Replicas = deploy.Status.Replicas
+		status.ReadyReplicas = deploy.Status.ReadyReplicas
+		status.UpdatedReplicas = deploy.Status.UpdatedReplicas
+		status.AvailableReplicas = deploy.Status.AvailableReplicas
+
+		available := deploymentCondition(deploy, v1.DeploymentAvailable)
+		if available != nil && available.Reason != "" {
+			setCondition(meshv1beta1.ConditionDeploymentAvailable, metav1.ConditionStatus(available.Status),
+				available.Reason, "%s", available.Message)
+		} else {
+			setCondition(meshv1beta1.ConditionDeploymentAvailable, metav1.ConditionUnknown, reasonAvailabilityUnknown,
+				"deployment %s doesn't report its availability yet", deploy.Name)
+		}
+	}
+
+	pods, err := r.listPods(ctx, deploy)
+	if err != nil {
+		return err
+	}
+	registered, err := r.countRegistered(ctx, status.ServiceName, pods)
+	switch {
+	case err == errNoControlPlane:
+		setCondition(meshv1beta1.ConditionRegisteredInMesh, metav1.ConditionUnknown, reasonNoControlPlane, "%v", err)
+	case err != nil:
+		setCondition(meshv1beta1.ConditionRegisteredInMesh, metav1.ConditionUnknown, reasonControlPlaneFailed, "%v", err)
+	case registered > 0:
+		setCondition(meshv1beta1.ConditionRegisteredInMesh, metav1.ConditionTrue, reasonInstancesRegistered,
+			"%d of %d instances are registered as mesh service %s", registered, len(pods), status.ServiceName)
+	default:
+		setCondition(meshv1beta1.ConditionRegisteredInMesh, metav1.ConditionFalse, reasonNoInstance,
+			"none of %d instances is registered as mesh service %s", len(pods), status.ServiceName)
+	}
+
+	switch {
+	case meshDeploy.Spec.Service.RegisterTenant == "":
+		meta.RemoveStatusCondition(&status.Conditions, meshv1beta1.ConditionServiceRegistered)
+	case registerErr != nil:
+		setCondition(meshv1beta1.ConditionServiceRegistered, metav1.ConditionFalse, reasonRegistrationFailed, "%v", registerErr)
+	default:
+		setCondition(meshv1beta1.ConditionServiceRegistered, metav1.ConditionTrue, reasonRegistered,
+			"mesh service %s is registered in tenant %s", status.ServiceName, meshDeploy.Spec.Service.RegisterTenant)
+	}
+
+	switch {
+	case syncErr != nil:
+		setCondition(meshv1beta1.ConditionDegraded, metav1.ConditionTrue, reasonSyncFailed, "%v", syncErr)
+	case registerErr != nil:
+		setCondition(meshv1beta1.ConditionDegraded, metav1.ConditionTrue, reasonRegistrationFailed, "%v", registerErr)
+	case deploy == nil:
+		setCondition(meshv1beta1.ConditionDegraded, metav1.ConditionTrue, reasonDeploymentNotFound,
+			"deployment %s is not found", meshDeploy.Name)
+	case deploy.Status.AvailableReplicas < desiredReplicas(deploy):
+		setCondition(meshv1beta1.ConditionDegraded, metav1.ConditionTrue, reasonReplicasUnavailable,
+			"%d of %d replicas are available", deploy.Status.AvailableReplicas, desiredReplicas(deploy))
+	default:
+		setCondition(meshv1beta1.ConditionDegraded, metav1.ConditionFalse, reasonAsExpected,
+			"all %d replicas are available", desiredReplicas(deploy))
+	}
+
+	if equality.Semantic.DeepEqual(status, &meshDeploy.Status) {
+		return nil
+	}
+	meshDeploy.Status = *status
+	return r.Client.Status().Update(ctx, meshDeploy)
+}
+
+// countRegistered counts the pods registered as instances of the mesh
+// service in the control plane, the sidecar registers the instance named
+// after its pod once it's ready. The control plane isn't requested if there
+// is no pod.
+func (r *MeshDeploymentReconciler) countRegistered(ctx context.Context, serviceName string, pods []corev1.Pod) (int, error) {
+	if len(pods) == 0 {
+		return 0, nil
+	}
+	if r.MeshClient == nil {
+		return 0, errNoControlPlane
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, controlPlaneTimeout)
+	defer cancel()
+
+	instances, err := r.MeshClient.ListServiceInstances(ctx)
+	if err != nil {
+		return 0, errors.Annotate(err, "list mesh service instances")
+	}
+
+	podSet := map[string]bool{}
+	for _, pod := range pods {
+		podSet[pod.Name] = true
+	}
+
+	registered := 0
+	for _, instance := range instances {
+		if instance.ServiceName == serviceName && podSet[instance.InstanceID] {
+			registered++
+		}
+	}
+	return registered, nil
+}
+
+// listPods lists the pods selected by the Deployment.
+func (r *MeshDeploymentReconciler) listPods(ctx context.Context, deploy *v1.Deployment) ([]corev1.Pod, error) {
+	if deploy == nil || deploy.Spec.Selector == nil {
+		return nil, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(deploy.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	pods := &corev1.PodList{}
+	err = r.Client.List(ctx, pods, client.InNamespace(deploy.Namespace), client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
+
+func deploymentCondition(deploy *v1.Deployment, conditionType v1.DeploymentConditionType) *v1.DeploymentCondition {
+	for i, condition := range deploy.Status.Conditions {
+		if condition.Type == conditionType {
+			return &deploy.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func desiredReplicas(deploy *v1.Deployment) int32 {
+	if deploy.Spec.Replicas == nil {
+		return defaultDeploymentReplicas
+	}
+	return *deploy.Spec.Replicas
+}
+
+// imageTag returns the tag of the image, the tag of an image without a tag
+// is latest.
+func imageTag(image string) string {
+	name := image[strings.LastIndex(image, "/")+1:]
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return defaultImageTag
+}
@@ -0,0 +1,207 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	meshv1beta1 "github.com/megaease/easemesh/mesh-operator/pkg/api/v1beta1"
+	"github.com/megaease/easemesh/mesh-operator/pkg/meshclient"
+	"github.com/megaease/easemesh/mesh-operator/pkg/sidecar"
+
+	"github.com/juju/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	controlPlaneTimeout = 10 * time.Second
+
+	defaultDiscoveryType = "eureka"
+	defaultSidecarHost   = "127.0.0.1"
+	defaultProtocol      = "http"
+)
+
+var errNoControlPlane = errors.New("the control plane of the mesh is not configured for the operator")
+
+// managedService is the part of the mesh service the operator manages, the
+// other parts, such as the canary and the observability, are left to emctl.
+type managedService struct {
+	Name           string                       `json:"name"`
+	RegisterTenant string                       `json:"registerTenant"`
+	Sidecar        *meshv1beta1.SidecarSpec     `json:"sidecar"`
+	LoadBalance    *meshv1beta1.LoadBalanceSpec `json:"loadBalance,omitempty"`
+	Resilience     *runtime.RawExtension        `json:"resilience,omitempty"`
+}
+
+// serviceOf returns the fields of the mesh service the MeshDeployment
+// specifies, the sidecar defaults to the injected one.
+func serviceOf(meshDeploy *meshv1beta1.MeshDeployment) (meshclient.Service, error) {
+	spec := meshDeploy.Spec.Service
+	managed := &managedService{
+		Name:           spec.Name,
+		RegisterTenant: spec.RegisterTenant,
+		Sidecar:        spec.Sidecar,
+		LoadBalance:    spec.LoadBalance,
+		Resilience:     spec.Resilience,
+	}
+	if managed.Sidecar == nil {
+		managed.Sidecar = &meshv1beta1.SidecarSpec{
+			DiscoveryType:   defaultDiscoveryType,
+			Address:         defaultSidecarHost,
+			IngressPort:     sidecar.IngressPort,
+			IngressProtocol: defaultProtocol,
+			EgressPort:      sidecar.EgressPort,
+			EgressProtocol:  defaultProtocol,
+		}
+	}
+
+	// The round trip makes the fields comparable with the ones of the
+	// service got from the control plane.
+	b, err := json.Marshal(managed)
+	if err != nil {
+		return nil, errors.Annotatef(err, "marshal mesh service %s", spec.Name)
+	}
+	service := meshclient.Service{}
+	err = json.Unmarshal(b, &service)
+	if err != nil {
+		return nil, errors.Annotatef(err, "unmarshal mesh service %s", spec.Name)
+	}
+	return service, nil
+}
+
+// registerService creates the mesh service of the MeshDeployment in the
+// control plane, or updates the fields the operator manages if the service
+// exists.
+func (r *MeshDeploymentReconciler) registerService(ctx context.Context, meshDeploy *meshv1beta1.MeshDeployment) error {
+	if r.MeshClient == nil {
+		return errNoControlPlane
+	}
+
+	desired, err := serviceOf(meshDeploy)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, controlPlaneTimeout)
+	defer cancel()
+
+	name := meshDeploy.Spec.Service.Name
+	current, err := r.MeshClient.GetService(ctx, name)
+	if meshclient.IsNotFoundError(err) {
+		err = r.MeshClient.CreateService(ctx, name, desired)
+		if err != nil {
+			return errors.Annotatef(err, "create mesh service %s", name)
+		}
+		r.Recorder.Eventf(meshDeploy, corev1.EventTypeNormal, "ServiceRegistered",
+			"mesh service %s is registered in tenant %s", name, meshDeploy.Spec.Service.RegisterTenant)
+		return nil
+	}
+	if err != nil {
+		return errors.Annotatef(err, "get mesh service %s", name)
+	}
+
+	updated := meshclient.Service{}
+	for k, v := range current {
+		updated[k] = v
+	}
+	for k, v := range desired {
+		updated[k] = v
+	}
+	if reflect.DeepEqual(updated, current) {
+		return nil
+	}
+
+	err = r.MeshClient.UpdateService(ctx, name, updated)
+	if err != nil {
+		return errors.Annotatef(err, "update mesh service %s", name)
+	}
+	r.Recorder.Eventf(meshDeploy, corev1.EventTypeNormal, "ServiceUpdated", "mesh service %s is updated", name)
+	return nil
+}
+
+// deregisterInstances deletes the instances registered by the sidecars of
+// the pods from the control plane.
+func (r *MeshDeploymentReconciler) deregisterInstances(ctx context.Context, meshDeploy *meshv1beta1.MeshDeployment, pods []string) error {
+	ctx, cancel := context.WithTimeout(ctx, controlPlaneTimeout)
+	defer cancel()
+
+	name := meshDeploy.Spec.Service.Name
+	instances, err := r.MeshClient.ListServiceInstances(ctx)
+	if err != nil {
+		return errors.Annotate(err, "list mesh service instances")
+	}
+
+	podSet := map[string]bool{}
+	for _, pod := range pods {
+		podSet[pod] = true
+	}
+
+	deregistered := 0
+	for _, instance := range instances {
+		if instance.ServiceName != name || !podSet[instance.InstanceID] {
+			continue
+		}
+		err := r.MeshClient.DeleteServiceInstance(ctx, name, instance.InstanceID)
+		if err != nil && !meshclient.IsNotFoundError(err) {
+			return errors.Annotatef(err, "delete mesh service instance %s/%s", name, instance.InstanceID)
+		}
+		deregistered++
+	}
+
+	if deregistered > 0 {
+		r.Recorder.Eventf(meshDeploy, corev1.EventTypeNormal, "InstancesDeregistered",
+			"%d instances of mesh service %s are deregistered", deregistered, name)
+	}
+	return nil
+}
+
+// deregisterService deletes the mesh service of the MeshDeployment from the
+// control plane.
+func (r *MeshDeploymentReconciler) deregisterService(ctx context.Context, meshDeploy *meshv1beta1.MeshDeployment) error {
+	ctx, cancel := context.WithTimeout(ctx, controlPlaneTimeout)
+	defer cancel()
+
+	name := meshDeploy.Spec.Service.Name
+	err := r.MeshClient.DeleteService(ctx, name)
+	if err != nil && !meshclient.IsNotFoundError(err) {
+		return errors.Annotatef(err, "delete mesh service %s", name)
+	}
+	r.Recorder.Eventf(meshDeploy, corev1.EventTypeNormal, "ServiceDeregistered", "mesh service %s is deregistered", name)
+	return nil
+}
+
+// serviceSharedWith returns another MeshDeployment, e.g. the canary one,
+// which still registers the same mesh service, it's nil if there is none.
+func (r *MeshDeploymentReconciler) serviceSharedWith(ctx context.Context, meshDeploy *meshv1beta1.MeshDeployment) (*meshv1beta1.MeshDeployment, error) {
+	meshDeploys := &meshv1beta1.MeshDeploymentList{}
+	err := r.Client.List(ctx, meshDeploys)
+	if err != nil {
+		return nil, errors.Annotate(err, "list MeshDeployments")
+	}
+	for i, other := range meshDeploys.Items {
+		if other.UID != meshDeploy.UID && other.DeletionTimestamp.IsZero() &&
+			other.Spec.Service.Name == meshDeploy.Spec.Service.Name && other.Spec.Service.RegisterTenant != "" {
+			return &meshDeploys.Items[i], nil
+		}
+	}
+	return nil, nil
+}
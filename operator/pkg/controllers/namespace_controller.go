@@ -0,0 +1,145 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/megaease/easemesh/mesh-operator/pkg/sidecar"
+
+	"github.com/go-logr/logr"
+	"github.com/juju/errors"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// NamespaceReconciler keeps the Deployments of a namespace in line with the
+// Policy: it injects the ones which are in the mesh but not injected, such
+// as the ones created before the namespace is enrolled, and strips the ones
+// which are injected but not in the mesh any more, such as the ones of a
+// namespace whose enrollment is removed. Updating the pod template of a
+// Deployment rolls out its pods.
+type NamespaceReconciler struct {
+	client.Client
+	Log      logr.Logger
+	Recorder record.EventRecorder
+	Injector *sidecar.Injector
+	Policy   *sidecar.Policy
+}
+
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// Reconcile syncs the Deployments of the namespace.
+func (r *NamespaceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := r.Log.WithValues("namespace", req.Name)
+
+	namespace := &corev1.Namespace{}
+	err := r.Client.Get(ctx, req.NamespacedName, namespace)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	deploys := &v1.DeploymentList{}
+	err = r.Client.List(ctx, deploys, client.InNamespace(namespace.Name))
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	failures := []string{}
+	for i := range deploys.Items {
+		deploy := &deploys.Items[i]
+		err := r.syncDeployment(ctx, deploy, namespace)
+		if err != nil {
+			log.Error(err, "sync deployment failed", "deployment", deploy.Name)
+			failures = append(failures, fmt.Sprintf("%s: %v", deploy.Name, err))
+		}
+	}
+
+	if len(failures) != 0 {
+		return reconcile.Result{}, errors.Errorf("sync deployments failed: %s", strings.Join(failures, "; "))
+	}
+	return reconcile.Result{}, nil
+}
+
+func (r *NamespaceReconciler) syncDeployment(ctx context.Context, deploy *v1.Deployment, namespace *corev1.Namespace) error {
+	service, err := r.Policy.ServiceOf(deploy, namespace)
+	if err != nil {
+		// It's not an error of the operator, retrying doesn't help.
+		r.Recorder.Event(deploy, corev1.EventTypeWarning, "SidecarInjectionSkipped", err.Error())
+		return nil
+	}
+
+	injected := sidecar.Injected(&deploy.Spec.Template.ObjectMeta)
+	if service == nil && !injected {
+		return nil
+	}
+
+	template := deploy.Spec.Template.DeepCopy()
+	err = r.Injector.Sync(&template.ObjectMeta, &template.Spec, service)
+	if err != nil {
+		r.Recorder.Eventf(deploy, corev1.EventTypeWarning, "SidecarInjectionFailed", "inject sidecar failed: %v", err)
+		return nil
+	}
+	if equality.Semantic.DeepEqual(template, &deploy.Spec.Template) {
+		return nil
+	}
+
+	deploy.Spec.Template = *template
+	err = r.Client.Update(ctx, deploy)
+	if err != nil {
+		return err
+	}
+
+	if service == nil {
+		r.Recorder.Event(deploy, corev1.EventTypeNormal, "SidecarStripped", "the sidecar is stripped as it's not in the mesh")
+	} else {
+		r.Recorder.Eventf(deploy, corev1.EventTypeNormal, "SidecarInjected", "the sidecar of mesh service %s is injected", service.Name)
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager, the namespace of
+// a changed Deployment is reconciled too, as the Deployment may opt out, the
+// changes of the status of Deployments are ignored.
+func (r *NamespaceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Namespace{}).
+		Watches(&source.Kind{Type: &v1.Deployment{}},
+			handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: obj.GetNamespace()}}}
+			}),
+			builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		Complete(r)
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/megaease/easemesh/mesh-operator/pkg/sidecar"
+
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("namespace controller", func() {
+	// Namespaces are never deleted in the test environment, which doesn't
+	// run the namespace controller of Kubernetes, so every spec has its own.
+	var reconciler *NamespaceReconciler
+	var namespace corev1.Namespace
+	var deploy v1.Deployment
+
+	reconcileNamespace := func() {
+		_, err := reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: namespace.Name}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: namespace.Name, Name: deploy.Name}, &deploy)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		reconciler = &NamespaceReconciler{
+			Client:   k8sClient,
+			Log:      ctrl.Log.WithName("controllers").WithName("Namespace"),
+			Recorder: &mockRecorder{},
+			Injector: &sidecar.Injector{},
+			Policy:   &sidecar.Policy{ServiceNameKey: sidecar.DefaultServiceNameKey},
+		}
+
+		namespace = corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "enrolled-"}}
+		Expect(k8sClient.Create(context.TODO(), &namespace)).To(Succeed())
+
+		deploy = v1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "vets",
+				Namespace: namespace.Name,
+				Labels:    map[string]string{"app": "vets-service"},
+			},
+			Spec: v1.DeploymentSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "vets-service"}},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "vets-service"}},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "vets", Image: "megaease/vets:1.0"}},
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(context.TODO(), &deploy)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		k8sClient.Delete(context.TODO(), &deploy)
+	})
+
+	Context("deployment created before the namespace is enrolled", func() {
+		It("should be injected after enrolled and stripped after the enrollment is removed", func() {
+			reconcileNamespace()
+			Expect(deploy.Spec.Template.Spec.Containers).To(HaveLen(1))
+
+			namespace.Labels = map[string]string{sidecar.InjectKey: sidecar.InjectEnabled}
+			Expect(k8sClient.Update(context.TODO(), &namespace)).To(Succeed())
+			reconcileNamespace()
+			Expect(deploy.Spec.Template.Spec.Containers).To(HaveLen(2))
+			Expect(deploy.Spec.Template.Spec.Containers[1].Name).To(Equal(sidecar.ContainerName))
+			Expect(sidecar.Injected(&deploy.Spec.Template.ObjectMeta)).To(BeTrue())
+
+			delete(namespace.Labels, sidecar.InjectKey)
+			Expect(k8sClient.Update(context.TODO(), &namespace)).To(Succeed())
+			reconcileNamespace()
+			Expect(deploy.Spec.Template.Spec.Containers).To(HaveLen(1))
+			Expect(sidecar.Injected(&deploy.Spec.Template.ObjectMeta)).To(BeFalse())
+		})
+	})
+
+	Context("deployment opted out of an enrolled namespace", func() {
+		It("should not be injected", func() {
+			deploy.Annotations = map[string]string{sidecar.InjectKey: sidecar.InjectDisabled}
+			Expect(k8sClient.Update(context.TODO(), &deploy)).To(Succeed())
+			namespace.Labels = map[string]string{sidecar.InjectKey: sidecar.InjectEnabled}
+			Expect(k8sClient.Update(context.TODO(), &namespace)).To(Succeed())
+
+			reconcileNamespace()
+			Expect(deploy.Spec.Template.Spec.Containers).To(HaveLen(1))
+		})
+	})
+})
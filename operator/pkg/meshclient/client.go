@@ -0,0 +1,244 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MeshServiceURL is the path of the mesh service in the control plane
+	MeshServiceURL = "/apis/v1/mesh/services/%s"
+
+	// MeshServiceInstancesURL is the path of the mesh service instances in the control plane
+	MeshServiceInstancesURL = "/apis/v1/mesh/serviceinstances"
+
+	// MeshServiceInstanceURL is the path of the mesh service instance in the control plane
+	MeshServiceInstanceURL = "/apis/v1/mesh/serviceinstances/%s/%s"
+)
+
+var (
+	// ConflictError indicate that the resource already exists
+	ConflictError = errors.Errorf("resource already exists")
+	// NotFoundError indicate that the resource does not existed
+	NotFoundError = errors.Errorf("resource not found")
+)
+
+// IsConflictError judge err is a ConflictError
+func IsConflictError(err error) bool {
+	return errors.Cause(err) == ConflictError
+}
+
+// IsNotFoundError judge err is a NotFoundError
+func IsNotFoundError(err error) bool {
+	return errors.Cause(err) == NotFoundError
+}
+
+// StatusError indicates that the control plane responds an unexpected status code
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("call %s %s failed, return status code %d text %s", e.Method, e.URL, e.StatusCode, e.Body)
+}
+
+// Service is the mesh service in the control plane. It's kept as the raw
+// JSON object, so that the fields the operator doesn't manage, such as the
+// canary and the observability, survive the updates.
+type Service map[string]interface{}
+
+// ServiceInstance is the instance of the mesh service registered by the
+// sidecar, its instance ID is the name of the pod.
+type ServiceInstance struct {
+	ServiceName string            `json:"serviceName"`
+	InstanceID  string            `json:"instanceID"`
+	IP          string            `json:"ip,omitempty"`
+	Port        int32             `json:"port,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Status      string            `json:"status,omitempty"`
+}
+
+// Client manipulates the mesh services and their instances in the control
+// plane through the same REST API as emctl.
+type Client interface {
+	GetService(ctx context.Context, name string) (Service, error)
+	CreateService(ctx context.Context, name string, service Service) error
+	UpdateService(ctx context.Context, name string, service Service) error
+	DeleteService(ctx context.Context, name string) error
+
+	ListServiceInstances(ctx context.Context) ([]ServiceInstance, error)
+	DeleteServiceInstance(ctx context.Context, serviceName, instanceID string) error
+}
+
+type client struct {
+	endpoints  []string
+	httpClient *http.Client
+}
+
+var _ Client = &client{}
+
+// New creates the client of the control plane, the server is a comma
+// separated list of its endpoints, a request is sent to the next endpoint
+// if the previous one is unreachable.
+func New(server string, httpClient *http.Client) Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &client{endpoints: parseEndpoints(server, "http://"), httpClient: httpClient}
+}
+
+// parseEndpoints splits the server into endpoints, the scheme is prepended
+// to the endpoints without one.
+func parseEndpoints(server, scheme string) []string {
+	endpoints := []string{}
+	for _, s := range strings.Split(server, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if !strings.HasPrefix(s, "http://") && !strings.HasPrefix(s, "https://") {
+			s = scheme + s
+		}
+		endpoints = append(endpoints, strings.TrimSuffix(s, "/"))
+	}
+	return endpoints
+}
+
+func (c *client) GetService(ctx context.Context, name string) (Service, error) {
+	b, err := c.do(ctx, http.MethodGet, fmt.Sprintf(MeshServiceURL, name), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	service := Service{}
+	err = json.Unmarshal(b, &service)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unmarshal service %s", name)
+	}
+	return service, nil
+}
+
+// CreateService creates the service, the control plane expects the POST
+// on the URL of the service instead of the one of the services.
+func (c *client) CreateService(ctx context.Context, name string, service Service) error {
+	_, err := c.do(ctx, http.MethodPost, fmt.Sprintf(MeshServiceURL, name), service)
+	return err
+}
+
+// UpdateService replaces the whole service with the given one.
+func (c *client) UpdateService(ctx context.Context, name string, service Service) error {
+	_, err := c.do(ctx, http.MethodPut, fmt.Sprintf(MeshServiceURL, name), service)
+	return err
+}
+
+func (c *client) DeleteService(ctx context.Context, name string) error {
+	_, err := c.do(ctx, http.MethodDelete, fmt.Sprintf(MeshServiceURL, name), nil)
+	return err
+}
+
+func (c *client) ListServiceInstances(ctx context.Context) ([]ServiceInstance, error) {
+	b, err := c.do(ctx, http.MethodGet, MeshServiceInstancesURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	instances := []ServiceInstance{}
+	err = json.Unmarshal(b, &instances)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal service instances")
+	}
+	return instances, nil
+}
+
+func (c *client) DeleteServiceInstance(ctx context.Context, serviceName, instanceID string) error {
+	_, err := c.do(ctx, http.MethodDelete, fmt.Sprintf(MeshServiceInstanceURL, serviceName, instanceID), nil)
+	return err
+}
+
+// do sends the request to the endpoints in order until one of them
+// responds, and maps the status code of the response to the error.
+func (c *client) do(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return nil, errors.Wrapf(err, "marshal request of %s %s", method, path)
+		}
+	}
+
+	if len(c.endpoints) == 0 {
+		return nil, errors.Errorf("no endpoint of the control plane")
+	}
+
+	var lastErr error
+	for _, endpoint := range c.endpoints {
+		url := endpoint + path
+		var reader io.Reader
+		if payload != nil {
+			reader = bytes.NewReader(payload)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, url, reader)
+		if err != nil {
+			return nil, errors.Wrapf(err, "new request %s %s", method, url)
+		}
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			lastErr = errors.Wrapf(err, "call %s %s", method, url)
+			if ctx.Err() != nil {
+				return nil, lastErr
+			}
+			continue
+		}
+
+		b, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "read response of %s %s", method, url)
+		}
+
+		switch {
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			return b, nil
+		case resp.StatusCode == http.StatusNotFound:
+			return nil, errors.Wrapf(NotFoundError, "%s %s", method, path)
+		case resp.StatusCode == http.StatusConflict:
+			return nil, errors.Wrapf(ConflictError, "%s %s", method, path)
+		default:
+			return nil, &StatusError{Method: method, URL: url, StatusCode: resp.StatusCode, Body: string(b)}
+		}
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/megaease/easemesh/mesh-operator/pkg/meshclient"
+	"github.com/megaease/easemesh/mesh-operator/pkg/meshclient/fake"
+)
+
+func TestClient(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+
+	// The first endpoint is unreachable, the requests fail over to the fake.
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+	client := meshclient.New(unreachable.URL+","+server.URL(), nil)
+	ctx := context.Background()
+
+	if _, err := client.GetService(ctx, "vets"); !meshclient.IsNotFoundError(err) {
+		t.Fatalf("expect not found error but got %v", err)
+	}
+
+	service := meshclient.Service{"name": "vets", "registerTenant": "pet"}
+	if err := client.CreateService(ctx, "vets", service); err != nil {
+		t.Fatalf("create service failed: %v", err)
+	}
+	if err := client.CreateService(ctx, "vets", service); !meshclient.IsConflictError(err) {
+		t.Fatalf("expect conflict error but got %v", err)
+	}
+
+	service["canary"] = map[string]interface{}{"canaryRules": []interface{}{}}
+	if err := client.UpdateService(ctx, "vets", service); err != nil {
+		t.Fatalf("update service failed: %v", err)
+	}
+	got, err := client.GetService(ctx, "vets")
+	if err != nil {
+		t.Fatalf("get service failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, service) {
+		t.Errorf("expect service %v but got %v", service, got)
+	}
+
+	if err := client.DeleteService(ctx, "vets"); err != nil {
+		t.Fatalf("delete service failed: %v", err)
+	}
+	if err := client.DeleteService(ctx, "vets"); !meshclient.IsNotFoundError(err) {
+		t.Fatalf("expect not found error but got %v", err)
+	}
+
+	expected := []fake.Request{
+		{Method: http.MethodGet, Path: "/apis/v1/mesh/services/vets"},
+		{Method: http.MethodPost, Path: "/apis/v1/mesh/services/vets"},
+		{Method: http.MethodPost, Path: "/apis/v1/mesh/services/vets"},
+		{Method: http.MethodPut, Path: "/apis/v1/mesh/services/vets"},
+		{Method: http.MethodGet, Path: "/apis/v1/mesh/services/vets"},
+		{Method: http.MethodDelete, Path: "/apis/v1/mesh/services/vets"},
+		{Method: http.MethodDelete, Path: "/apis/v1/mesh/services/vets"},
+	}
+	if requests := server.Requests(); !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expect requests %v but got %v", expected, requests)
+	}
+}
+
+func TestClientServiceInstances(t *testing.T) {
+	server := fake.NewServer()
+	defer server.Close()
+	client := meshclient.New(server.URL(), nil)
+	ctx := context.Background()
+
+	instances, err := client.ListServiceInstances(ctx)
+	if err != nil || len(instances) != 0 {
+		t.Fatalf("expect no instances but got %v, %v", instances, err)
+	}
+
+	vets := meshclient.ServiceInstance{ServiceName: "vets", InstanceID: "vets-v1-5d9c7b-x2z9q", Labels: map[string]string{"version": "v1"}}
+	owners := meshclient.ServiceInstance{ServiceName: "owners", InstanceID: "owners-7f6d8c-k8s2p"}
+	server.AddServiceInstance(vets)
+	server.AddServiceInstance(owners)
+
+	instances, err = client.ListServiceInstances(ctx)
+	if err != nil {
+		t.Fatalf("list service instances failed: %v", err)
+	}
+	if expected := []meshclient.ServiceInstance{vets, owners}; !reflect.DeepEqual(instances, expected) {
+		t.Errorf("expect instances %v but got %v", expected, instances)
+	}
+
+	if err := client.DeleteServiceInstance(ctx, "vets", vets.InstanceID); err != nil {
+		t.Fatalf("delete service instance failed: %v", err)
+	}
+	if err := client.DeleteServiceInstance(ctx, "vets", vets.InstanceID); !meshclient.IsNotFoundError(err) {
+		t.Fatalf("expect not found error but got %v", err)
+	}
+	if instances := server.ServiceInstances(); !reflect.DeepEqual(instances, []meshclient.ServiceInstance{owners}) {
+		t.Errorf("expect only instance of owners left but got %v", instances)
+	}
+}
+
+func TestClientStatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "tenant pet not found", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	err := meshclient.New(server.URL, nil).CreateService(context.Background(), "vets", meshclient.Service{"name": "vets"})
+	statusErr, ok := err.(*meshclient.StatusError)
+	if !ok {
+		t.Fatalf("expect status error but got %v", err)
+	}
+	if statusErr.StatusCode != http.StatusBadRequest || statusErr.Method != http.MethodPost {
+		t.Errorf("unexpected status error %v", statusErr)
+	}
+
+	if _, err := meshclient.New("", nil).GetService(context.Background(), "vets"); err == nil {
+		t.Errorf("expect error without endpoints")
+	}
+}
@@ -0,0 +1,193 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package fake provides an in-memory fake of the mesh services and their
+// instances of the EaseMesh control plane for testing the operator without a live Easegress.
+package fake
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"github.com/megaease/easemesh/mesh-operator/pkg/meshclient"
+)
+
+const (
+	servicePrefix  = "/apis/v1/mesh/services/"
+	instancePrefix = "/apis/v1/mesh/serviceinstances"
+)
+
+type (
+	// Server is an in-memory fake of the REST apis of the mesh services and
+	// their instances.
+	Server struct {
+		server *httptest.Server
+
+		mutex     sync.Mutex
+		services  map[string]meshclient.Service
+		instances []meshclient.ServiceInstance
+		requests  []Request
+	}
+
+	// Request is a request the server received.
+	Request struct {
+		Method string
+		Path   string
+	}
+)
+
+// NewServer starts a fake control plane without any service.
+func NewServer() *Server {
+	s := &Server{services: map[string]meshclient.Service{}}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// URL returns the endpoint of the server.
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// AddService stores the service as if it had been created.
+func (s *Server) AddService(name string, service meshclient.Service) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.services[name] = service
+}
+
+// Service returns the stored service, it's nil if the service doesn't exist.
+func (s *Server) Service(name string) meshclient.Service {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.services[name]
+}
+
+// AddServiceInstance stores the instance as if the sidecar had registered it.
+func (s *Server) AddServiceInstance(instance meshclient.ServiceInstance) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.instances = append(s.instances, instance)
+}
+
+// ServiceInstances returns the stored instances.
+func (s *Server) ServiceInstances() []meshclient.ServiceInstance {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]meshclient.ServiceInstance(nil), s.instances...)
+}
+
+// Requests returns the requests the server received in order.
+func (s *Server) Requests() []Request {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path})
+
+	if strings.HasPrefix(r.URL.Path, instancePrefix) {
+		s.handleInstances(w, r)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, servicePrefix)
+	if name == r.URL.Path || name == "" || strings.Contains(name, "/") {
+		http.Error(w, "unsupported path", http.StatusNotFound)
+		return
+	}
+	service, exists := s.services[name]
+
+	switch r.Method {
+	case http.MethodGet:
+		if !exists {
+			http.Error(w, "service not found", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(service)
+	case http.MethodPost, http.MethodPut:
+		if r.Method == http.MethodPost && exists {
+			http.Error(w, "service already exists", http.StatusConflict)
+			return
+		}
+		if r.Method == http.MethodPut && !exists {
+			http.Error(w, "service not found", http.StatusNotFound)
+			return
+		}
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		service := meshclient.Service{}
+		if err := json.Unmarshal(b, &service); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.services[name] = service
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+		}
+	case http.MethodDelete:
+		if !exists {
+			http.Error(w, "service not found", http.StatusNotFound)
+			return
+		}
+		delete(s.services, name)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleInstances(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == instancePrefix {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		instances := s.instances
+		if instances == nil {
+			instances = []meshclient.ServiceInstance{}
+		}
+		json.NewEncoder(w).Encode(instances)
+		return
+	}
+
+	segments := strings.Split(strings.TrimPrefix(r.URL.Path, instancePrefix+"/"), "/")
+	if len(segments) != 2 || r.Method != http.MethodDelete {
+		http.Error(w, "unsupported request", http.StatusNotFound)
+		return
+	}
+	for i, instance := range s.instances {
+		if instance.ServiceName == segments[0] && instance.InstanceID == segments[1] {
+			s.instances = append(s.instances[:i], s.instances[i+1:]...)
+			return
+		}
+	}
+	http.Error(w, "service instance not found", http.StatusNotFound)
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// Options holds the TLS material and the credentials to access the control
+// plane, they're the same as the client options of emctl.
+type Options struct {
+	CertificateAuthority  string
+	ClientCertificate     string
+	ClientKey             string
+	InsecureSkipTLSVerify bool
+
+	Token    string
+	Username string
+	Password string
+}
+
+// tlsEnabled returns whether any TLS option is specified, the endpoints
+// without scheme are accessed by https then.
+func (o *Options) tlsEnabled() bool {
+	return o.CertificateAuthority != "" || o.ClientCertificate != "" ||
+		o.ClientKey != "" || o.InsecureSkipTLSVerify
+}
+
+// NewWithOptions creates the client of the control plane accessing it with
+// the options, see New for the server.
+func NewWithOptions(server string, opts *Options) (Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	scheme := "http://"
+	if opts.tlsEnabled() {
+		tlsConfig, err := newTLSConfig(opts)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+		scheme = "https://"
+	}
+
+	httpClient := &http.Client{Transport: &authTransport{opts: opts, next: transport}}
+	return &client{endpoints: parseEndpoints(server, scheme), httpClient: httpClient}, nil
+}
+
+func newTLSConfig(opts *Options) (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: opts.InsecureSkipTLSVerify}
+
+	if opts.CertificateAuthority != "" {
+		caPEM, err := ioutil.ReadFile(opts.CertificateAuthority)
+		if err != nil {
+			return nil, errors.Wrapf(err, "read certificate authority %s", opts.CertificateAuthority)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, errors.Errorf("no valid PEM certificate found in %s", opts.CertificateAuthority)
+		}
+	}
+
+	if opts.ClientCertificate != "" || opts.ClientKey != "" {
+		if opts.ClientCertificate == "" || opts.ClientKey == "" {
+			return nil, errors.Errorf("client certificate and client key must be specified together")
+		}
+		cert, err := tls.LoadX509KeyPair(opts.ClientCertificate, opts.ClientKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, "load client certificate %s and key %s", opts.ClientCertificate, opts.ClientKey)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// authTransport authenticates the requests with the bearer token, or the
+// username and password.
+type authTransport struct {
+	opts *Options
+	next http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch {
+	case t.opts.Token != "":
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+t.opts.Token)
+	case t.opts.Username != "" || t.opts.Password != "":
+		req = req.Clone(req.Context())
+		req.SetBasicAuth(t.opts.Username, t.opts.Password)
+	}
+	return t.next.RoundTrip(req)
+}
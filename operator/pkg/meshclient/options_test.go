@@ -0,0 +1,106 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meshclient_test
+
+import (
+	"context"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/megaease/easemesh/mesh-operator/pkg/meshclient"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	var authorization string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Write([]byte(`{"name":"vets"}`))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "meshclient")
+	if err != nil {
+		t.Fatalf("create temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	caFile := path.Join(dir, "ca.crt")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := ioutil.WriteFile(caFile, caPEM, 0600); err != nil {
+		t.Fatalf("write certificate authority failed: %v", err)
+	}
+
+	// The endpoint without scheme is accessed by https with TLS options.
+	endpoint := strings.TrimPrefix(server.URL, "https://")
+
+	tests := []struct {
+		name          string
+		opts          *meshclient.Options
+		authorization string
+		fails         bool
+	}{
+		{"token", &meshclient.Options{CertificateAuthority: caFile, Token: "secret"}, "Bearer secret", false},
+		{"basic-auth", &meshclient.Options{InsecureSkipTLSVerify: true, Username: "admin", Password: "admin"},
+			"Basic YWRtaW46YWRtaW4=", false},
+		{"key-without-certificate", &meshclient.Options{ClientKey: caFile}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorization = ""
+			client, err := meshclient.NewWithOptions(endpoint, tt.opts)
+			if tt.fails {
+				if err == nil {
+					_, err = client.GetService(context.Background(), "vets")
+				}
+				if err == nil {
+					t.Errorf("expect error with options %+v", tt.opts)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("new client failed: %v", err)
+			}
+
+			service, err := client.GetService(context.Background(), "vets")
+			if err != nil {
+				t.Fatalf("get service failed: %v", err)
+			}
+			if service["name"] != "vets" {
+				t.Errorf("expect service vets but got %v", service)
+			}
+			if authorization != tt.authorization {
+				t.Errorf("expect authorization %q but got %q", tt.authorization, authorization)
+			}
+		})
+	}
+
+	// The server isn't trusted without its certificate authority.
+	client, err := meshclient.NewWithOptions(server.URL, &meshclient.Options{Token: "secret"})
+	if err != nil {
+		t.Fatalf("new client failed: %v", err)
+	}
+	if _, err := client.GetService(context.Background(), "vets"); err == nil {
+		t.Errorf("expect error for the unknown certificate authority")
+	}
+}
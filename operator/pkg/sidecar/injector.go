@@ -0,0 +1,556 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sidecar
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	agentVolumeName      = "easeagent-volume"
+	agentVolumeMountPath = "/easeagent-volume"
+
+	sidecarParamsVolumeName      = "sidecar-params-volume"
+	sidecarParamsVolumeMountPath = "/sidecar-params-volume"
+	sidecarInitContainerName     = "easegress-sidecar-initializer"
+
+	agentInitContainerName      = "easeagent-initializer"
+	agentInitContainerImage     = "megaease/easeagent-initializer:latest"
+	agentInitContainerMountPath = "/easeagent-share-volume"
+
+	easeAgentJar       = " -javaagent:" + agentVolumeMountPath + "/easeagent.jar -Deaseagent.log.conf=" + agentVolumeMountPath + "/log4j2.xml "
+	javaAgentJarOption = easeAgentJar
+
+	javaToolOptionsEnvName = "JAVA_TOOL_OPTIONS"
+	podIPEnvName           = "APPLICATION_IP"
+	podNameEnvName         = "POD_NAME"
+
+	k8sPodIPFieldPath   = "status.podIP"
+	k8sPodNameFieldPath = "metadata.name"
+
+	sidecarImageName                = "megaease/easegress:server-sidecar"
+	sidecarMountPath                = "/easegress-sidecar"
+	sidecarIngressPortName          = "sidecar-ingress"
+	sidecarIngressPortContainerPort = 13001
+
+	sidecarEgressPortName         = "sidecar-egress"
+	sidecarEressPortContainerPort = 13002
+
+	sidecarEurekaPortName          = "sidecar-eureka"
+	sidecarEurekaPortContainerPort = 13009
+
+	defaultAgentHTTPServerProbe = "http://localhost:9900/health"
+
+	clusterRoleReader           = "reader"
+	defaultClusterRole          = clusterRoleReader
+	defaultRequestTimeoutSecond = "10s"
+
+	sideCarMeshServicenameLabel = "mesh-servicename"
+	sideCarAliveProbeLabel      = "alive-probe"
+	sideCarApplicationPortLabel = "application-port"
+	meshServiceLabelsLabel      = "mesh-service-labels"
+
+	// ContainerName is the name of the injected sidecar container
+	ContainerName = "easemesh-sidecar"
+	// IngressPort is the port the injected sidecar serves the ingress traffic on
+	IngressPort = sidecarIngressPortContainerPort
+	// EgressPort is the port the injected sidecar serves the egress traffic on
+	EgressPort = sidecarEressPortContainerPort
+)
+
+type sideCarParams struct {
+	ClusterJoinUrls       string            `yaml:"cluster-join-urls"`
+	ClusterRequestTimeout string            `yaml:"cluster-request-timeout"`
+	ClusterRole           string            `yaml:"cluster-role"`
+	ClusterName           string            `yaml:"cluster-name"`
+	Labels                map[string]string `yaml:"Labels"`
+}
+
+func (params *sideCarParams) String() string {
+
+	str := " "
+	for k, v := range params.Labels {
+		str += " --Labels=" + k + "=" + v
+	}
+
+	str += " --cluster-request-timeout=" + params.ClusterRequestTimeout
+	str += " --cluster-role=" + params.ClusterRole
+	str += " --cluster-join-urls=" + params.ClusterJoinUrls
+	str += " --cluster-name=" + params.ClusterName
+	return str
+}
+
+func (params *sideCarParams) Yaml() (string, error) {
+	bytes, err := yaml.Marshal(params)
+	if err != nil {
+		return "", errors.Errorf("obj should be a deployment but is a %T", err)
+	}
+	return string(bytes), nil
+}
+
+// Service describes the mesh service which the pods are instances of
+type Service struct {
+	// Name is the name of the mesh service
+	Name string
+	// AppContainerName is the name of the application container, the
+	// first container is the application one if it's empty
+	AppContainerName string
+	// Labels is dedicated to labeling instances for traffic control
+	Labels map[string]string
+}
+
+// Injector injects the EaseAgent and the sidecar of EaseMesh into pods, it's
+// shared by the MeshDeployment controller and the admission webhook, so
+// that both of them produce the same pods.
+type Injector struct {
+	ImageRegistryURL string
+	ClusterJoinURL   string
+	ClusterName      string
+}
+
+// injection injects a pod spec with the service.
+type injection struct {
+	*Injector
+	service *Service
+	spec    *corev1.PodSpec
+}
+
+// Inject injects the volumes, the init containers, the environment of the
+// application container and the sidecar container into the pod spec, the
+// injected ones are replaced if the pod spec has been injected already.
+func (i *Injector) Inject(spec *corev1.PodSpec, service *Service) error {
+	d := &injection{Injector: i, service: service, spec: spec}
+
+	d.injectVolumes()
+
+	err := d.completeAppContainerSpec()
+	if err != nil {
+		return errors.Wrap(err, "Complete Application Container error")
+	}
+
+	err = d.injectInitContainers()
+	if err != nil {
+		return errors.Wrap(err, "inject InitContainer error")
+	}
+
+	err = d.injectSideCarSpec()
+	if err != nil {
+		return errors.Wrap(err, "inject side car error")
+	}
+
+	return nil
+}
+
+func (d *injection) injectVolumes() {
+	d.injectVolumeIntoPod(easeAgentVolume)
+	d.injectVolumeIntoPod(sideCarParamsVolume)
+}
+
+func (d *injection) injectVolumeIntoPod(fn func() corev1.Volume) {
+	volume := fn()
+	if len(d.spec.Volumes) == 0 {
+		d.spec.Volumes = []corev1.Volume{volume}
+		return
+	}
+	for index, v := range d.spec.Volumes {
+		if v.Name == volume.Name {
+			d.spec.Volumes[index] = volume
+			return
+		}
+	}
+	d.spec.Volumes = append(d.spec.Volumes, volume)
+}
+
+// completeAppContainerSpec add volumeMounts for mount AgentVolume and declare env for Java Application
+func (d *injection) completeAppContainerSpec() error {
+
+	appContainer, err := d.getAppContainer()
+	if err != nil {
+		return err
+	}
+
+	d.injectVolumeMountIntoContainer(appContainer, agentVolumeName, easeAgentVolumeMount)
+	d.injectEnvIntoContainer(appContainer, javaToolOptionsEnvName, javaToolsOptionEnv)
+	return nil
+}
+
+func (d *injection) injectSideCarSpec() error {
+
+	sideCarContainer := corev1.Container{}
+	err := d.completeSideCarSpec(&sideCarContainer)
+	if err != nil {
+		return err
+	}
+
+	if len(d.spec.Containers) == 0 {
+		d.spec.Containers = []corev1.Container{sideCarContainer}
+		return nil
+	}
+
+	for index, container := range d.spec.Containers {
+		if container.Name == ContainerName {
+			d.spec.Containers[index] = sideCarContainer
+			return nil
+		}
+	}
+
+	d.spec.Containers = append(d.spec.Containers, sideCarContainer)
+	return nil
+}
+
+func (d *injection) completeSideCarSpec(sideCarContainer *corev1.Container) error {
+
+	sideCarContainer.Name = ContainerName
+
+	command := "/opt/easegress/bin/easegress-server -f /easegress-sidecar/eg-sidecar.yaml"
+	sideCarContainer.Command = []string{"/bin/sh", "-c", command}
+	sideCarContainer.Image = complateImageURL(d.ImageRegistryURL, sidecarImageName)
+	sideCarContainer.ImagePullPolicy = corev1.PullAlways
+	d.injectPortIntoContainer(sideCarContainer, sidecarIngressPortName, sideCarIngressPort)
+	d.injectPortIntoContainer(sideCarContainer, sidecarEgressPortName, sideCarEgressPort)
+	d.injectPortIntoContainer(sideCarContainer, sidecarEurekaPortName, sideCarEurekaPort)
+	d.injectEnvIntoContainer(sideCarContainer, podIPEnvName, podIPEnv)
+	err := d.injectSidecarVolumeMounts(sideCarContainer, sidecarMountPath)
+	return err
+}
+
+func (d *injection) initSideCarParams() (*sideCarParams, error) {
+	params := &sideCarParams{}
+	params.ClusterRole = defaultClusterRole
+	params.ClusterRequestTimeout = defaultRequestTimeoutSecond
+
+	labelSlice := []string{}
+	for key, value := range d.service.Labels {
+		labelSlice = append(labelSlice, key+"="+value)
+	}
+
+	meshServiceLabels := url.QueryEscape(strings.Join(labelSlice, "&"))
+
+	labels := make(map[string]string)
+	labels[sideCarMeshServicenameLabel] = d.service.Name
+	labels[sideCarAliveProbeLabel] = defaultAgentHTTPServerProbe
+	labels[sideCarApplicationPortLabel] = ""
+	labels[meshServiceLabelsLabel] = meshServiceLabels
+
+	params.Labels = labels
+	params.ClusterJoinUrls = d.ClusterJoinURL
+	params.ClusterName = d.ClusterName
+	return params, nil
+}
+
+func (d *injection) injectInitContainers() error {
+	err := d.injectInitContainersIntoPod(complateImageURL(d.ImageRegistryURL, agentInitContainerImage), d.easeAgentInitContainer)
+	if err != nil {
+		return errors.Wrap(err, "inject EaseAgent InitContainer error")
+	}
+
+	err = d.injectInitContainersIntoPod(complateImageURL(d.ImageRegistryURL, sidecarImageName), d.sidecarInitContainer)
+	if err != nil {
+		return errors.Wrap(err, "inject sidecar InitContainer error")
+	}
+	return nil
+}
+
+func (d *injection) injectInitContainersIntoPod(containerImageName string, fn func() (corev1.Container, error)) error {
+
+	initContainer, err := fn()
+	if err != nil {
+		return err
+	}
+	initContainers := d.spec.InitContainers
+	if len(initContainers) == 0 {
+		d.spec.InitContainers = []corev1.Container{initContainer}
+	} else {
+		for index, container := range initContainers {
+			if container.Image == containerImageName {
+				d.spec.InitContainers[index] = initContainer
+				return nil
+			}
+		}
+		d.spec.InitContainers = append(d.spec.InitContainers, initContainer)
+	}
+	return nil
+}
+
+func (d *injection) easeAgentInitContainer() (corev1.Container, error) {
+
+	initContainer := corev1.Container{}
+
+	initContainer.Name = agentInitContainerName
+	initContainer.Image = complateImageURL(d.ImageRegistryURL, agentInitContainerImage)
+	initContainer.ImagePullPolicy = corev1.PullAlways
+
+	command := "cp -r " + agentVolumeMountPath + "/. " + agentInitContainerMountPath
+	initContainer.Command = []string{"/bin/sh", "-c", command}
+
+	err := d.injectAgentVolumeMounts(&initContainer, agentInitContainerMountPath)
+	if err != nil {
+		return initContainer, errors.Wrap(err, "inject agent volumeMounts error")
+	}
+	return initContainer, nil
+
+}
+
+func (d *injection) sidecarInitContainer() (corev1.Container, error) {
+
+	initContainer := corev1.Container{}
+
+	initContainer.Name = sidecarInitContainerName
+	initContainer.Image = complateImageURL(d.ImageRegistryURL, sidecarImageName)
+	initContainer.ImagePullPolicy = corev1.PullAlways
+
+	params, err := d.initSideCarParams()
+	if err != nil {
+		return initContainer, err
+	}
+
+	appContainer, err := d.getAppContainer()
+	if err != nil {
+		return initContainer, err
+	}
+
+	if len(appContainer.Ports) != 0 {
+		port := appContainer.Ports[0].ContainerPort
+		params.Labels[sideCarApplicationPortLabel] = strconv.Itoa(int(port))
+	}
+
+	livenessProbe := appContainer.LivenessProbe
+	if livenessProbe != nil && livenessProbe.HTTPGet != nil {
+		host := livenessProbe.HTTPGet.Host
+		port := livenessProbe.HTTPGet.Port
+		path := livenessProbe.HTTPGet.Path
+		aliveProbeURL := "http://" + host + port.StrVal + path
+		params.Labels[sideCarAliveProbeLabel] = aliveProbeURL
+	}
+
+	d.injectEnvIntoContainer(&initContainer, podNameEnvName, podNameEnv)
+	s, err := params.Yaml()
+	if err != nil {
+		return initContainer, err
+	}
+
+	command := "echo name: $POD_NAME >> /opt/eg-sidecar.yaml; echo '" + s + "' >> /opt/eg-sidecar.yaml; cp -r /opt/. " + sidecarParamsVolumeMountPath
+	initContainer.Command = []string{"/bin/sh", "-c", command}
+
+	d.injectVolumeMountIntoContainer(&initContainer, sidecarParamsVolumeName, sidecarVolumeMount)
+
+	return initContainer, nil
+
+}
+
+// injectAgentVolumeMounts add volumeMounts for mount AgentVolume which containing the jar into container
+func (d *injection) injectAgentVolumeMounts(container *corev1.Container, mountPath string) error {
+
+	volumeMount := corev1.VolumeMount{}
+	volumeMount.Name = agentVolumeName
+	volumeMount.MountPath = mountPath
+
+	if len(container.VolumeMounts) == 0 {
+		container.VolumeMounts = []corev1.VolumeMount{volumeMount}
+		return nil
+	}
+	for index, vm := range container.VolumeMounts {
+		if vm.Name == agentVolumeName {
+			container.VolumeMounts[index] = volumeMount
+			return nil
+		}
+	}
+	container.VolumeMounts = append(container.VolumeMounts, volumeMount)
+	return nil
+}
+
+func (d *injection) injectSidecarVolumeMounts(container *corev1.Container, mountPath string) error {
+
+	volumeMount := corev1.VolumeMount{}
+	volumeMount.Name = sidecarParamsVolumeName
+	volumeMount.MountPath = mountPath
+
+	if len(container.VolumeMounts) == 0 {
+		container.VolumeMounts = []corev1.VolumeMount{volumeMount}
+		return nil
+	}
+	for index, vm := range container.VolumeMounts {
+		if vm.Name == sidecarParamsVolumeName {
+			container.VolumeMounts[index] = volumeMount
+			return nil
+		}
+	}
+	container.VolumeMounts = append(container.VolumeMounts, volumeMount)
+	return nil
+}
+
+func (d *injection) getAppContainer() (*corev1.Container, error) {
+	if d.service.AppContainerName == "" {
+		if len(d.spec.Containers) == 0 {
+			return nil, errors.Errorf("Application container do not exists.")
+		}
+		return &d.spec.Containers[0], nil
+	}
+	for index, container := range d.spec.Containers {
+		if container.Name == d.service.AppContainerName {
+			return &d.spec.Containers[index], nil
+		}
+	}
+	return nil, errors.Errorf("Application container do not exists. Please confirm application container name is %s.", d.service.AppContainerName)
+}
+
+func (d *injection) injectEnvIntoContainer(container *corev1.Container, envName string, fn func() corev1.EnvVar) {
+	env := fn()
+	if len(container.Env) == 0 {
+		container.Env = []corev1.EnvVar{env}
+		return
+	}
+	for index, env := range container.Env {
+		if env.Name == envName {
+			container.Env[index] = env
+			return
+		}
+	}
+	container.Env = append(container.Env, env)
+
+}
+
+func (d *injection) injectPortIntoContainer(container *corev1.Container, portName string, fn func() corev1.ContainerPort) {
+	port := fn()
+	if len(container.Ports) == 0 {
+		container.Ports = []corev1.ContainerPort{port}
+		return
+	}
+	for index, p := range container.Ports {
+		if p.Name == portName {
+			container.Ports[index] = port
+			return
+		}
+	}
+	container.Ports = append(container.Ports, port)
+
+}
+
+func (d *injection) injectVolumeMountIntoContainer(container *corev1.Container, volumeName string, fn func() corev1.VolumeMount) {
+	volumeMount := fn()
+	if len(container.VolumeMounts) == 0 {
+		container.VolumeMounts = []corev1.VolumeMount{volumeMount}
+		return
+	}
+
+	for index, vm := range container.VolumeMounts {
+		if vm.Name == volumeName {
+			container.VolumeMounts[index] = volumeMount
+			return
+		}
+	}
+	container.VolumeMounts = append(container.VolumeMounts, volumeMount)
+}
+
+func sideCarParamsVolume() corev1.Volume {
+	volume := corev1.Volume{}
+	volume.Name = sidecarParamsVolumeName
+	volume.EmptyDir = &corev1.EmptyDirVolumeSource{}
+	return volume
+}
+
+func easeAgentVolume() corev1.Volume {
+	volume := corev1.Volume{}
+	volume.Name = agentVolumeName
+	volume.EmptyDir = &corev1.EmptyDirVolumeSource{}
+	return volume
+}
+
+func javaToolsOptionEnv() corev1.EnvVar {
+	env := corev1.EnvVar{
+		Name:  javaToolOptionsEnvName,
+		Value: javaAgentJarOption,
+	}
+	return env
+}
+
+func podIPEnv() corev1.EnvVar {
+	varSource := &corev1.EnvVarSource{
+		FieldRef: &corev1.ObjectFieldSelector{
+			FieldPath: k8sPodIPFieldPath,
+		},
+	}
+
+	env := corev1.EnvVar{
+		Name:      podIPEnvName,
+		ValueFrom: varSource,
+	}
+	return env
+}
+
+func podNameEnv() corev1.EnvVar {
+	varSource := &corev1.EnvVarSource{
+		FieldRef: &corev1.ObjectFieldSelector{
+			FieldPath: k8sPodNameFieldPath,
+		},
+	}
+
+	env := corev1.EnvVar{
+		Name:      podNameEnvName,
+		ValueFrom: varSource,
+	}
+	return env
+}
+
+func sideCarIngressPort() corev1.ContainerPort {
+	port := corev1.ContainerPort{
+		Name:          sidecarIngressPortName,
+		ContainerPort: sidecarIngressPortContainerPort,
+	}
+	return port
+}
+
+func sideCarEgressPort() corev1.ContainerPort {
+	port := corev1.ContainerPort{
+		Name:          sidecarEgressPortName,
+		ContainerPort: sidecarEressPortContainerPort,
+	}
+	return port
+}
+
+func sideCarEurekaPort() corev1.ContainerPort {
+	port := corev1.ContainerPort{
+		Name:          sidecarEurekaPortName,
+		ContainerPort: sidecarEurekaPortContainerPort,
+	}
+	return port
+}
+
+func easeAgentVolumeMount() corev1.VolumeMount {
+	volumeMount := corev1.VolumeMount{}
+	volumeMount.Name = agentVolumeName
+	volumeMount.MountPath = agentVolumeMountPath
+	return volumeMount
+}
+
+func sidecarVolumeMount() corev1.VolumeMount {
+	volumeMount := corev1.VolumeMount{}
+	volumeMount.Name = sidecarParamsVolumeName
+	volumeMount.MountPath = sidecarParamsVolumeMountPath
+	return volumeMount
+}
+
+func complateImageURL(registryURL string, imageName string) string {
+	return registryURL + "/" + imageName
+}
@@ -0,0 +1,188 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sidecar
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ServiceNameAnnotation opts a Deployment or a Pod in the mesh, its
+	// value is the name of the mesh service.
+	ServiceNameAnnotation = "mesh.megaease.com/service-name"
+	// AppContainerNameAnnotation is the name of the application container,
+	// the first container is the application one if it's absent.
+	AppContainerNameAnnotation = "mesh.megaease.com/app-container-name"
+	// ServiceLabelsAnnotation labels the instances for traffic control, its
+	// value is a comma separated list of key=value, such as "version=canary".
+	ServiceLabelsAnnotation = "mesh.megaease.com/service-labels"
+	// InjectedAnnotation marks the pod templates and the pods injected by
+	// Sync, the pods created from an injected template are skipped.
+	InjectedAnnotation = "mesh.megaease.com/sidecar-injected"
+
+	// InjectKey is the label of a namespace enrolling all its workloads in
+	// the mesh when it's InjectEnabled, and the annotation of a workload
+	// opting out of the enrollment when it's InjectDisabled.
+	InjectKey = "mesh.megaease.com/inject"
+	// InjectEnabled enrolls the workloads of the namespace
+	InjectEnabled = "enabled"
+	// InjectDisabled opts the workload out of the enrollment
+	InjectDisabled = "disabled"
+
+	// DefaultServiceNameKey is the default label or annotation whose value
+	// is the name of the mesh service of an enrolled workload.
+	DefaultServiceNameKey = "app"
+)
+
+// Policy decides which mesh service a workload serves, a workload is in the
+// mesh if it's annotated with ServiceNameAnnotation, or it's enrolled by its
+// namespace. Only the workloads not controlled by others are enrolled, such
+// as Deployments and bare Pods, the Pods of a ReplicaSet follow its template.
+type Policy struct {
+	// ServiceNameKey is the label or annotation whose value is the name of
+	// the mesh service of an enrolled workload, the label goes first.
+	ServiceNameKey string
+}
+
+// ServiceOf returns the mesh service of the workload in the namespace, it
+// returns nil if the workload isn't in the mesh.
+func (p *Policy) ServiceOf(obj metav1.Object, namespace *corev1.Namespace) (*Service, error) {
+	annotations := obj.GetAnnotations()
+	if annotations[InjectKey] == InjectDisabled {
+		return nil, nil
+	}
+
+	name := annotations[ServiceNameAnnotation]
+	if name == "" && Enrolled(namespace) && metav1.GetControllerOf(obj) == nil {
+		name = obj.GetLabels()[p.ServiceNameKey]
+		if name == "" {
+			name = annotations[p.ServiceNameKey]
+		}
+		if name == "" {
+			return nil, errors.Errorf("enrolled by namespace %s but no label or annotation %s for the mesh service name",
+				namespace.Name, p.ServiceNameKey)
+		}
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	service := &Service{
+		Name:             name,
+		AppContainerName: annotations[AppContainerNameAnnotation],
+	}
+
+	labels := annotations[ServiceLabelsAnnotation]
+	if labels == "" {
+		return service, nil
+	}
+
+	service.Labels = map[string]string{}
+	for _, label := range strings.Split(labels, ",") {
+		kv := strings.SplitN(strings.TrimSpace(label), "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, errors.Errorf("invalid label %q in annotation %s, expecting key=value", label, ServiceLabelsAnnotation)
+		}
+		service.Labels[kv[0]] = kv[1]
+	}
+	return service, nil
+}
+
+// Enrolled returns whether the namespace enrolls its workloads in the mesh.
+func Enrolled(namespace *corev1.Namespace) bool {
+	return namespace != nil && namespace.Labels[InjectKey] == InjectEnabled
+}
+
+// Injected returns whether the pod or the pod template is injected by Sync.
+func Injected(meta *metav1.ObjectMeta) bool {
+	return meta.Annotations[InjectedAnnotation] == "true"
+}
+
+// Sync injects the pod spec and annotates it with InjectedAnnotation if the
+// service isn't nil, otherwise it strips the pod spec injected by Sync.
+func (i *Injector) Sync(meta *metav1.ObjectMeta, spec *corev1.PodSpec, service *Service) error {
+	if service == nil {
+		if Injected(meta) {
+			Strip(spec)
+			delete(meta.Annotations, InjectedAnnotation)
+		}
+		return nil
+	}
+
+	err := i.Inject(spec, service)
+	if err != nil {
+		return err
+	}
+
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	meta.Annotations[InjectedAnnotation] = "true"
+	return nil
+}
+
+// Strip removes what Inject injects from the pod spec, the environment
+// variable JAVA_TOOL_OPTIONS is removed only if it's the injected one. The
+// emptied lists are nil, as they are omitted before the injection.
+func Strip(spec *corev1.PodSpec) {
+	var volumes []corev1.Volume
+	for _, volume := range spec.Volumes {
+		if volume.Name != agentVolumeName && volume.Name != sidecarParamsVolumeName {
+			volumes = append(volumes, volume)
+		}
+	}
+	spec.Volumes = volumes
+
+	var initContainers []corev1.Container
+	for _, container := range spec.InitContainers {
+		if container.Name != agentInitContainerName && container.Name != sidecarInitContainerName {
+			initContainers = append(initContainers, container)
+		}
+	}
+	spec.InitContainers = initContainers
+
+	var containers []corev1.Container
+	for _, container := range spec.Containers {
+		if container.Name == ContainerName {
+			continue
+		}
+
+		var volumeMounts []corev1.VolumeMount
+		for _, volumeMount := range container.VolumeMounts {
+			if volumeMount.Name != agentVolumeName {
+				volumeMounts = append(volumeMounts, volumeMount)
+			}
+		}
+		container.VolumeMounts = volumeMounts
+
+		var env []corev1.EnvVar
+		for _, e := range container.Env {
+			if e.Name != javaToolOptionsEnvName || e.Value != javaAgentJarOption {
+				env = append(env, e)
+			}
+		}
+		container.Env = env
+
+		containers = append(containers, container)
+	}
+	spec.Containers = containers
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sidecar
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestServiceOf(t *testing.T) {
+	enrolled := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "shop",
+		Labels: map[string]string{InjectKey: InjectEnabled},
+	}}
+	isController := true
+	owners := []metav1.OwnerReference{{Kind: "MeshDeployment", Name: "vets", Controller: &isController}}
+
+	tests := []struct {
+		name      string
+		meta      metav1.ObjectMeta
+		namespace *corev1.Namespace
+		service   *Service
+		err       bool
+	}{
+		{"not in the mesh", metav1.ObjectMeta{}, nil, nil, false},
+		{"annotated", metav1.ObjectMeta{Annotations: map[string]string{
+			ServiceNameAnnotation:      "vets-service",
+			AppContainerNameAnnotation: "vets",
+			ServiceLabelsAnnotation:    "version=canary, zone=a",
+		}}, nil, &Service{Name: "vets-service", AppContainerName: "vets",
+			Labels: map[string]string{"version": "canary", "zone": "a"}}, false},
+		{"invalid labels", metav1.ObjectMeta{Annotations: map[string]string{
+			ServiceNameAnnotation:   "vets-service",
+			ServiceLabelsAnnotation: "canary",
+		}}, nil, nil, true},
+		{"enrolled by label", metav1.ObjectMeta{Labels: map[string]string{"app": "vets-service"}},
+			enrolled, &Service{Name: "vets-service"}, false},
+		{"enrolled by annotation", metav1.ObjectMeta{Annotations: map[string]string{"app": "vets-service"}},
+			enrolled, &Service{Name: "vets-service"}, false},
+		{"annotation goes first", metav1.ObjectMeta{
+			Labels:      map[string]string{"app": "vets"},
+			Annotations: map[string]string{ServiceNameAnnotation: "vets-service"},
+		}, enrolled, &Service{Name: "vets-service"}, false},
+		{"enrolled without name", metav1.ObjectMeta{}, enrolled, nil, true},
+		{"opted out", metav1.ObjectMeta{
+			Labels:      map[string]string{"app": "vets-service"},
+			Annotations: map[string]string{InjectKey: InjectDisabled},
+		}, enrolled, nil, false},
+		{"controlled by others", metav1.ObjectMeta{
+			Labels:          map[string]string{"app": "vets-service"},
+			OwnerReferences: owners,
+		}, enrolled, nil, false},
+	}
+
+	policy := &Policy{ServiceNameKey: DefaultServiceNameKey}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, err := policy.ServiceOf(&tt.meta, tt.namespace)
+			if (err != nil) != tt.err {
+				t.Fatalf("expect error %v but got: %v", tt.err, err)
+			}
+			if !reflect.DeepEqual(service, tt.service) {
+				t.Errorf("expect service %+v but got %+v", tt.service, service)
+			}
+		})
+	}
+}
+
+func TestSyncStrip(t *testing.T) {
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"owner": "pet"}},
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{{Name: "config"}},
+			Containers: []corev1.Container{{
+				Name:         "vets",
+				Env:          []corev1.EnvVar{{Name: "PROFILE", Value: "prod"}},
+				VolumeMounts: []corev1.VolumeMount{{Name: "config", MountPath: "/config"}},
+			}},
+		},
+	}
+	original := template.DeepCopy()
+
+	injector := &Injector{}
+	err := injector.Sync(&template.ObjectMeta, &template.Spec, &Service{Name: "vets-service"})
+	if err != nil {
+		t.Fatalf("inject failed: %v", err)
+	}
+	if !Injected(&template.ObjectMeta) || len(template.Spec.Containers) != 2 {
+		t.Fatalf("expect the template to be injected but got: %+v", template)
+	}
+
+	err = injector.Sync(&template.ObjectMeta, &template.Spec, nil)
+	if err != nil {
+		t.Fatalf("strip failed: %v", err)
+	}
+	if !reflect.DeepEqual(&template, original) {
+		t.Errorf("expect the stripped template to be the original one\nexpect: %+v\ngot: %+v", original, template)
+	}
+}
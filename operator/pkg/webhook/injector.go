@@ -0,0 +1,180 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/megaease/easemesh/mesh-operator/pkg/sidecar"
+
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// DeploymentPath is the path of the webhook injecting Deployments
+	DeploymentPath = "/mutate-apps-v1-deployment"
+	// PodPath is the path of the webhook injecting Pods
+	PodPath = "/mutate-v1-pod"
+)
+
+// +kubebuilder:webhook:path=/mutate-apps-v1-deployment,mutating=true,failurePolicy=ignore,sideEffects=None,groups=apps,resources=deployments,verbs=create;update,versions=v1,name=mdeployment.mesh.megaease.com,admissionReviewVersions={v1,v1beta1}
+// +kubebuilder:webhook:path=/mutate-v1-pod,mutating=true,failurePolicy=ignore,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=mpod.mesh.megaease.com,admissionReviewVersions={v1,v1beta1}
+
+type (
+	// DeploymentInjector injects the sidecar into the pod template of the
+	// Deployments in the mesh by the Policy, the same as the Deployments of
+	// MeshDeployments, and strips the injected ones which leave the mesh.
+	DeploymentInjector struct {
+		Client   client.Client
+		Injector *sidecar.Injector
+		Policy   *sidecar.Policy
+		Log      logr.Logger
+		decoder  *admission.Decoder
+	}
+
+	// PodInjector injects the sidecar into the Pods in the mesh by the
+	// Policy, which aren't created from injected templates.
+	PodInjector struct {
+		Client   client.Client
+		Injector *sidecar.Injector
+		Policy   *sidecar.Policy
+		Log      logr.Logger
+		decoder  *admission.Decoder
+	}
+)
+
+var (
+	_ admission.Handler         = &DeploymentInjector{}
+	_ admission.DecoderInjector = &DeploymentInjector{}
+	_ admission.Handler         = &PodInjector{}
+	_ admission.DecoderInjector = &PodInjector{}
+)
+
+// Handle injects or strips the Deployment of the request.
+func (d *DeploymentInjector) Handle(ctx context.Context, req admission.Request) admission.Response {
+	deploy := &v1.Deployment{}
+	err := d.decoder.Decode(req, deploy)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	namespace, err := getNamespace(ctx, d.Client, req.Namespace)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	service, err := d.Policy.ServiceOf(deploy, namespace)
+	if err != nil {
+		// The workload is admitted without injection instead of being
+		// rejected, so that enrolling a namespace never blocks deployments.
+		return admission.Allowed("not in the mesh").WithWarnings(err.Error())
+	}
+	if service == nil && !sidecar.Injected(&deploy.Spec.Template.ObjectMeta) {
+		return admission.Allowed("not in the mesh")
+	}
+
+	err = d.Injector.Sync(&deploy.Spec.Template.ObjectMeta, &deploy.Spec.Template.Spec, service)
+	if err != nil {
+		d.Log.Error(err, "inject deployment failed", "namespace", req.Namespace, "name", req.Name)
+		return injectionFailed(deploy, err)
+	}
+
+	return patchResponse(req, deploy)
+}
+
+// InjectDecoder injects the decoder.
+func (d *DeploymentInjector) InjectDecoder(decoder *admission.Decoder) error {
+	d.decoder = decoder
+	return nil
+}
+
+// Handle injects the Pod of the request.
+func (p *PodInjector) Handle(ctx context.Context, req admission.Request) admission.Response {
+	pod := &corev1.Pod{}
+	err := p.decoder.Decode(req, pod)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if sidecar.Injected(&pod.ObjectMeta) {
+		return admission.Allowed("injected already")
+	}
+
+	namespace, err := getNamespace(ctx, p.Client, req.Namespace)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	service, err := p.Policy.ServiceOf(pod, namespace)
+	if err != nil {
+		return admission.Allowed("not in the mesh").WithWarnings(err.Error())
+	}
+	if service == nil {
+		return admission.Allowed("not in the mesh")
+	}
+
+	err = p.Injector.Sync(&pod.ObjectMeta, &pod.Spec, service)
+	if err != nil {
+		p.Log.Error(err, "inject pod failed", "namespace", req.Namespace, "name", pod.GenerateName+pod.Name)
+		return injectionFailed(pod, err)
+	}
+
+	return patchResponse(req, pod)
+}
+
+// InjectDecoder injects the decoder.
+func (p *PodInjector) InjectDecoder(decoder *admission.Decoder) error {
+	p.decoder = decoder
+	return nil
+}
+
+// injectionFailed rejects the workload opted in the mesh explicitly by
+// ServiceNameAnnotation when it can't be injected, the ones enrolled by their
+// namespace are admitted without injection, so that enrolling a namespace
+// never blocks deployments.
+func injectionFailed(obj metav1.Object, err error) admission.Response {
+	if obj.GetAnnotations()[sidecar.ServiceNameAnnotation] != "" {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	return admission.Allowed("not injected").WithWarnings(err.Error())
+}
+
+func getNamespace(ctx context.Context, c client.Client, name string) (*corev1.Namespace, error) {
+	namespace := &corev1.Namespace{}
+	err := c.Get(ctx, types.NamespacedName{Name: name}, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return namespace, nil
+}
+
+func patchResponse(req admission.Request, obj interface{}) admission.Response {
+	marshaled, err := json.Marshal(obj)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
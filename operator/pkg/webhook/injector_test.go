@@ -0,0 +1,267 @@
+/*
+ * Copyright (c) 2017, MegaEase
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/megaease/easemesh/mesh-operator/pkg/sidecar"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func newRequest(t *testing.T, obj client.Object) admission.Request {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("marshal %T failed: %v", obj, err)
+	}
+	return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Object:    runtime.RawExtension{Raw: raw},
+	}}
+}
+
+func newDecoder(t *testing.T) *admission.Decoder {
+	decoder, err := admission.NewDecoder(scheme.Scheme)
+	if err != nil {
+		t.Fatalf("new decoder failed: %v", err)
+	}
+	return decoder
+}
+
+// newClient returns a client of the namespaces pet, which isn't enrolled,
+// and shop, which is enrolled.
+func newClient() client.Client {
+	return fake.NewClientBuilder().WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "pet"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   "shop",
+			Labels: map[string]string{sidecar.InjectKey: sidecar.InjectEnabled},
+		}},
+	).Build()
+}
+
+func podSpec() corev1.PodSpec {
+	return corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "vets", Image: "megaease/vets:1.0"}},
+	}
+}
+
+func hasPatch(resp admission.Response, path string) bool {
+	for _, patch := range resp.Patches {
+		if patch.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+func newDeploymentInjector(t *testing.T) *DeploymentInjector {
+	injector := &DeploymentInjector{
+		Client:   newClient(),
+		Injector: &sidecar.Injector{},
+		Policy:   &sidecar.Policy{ServiceNameKey: sidecar.DefaultServiceNameKey},
+		Log:      ctrl.Log,
+	}
+	injector.InjectDecoder(newDecoder(t))
+	return injector
+}
+
+func newDeployment(namespace string) *v1.Deployment {
+	return &v1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "vets", Namespace: namespace},
+		Spec: v1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{Spec: podSpec()},
+		},
+	}
+}
+
+func TestDeploymentInjector(t *testing.T) {
+	injector := newDeploymentInjector(t)
+
+	deploy := newDeployment("pet")
+	resp := injector.Handle(context.Background(), newRequest(t, deploy))
+	if !resp.Allowed || len(resp.Patches) != 0 {
+		t.Fatalf("expect no patches without annotation but got: %+v", resp)
+	}
+
+	deploy.Annotations = map[string]string{
+		sidecar.ServiceNameAnnotation:   "vets-service",
+		sidecar.ServiceLabelsAnnotation: "version=canary",
+	}
+	resp = injector.Handle(context.Background(), newRequest(t, deploy))
+	if !resp.Allowed {
+		t.Fatalf("expect allowed but got: %+v", resp.Result)
+	}
+	for _, path := range []string{
+		"/spec/template/spec/containers/1",
+		"/spec/template/spec/initContainers",
+		"/spec/template/spec/volumes",
+		"/spec/template/spec/containers/0/env",
+		"/spec/template/metadata/annotations",
+	} {
+		if !hasPatch(resp, path) {
+			t.Errorf("expect patch of %s but got: %+v", path, resp.Patches)
+		}
+	}
+
+	// Injecting an injected Deployment changes nothing.
+	service, err := injector.Policy.ServiceOf(deploy, nil)
+	if err != nil {
+		t.Fatalf("parse annotations failed: %v", err)
+	}
+	err = injector.Injector.Sync(&deploy.Spec.Template.ObjectMeta, &deploy.Spec.Template.Spec, service)
+	if err != nil {
+		t.Fatalf("inject failed: %v", err)
+	}
+	resp = injector.Handle(context.Background(), newRequest(t, deploy))
+	if !resp.Allowed || len(resp.Patches) != 0 {
+		t.Fatalf("expect no patches for injected deployment but got: %+v", resp.Patches)
+	}
+
+	deploy.Annotations[sidecar.ServiceLabelsAnnotation] = "canary"
+	resp = injector.Handle(context.Background(), newRequest(t, deploy))
+	if !resp.Allowed || len(resp.Patches) != 0 || len(resp.Warnings) == 0 {
+		t.Fatalf("expect a warning without patches for invalid labels but got: %+v", resp)
+	}
+
+	// The injected Deployment leaving the mesh is stripped.
+	delete(deploy.Annotations, sidecar.ServiceNameAnnotation)
+	delete(deploy.Annotations, sidecar.ServiceLabelsAnnotation)
+	resp = injector.Handle(context.Background(), newRequest(t, deploy))
+	if !resp.Allowed || !hasPatch(resp, "/spec/template/spec/containers/1") {
+		t.Fatalf("expect the sidecar container to be removed but got: %+v", resp.Patches)
+	}
+}
+
+func TestDeploymentInjectorEnrolled(t *testing.T) {
+	injector := newDeploymentInjector(t)
+
+	deploy := newDeployment("shop")
+	deploy.Labels = map[string]string{sidecar.DefaultServiceNameKey: "vets-service"}
+	resp := injector.Handle(context.Background(), newRequest(t, deploy))
+	if !resp.Allowed || !hasPatch(resp, "/spec/template/spec/containers/1") {
+		t.Fatalf("expect the sidecar container to be injected but got: %+v", resp.Patches)
+	}
+
+	deploy.Annotations = map[string]string{sidecar.InjectKey: sidecar.InjectDisabled}
+	resp = injector.Handle(context.Background(), newRequest(t, deploy))
+	if !resp.Allowed || len(resp.Patches) != 0 {
+		t.Fatalf("expect no patches for the opted out deployment but got: %+v", resp.Patches)
+	}
+
+	// Failing to inject an enrolled Deployment doesn't block it, unless it
+	// opts in the mesh explicitly.
+	deploy.Annotations = map[string]string{sidecar.AppContainerNameAnnotation: "unknown"}
+	resp = injector.Handle(context.Background(), newRequest(t, deploy))
+	if !resp.Allowed || len(resp.Patches) != 0 || len(resp.Warnings) == 0 {
+		t.Fatalf("expect a warning without patches for the missing application container but got: %+v", resp)
+	}
+
+	deploy.Annotations[sidecar.ServiceNameAnnotation] = "vets-service"
+	resp = injector.Handle(context.Background(), newRequest(t, deploy))
+	if resp.Allowed {
+		t.Fatalf("expect missing application container of the opted in deployment to be rejected")
+	}
+
+	deploy = newDeployment("shop")
+	resp = injector.Handle(context.Background(), newRequest(t, deploy))
+	if !resp.Allowed || len(resp.Patches) != 0 || len(resp.Warnings) == 0 {
+		t.Fatalf("expect a warning without patches for the deployment without service name but got: %+v", resp)
+	}
+}
+
+func TestPodInjector(t *testing.T) {
+	injector := &PodInjector{
+		Client:   newClient(),
+		Injector: &sidecar.Injector{},
+		Policy:   &sidecar.Policy{ServiceNameKey: sidecar.DefaultServiceNameKey},
+		Log:      ctrl.Log,
+	}
+	injector.InjectDecoder(newDecoder(t))
+
+	pod := &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "vets",
+			Namespace:   "pet",
+			Annotations: map[string]string{sidecar.ServiceNameAnnotation: "vets-service"},
+		},
+		Spec: podSpec(),
+	}
+	resp := injector.Handle(context.Background(), newRequest(t, pod))
+	if !resp.Allowed || !hasPatch(resp, "/spec/containers/1") {
+		t.Fatalf("expect sidecar container to be injected but got: %+v", resp.Patches)
+	}
+
+	pod.Annotations[sidecar.AppContainerNameAnnotation] = "unknown"
+	resp = injector.Handle(context.Background(), newRequest(t, pod))
+	if resp.Allowed {
+		t.Fatalf("expect missing application container to be rejected")
+	}
+
+	pod.Annotations[sidecar.InjectedAnnotation] = "true"
+	resp = injector.Handle(context.Background(), newRequest(t, pod))
+	if !resp.Allowed || len(resp.Patches) != 0 {
+		t.Fatalf("expect pod created from injected template to be skipped but got: %+v", resp.Patches)
+	}
+
+	// The Pods of workloads in an enrolled namespace follow their templates.
+	isController := true
+	pod = &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "vets-7d7bccf78f-2pps5",
+			Namespace: "shop",
+			Labels:    map[string]string{sidecar.DefaultServiceNameKey: "vets-service"},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "vets-7d7bccf78f", UID: "1", Controller: &isController,
+			}},
+		},
+		Spec: podSpec(),
+	}
+	resp = injector.Handle(context.Background(), newRequest(t, pod))
+	if !resp.Allowed || len(resp.Patches) != 0 {
+		t.Fatalf("expect pod owned by replica set to be skipped but got: %+v", resp.Patches)
+	}
+
+	pod.OwnerReferences = nil
+	resp = injector.Handle(context.Background(), newRequest(t, pod))
+	if !resp.Allowed || !hasPatch(resp, "/spec/containers/1") {
+		t.Fatalf("expect bare pod in enrolled namespace to be injected but got: %+v", resp.Patches)
+	}
+	pod.Annotations = map[string]string{sidecar.AppContainerNameAnnotation: "unknown"}
+	resp = injector.Handle(context.Background(), newRequest(t, pod))
+	if !resp.Allowed || len(resp.Patches) != 0 || len(resp.Warnings) == 0 {
+		t.Fatalf("expect a warning without patches for the missing application container but got: %+v", resp)
+	}
+}